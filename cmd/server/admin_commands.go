@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"Test2/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// readJSONArg đọc tài liệu JSON từ tệp, "-" là stdin
+func readJSONArg(path string, v any) error {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		r = file
+	}
+
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid JSON in %s: %w", path, err)
+	}
+	return nil
+}
+
+// runPostCommand: post create <file|->, post restore <id>
+func runPostCommand(ctx context.Context, posts domain.PostUseCase, args []string) int {
+	if len(args) != 2 {
+		return usageError()
+	}
+
+	switch args[0] {
+	case "create":
+		var p domain.Post
+		if err := readJSONArg(args[1], &p); err != nil {
+			return writeResult(nil, err)
+		}
+		if err := posts.Store(ctx, &p); err != nil {
+			return writeResult(nil, err)
+		}
+		return writeResult(p, nil)
+	case "restore":
+		id, ok := parseIDArg(args[1])
+		if !ok {
+			fmt.Fprintf(os.Stderr, "post restore: invalid id %q\n", args[1])
+			return usageError()
+		}
+		if err := posts.Restore(ctx, id); err != nil {
+			return writeResult(nil, err)
+		}
+		return writeResult(gin.H{"id": id, "restored": true}, nil)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown post command %q\n", args[0])
+		return usageError()
+	}
+}
+
+// runCategoryCommand: category create <file|->, category restore <id>
+func runCategoryCommand(ctx context.Context, categories domain.CategoryUseCase, args []string) int {
+	if len(args) != 2 {
+		return usageError()
+	}
+
+	switch args[0] {
+	case "create":
+		var cate domain.Category
+		if err := readJSONArg(args[1], &cate); err != nil {
+			return writeResult(nil, err)
+		}
+		if err := categories.Store(ctx, &cate); err != nil {
+			return writeResult(nil, err)
+		}
+		return writeResult(cate, nil)
+	case "restore":
+		id, ok := parseIDArg(args[1])
+		if !ok {
+			fmt.Fprintf(os.Stderr, "category restore: invalid id %q\n", args[1])
+			return usageError()
+		}
+		if err := categories.Restore(ctx, id); err != nil {
+			return writeResult(nil, err)
+		}
+		return writeResult(gin.H{"id": id, "restored": true}, nil)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown category command %q\n", args[0])
+		return usageError()
+	}
+}
+
+// runCacheCommand: cache families, cache inspect <family> [N], cache flush <family>
+func runCacheCommand(ctx context.Context, maintenance domain.MaintenanceUseCase, args []string) int {
+	if len(args) == 0 {
+		return usageError()
+	}
+
+	switch args[0] {
+	case "families":
+		if len(args) != 1 {
+			return usageError()
+		}
+		return writeResult(maintenance.CacheFamilies(), nil)
+	case "inspect":
+		if len(args) < 2 || len(args) > 3 {
+			return usageError()
+		}
+		limit := 0
+		if len(args) == 3 {
+			n, err := strconv.Atoi(args[2])
+			if err != nil || n <= 0 {
+				fmt.Fprintf(os.Stderr, "cache inspect: invalid limit %q\n", args[2])
+				return usageError()
+			}
+			limit = n
+		}
+		stats, err := maintenance.InspectCache(ctx, args[1], limit)
+		return writeResult(stats, err)
+	case "flush":
+		if len(args) != 2 {
+			return usageError()
+		}
+		deleted, err := maintenance.FlushCache(ctx, args[1])
+		return writeResult(gin.H{"family": args[1], "deleted": deleted}, err)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown cache command %q\n", args[0])
+		return usageError()
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"Test2/infrastructure/migrate"
+	"Test2/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+const commandUsage = `Usage:
+  main                             start the HTTP server
+  main import-wxr <file.xml>       import a WordPress export (WXR) file
+  main migrate up [N]              apply all (or the next N) pending migrations
+  main migrate down [N]            roll back the last (or the last N) applied migrations
+  main migrate status              list migrations and whether they are applied
+  main migrate force <version>     mark a dirty migration as applied after fixing it by hand
+  main post create <file.json|->   create a post from a JSON document (same body as POST /posts/add)
+  main post restore <id>           restore a post from the trash
+  main category create <file|->    create a category from a JSON document
+  main category restore <id>       restore a category from the trash
+  main cache families              list the cache key families
+  main cache inspect <family> [N]  count the keys of a family and show up to N of them
+  main cache flush <family>        delete every key of a family
+  main search rebuild              rebuild the full-text index and drop cached search results
+  main check                       run data consistency checks (exit code 1 when issues are found)
+
+Results are printed to stdout as JSON: {"data": ...} on success, {"error": "..."} on failure.`
+
+// commandDeps là các UseCase mà subcommand dùng lại từ wiring của server
+type commandDeps struct {
+	wxr         domain.WXRImportUseCase
+	posts       domain.PostUseCase
+	categories  domain.CategoryUseCase
+	maintenance domain.MaintenanceUseCase
+}
+
+// runCommand chạy một subcommand và trả về exit code
+func runCommand(ctx context.Context, args []string, deps commandDeps) int {
+	// Audit log ghi nhận thao tác từ dòng lệnh với actor "cli"
+	ctx = domain.WithRequestMeta(ctx, domain.RequestMeta{Actor: "cli"})
+
+	switch args[0] {
+	case "import-wxr":
+		if len(args) != 2 {
+			return usageError()
+		}
+		return runImportWXR(ctx, deps.wxr, args[1])
+	case "post":
+		return runPostCommand(ctx, deps.posts, args[1:])
+	case "category":
+		return runCategoryCommand(ctx, deps.categories, args[1:])
+	case "cache":
+		return runCacheCommand(ctx, deps.maintenance, args[1:])
+	case "search":
+		if len(args) != 2 || args[1] != "rebuild" {
+			return usageError()
+		}
+		err := deps.maintenance.RebuildSearch(ctx)
+		return writeResult(gin.H{"rebuilt": err == nil}, err)
+	case "check":
+		if len(args) != 1 {
+			return usageError()
+		}
+		report, err := deps.maintenance.CheckConsistency(ctx)
+		code := writeResult(report, err)
+		if code == 0 && !report.OK {
+			return 1
+		}
+		return code
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", args[0])
+		return usageError()
+	}
+}
+
+// usageError in hướng dẫn sử dụng ra stderr, exit code 2 theo quy ước cho lỗi cú pháp
+func usageError() int {
+	fmt.Fprintln(os.Stderr, commandUsage)
+	return 2
+}
+
+// writeResult in kết quả ra stdout dạng JSON và trả về exit code (1 khi có lỗi).
+// data vẫn được in kèm lỗi để thấy phần việc đã làm được trước khi dừng.
+func writeResult(data any, err error) int {
+	result := gin.H{"data": data}
+	code := 0
+	if err != nil {
+		result["error"] = err.Error()
+		code = 1
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(result)
+	return code
+}
+
+// parseIDArg đọc id dương từ tham số dòng lệnh
+func parseIDArg(raw string) (int64, bool) {
+	id, err := strconv.ParseInt(raw, 10, 64)
+	return id, err == nil && id > 0
+}
+
+func runImportWXR(ctx context.Context, wxr domain.WXRImportUseCase, path string) int {
+	file, err := os.Open(path)
+	if err != nil {
+		return writeResult(nil, err)
+	}
+	defer file.Close()
+
+	// Tổng kết luôn được in ra, kể cả khi dừng giữa chừng
+	summary, err := wxr.Import(ctx, file)
+	return writeResult(summary, err)
+}
+
+// runMigrate chạy `migrate <up|down|status|force>` và trả về exit code
+func runMigrate(ctx context.Context, migrator *migrate.Migrator, args []string) int {
+	if len(args) == 0 || len(args) > 2 {
+		return usageError()
+	}
+
+	var n int64
+	if len(args) == 2 {
+		var ok bool
+		if n, ok = parseIDArg(args[1]); !ok {
+			fmt.Fprintf(os.Stderr, "migrate: invalid number %q\n", args[1])
+			return usageError()
+		}
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx, int(n))
+		return writeResult(gin.H{"applied": migrationNames(applied)}, err)
+	case "down":
+		reverted, err := migrator.Down(ctx, int(n))
+		return writeResult(gin.H{"rolled_back": migrationNames(reverted)}, err)
+	case "status":
+		if len(args) != 1 {
+			return usageError()
+		}
+		statuses, err := migrator.Status(ctx)
+		return writeResult(statuses, err)
+	case "force":
+		if len(args) != 2 {
+			return usageError()
+		}
+		err := migrator.Force(ctx, n)
+		return writeResult(gin.H{"forced": n}, err)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown migrate command %q\n", args[0])
+		return usageError()
+	}
+}
+
+func migrationNames(migrations []migrate.Migration) []string {
+	names := make([]string, 0, len(migrations))
+	for _, m := range migrations {
+		names = append(names, fmt.Sprintf("%06d_%s", m.Version, m.Name))
+	}
+	return names
+}
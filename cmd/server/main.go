@@ -1,80 +1,401 @@
 package main
 
 import (
+	"context"
 	"database/sql"
-	"log"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	_ "github.com/go-sql-driver/mysql" // Import driver MySQL (side-effect import)
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	ginprometheus "github.com/zsais/go-gin-prometheus"
 
 	"Test2/config"
+	"Test2/infrastructure/logging"
+	"Test2/infrastructure/migrate"
 	"Test2/infrastructure/redis"
+	"Test2/infrastructure/render"
+	"Test2/infrastructure/tracing"
+	"Test2/infrastructure/webhook"
 	httphandler "Test2/internal/delivery/http"
+	"Test2/internal/domain"
+	"Test2/internal/repository/localfs"
 	"Test2/internal/repository/mysql"
 	"Test2/internal/usecase"
+	"Test2/internal/worker"
+	"Test2/migrations"
 
 	redisRepo "Test2/internal/repository/redis"
 )
 
 func main() {
-	// Dựa trên config.go để lấy tham số môi trường
-	cfg, err := config.LoadConfig()
+	os.Exit(run())
+}
+
+// run khởi tạo và chạy ứng dụng, trả về exit code. Mọi lỗi đều được return thay vì log.Fatalf
+// để các defer đóng kết nối (MySQL, Redis) luôn được chạy.
+func run() int {
+	// Cấu hình: mặc định -> tệp YAML -> biến môi trường -> flag, phần còn lại của dòng lệnh là subcommand
+	// Store reload các khóa an toàn (TTL, log level, rate limit, feature flag) khi tệp thay đổi hoặc nhận SIGHUP
+	configStore, args, err := config.NewStore(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		slog.Error("Invalid configuration", "error", err)
+		return 2
+	}
+	cfg := configStore.Current()
+	if err := logging.Setup(cfg.LogLevel, cfg.LogFormat); err != nil {
+		slog.Error("Failed to set up logging", "error", err)
+		return 2
+	}
+	// Thông báo debug của gin (route đăng ký, cảnh báo chế độ debug) cũng đi qua slog
+	gin.DebugPrintFunc = func(format string, values ...any) {
+		slog.Debug(strings.TrimSpace(fmt.Sprintf(format, values...)), "component", "gin")
+	}
+	slog.Info("Effective config", "config", cfg)
+
+	// Tracing: span của HTTP request, UseCase, câu lệnh MySQL và lệnh Redis. Shutdown đẩy nốt span
+	// còn trong hàng đợi, defer đầu tiên nên chạy sau cùng khi mọi kết nối đã đóng.
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg)
 	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
+		slog.Error("Failed to set up tracing", "error", err)
+		return 1
 	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("Failed to flush traces", "error", err)
+		}
+	}()
 
 	// 1. Load Configuration
-	redis.InitRedis(cfg) // Khởi tạo Redis với config
+	// Redis chỉ là cache: khi không phản hồi, ứng dụng vẫn khởi động và chạy không cache tới khi Redis phục hồi
+	if err := redis.InitRedis(cfg); err != nil {
+		if !errors.Is(err, redis.ErrUnavailable) {
+			slog.Error("Failed to set up Redis client", "error", err)
+			return 1
+		}
+		slog.Warn("Starting in degraded mode without cache", "error", err)
+	}
+	// Defer chạy theo thứ tự ngược: MySQL được đóng trước, Redis sau cùng
+	defer func() {
+		if err := redis.Client.Close(); err != nil {
+			slog.Error("Failed to close Redis client", "error", err)
+		}
+		slog.Info("Redis connection closed")
+	}()
 
 	// 2. Database Connection
-	// Sử dụng DSN từ config.GetDSN()
-	db, err := sql.Open("mysql", cfg.GetDSN())
+	// Sử dụng DSN từ config.GetDSN(), mỗi câu lệnh tạo span khi request đang được trace
+	db, err := mysql.Open(cfg.GetDSN())
 	if err != nil {
-		log.Fatalf("Failed to open database connection: %v", err)
+		slog.Error("Failed to open database connection", "error", err)
+		return 1
 	}
-	defer db.Close()
+	defer func() {
+		db.Close()
+		slog.Info("Database connection closed")
+	}()
+	configurePool(db, cfg)
+	// sql.DBStats (kết nối mở/đang dùng/rảnh, thời gian chờ kết nối) của từng pool xuất trên /metrics
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db, "primary"))
 
 	// Kiểm tra kết nối thực tế (Ping)
 	if err := db.Ping(); err != nil {
-		log.Fatalf("Failed to ping database: %v", err)
+		slog.Error("Failed to ping database", "error", err)
+		return 1
+	}
+	slog.Info("Database connection established")
+
+	// Read replica: truy vấn đọc được chia tải sang replica khỏe, ghi và transaction luôn đi tới primary.
+	// Replica lỗi lúc khởi động không chặn server, nó được thêm vào vòng đọc khi lần kiểm tra sau đạt yêu cầu.
+	cluster := mysql.NewCluster(db, cfg.DBReplicaMaxLag, cfg.DBReplicaCheckInterval)
+	for _, addr := range cfg.ReplicaAddrs() {
+		replicaDB, err := mysql.Open(cfg.GetReplicaDSN(addr))
+		if err != nil {
+			slog.Error("Failed to open replica connection", "replica", addr, "error", err)
+			return 1
+		}
+		defer replicaDB.Close()
+		configurePool(replicaDB, cfg)
+		prometheus.MustRegister(collectors.NewDBStatsCollector(replicaDB, addr))
+		cluster.AddReplica(addr, replicaDB)
+	}
+	cluster.CheckReplicas(context.Background())
+
+	// Migration runner dùng pool riêng cho phép nhiều câu lệnh trong một tệp .sql
+	migrationDB, err := sql.Open("mysql", cfg.GetMigrationDSN())
+	if err != nil {
+		slog.Error("Failed to open migration connection", "error", err)
+		return 1
+	}
+	defer migrationDB.Close()
+	migrator, err := migrate.New(migrationDB, migrations.FS)
+	if err != nil {
+		slog.Error("Failed to load migrations", "error", err)
+		return 1
+	}
+
+	// `migrate ...` chạy trước khi kiểm tra schema để có thể đưa schema lên phiên bản mới
+	if len(args) > 0 && args[0] == "migrate" {
+		return runMigrate(context.Background(), migrator, args[1:])
+	}
+
+	if cfg.AutoMigrate {
+		applied, err := migrator.Up(context.Background(), 0)
+		if err != nil {
+			slog.Error("Failed to apply migrations", "error", err)
+			return 1
+		}
+		for _, m := range applied {
+			slog.Info("Applied migration", "version", m.Version, "name", m.Name)
+		}
+	}
+	// Từ chối khởi động khi schema cũ hơn binary hoặc có migration lỗi dở dang
+	if err := migrator.Check(context.Background()); err != nil {
+		slog.Error("Database schema is not up to date, run `main migrate up`", "error", err)
+		return 1
 	}
-	log.Println("Database connection established successfully")
 
 	// 3. Dependency Injection (Wiring Layers)
 
 	// Timeout cho context của mỗi request (được define trong UseCase)
-	timeoutContext := 2 * time.Second
+	timeoutContext := cfg.RequestTimeout
+	cacheTTLs := usecase.NewCacheTTLs(cacheTTL(cfg))
 
 	// Layer 1: Repository
 	// Lưu ý: Cần thêm hàm NewMysqlPostRepository vào package mysql như đã đề cập ở trên
-	postRepo := mysql.NewMysqlPostRepository(db)
-	cateRepo := mysql.NewMysqlCateRepository(db)
+	postRepo := mysql.NewMysqlPostRepository(cluster)
+	cateRepo := mysql.NewMysqlCateRepository(cluster)
+	mediaRepo := mysql.NewMysqlMediaRepository(cluster)
+	auditRepo := mysql.NewMysqlAuditRepository(cluster)
+	maintenanceRepo := mysql.NewMysqlMaintenanceRepository(cluster)
+	outboxRepo := mysql.NewMysqlOutboxRepository(cluster)
+	webhookRepo := mysql.NewMysqlWebhookRepository(cluster)
+	// Transactor cho phép UseCase ghi dữ liệu và domain event (outbox) trong cùng transaction
+	transactor := mysql.NewMysqlTransactor(cluster)
+
+	// Lưu tệp media trên filesystem local, phục vụ tĩnh qua cfg.MediaBaseURL
+	mediaStorage, err := localfs.NewLocalMediaStorage(cfg.MediaDir, cfg.MediaBaseURL)
+	if err != nil {
+		slog.Error("Failed to init media storage", "error", err)
+		return 1
+	}
 
 	// Khởi tạo Cache Repository từ client toàn cục
 	postCacheRepo := redisRepo.NewRedisCacheRepository(redis.Client)
+	rawCacheRepo := redisRepo.NewRedisRawCacheRepository(redis.Client)
+	if cluster.HasReplicas() {
+		// Cache có thể được nạp lại từ replica chưa nhận thay đổi ngay sau khi bị xóa, nên khóa bị xóa
+		// được xóa lại sau độ trễ tối đa của replica còn trong vòng đọc
+		redeleteDelay := cfg.DBReplicaMaxLag + cfg.DBReplicaCheckInterval
+		postCacheRepo = redisRepo.NewDoubleDeleteCacheRepository(postCacheRepo, redeleteDelay)
+		rawCacheRepo = redisRepo.NewDoubleDeleteRawCacheRepository(rawCacheRepo, redeleteDelay)
+	}
+
+	// Render Markdown/HTML/plaintext sang HTML đã sanitise
+	contentRenderer := render.NewContentRenderer()
 
 	// Layer 2: UseCase
 	// Tiêm Repository và Timeout vào UseCase
-	postUseCase := usecase.NewPostUseCase(postRepo, postCacheRepo, timeoutContext)
-	cateUseCase := usecase.NewCateUseCase(cateRepo, timeoutContext)
+	mediaUseCase := usecase.NewMediaUseCase(mediaRepo, mediaStorage, postCacheRepo, rawCacheRepo, cfg.MediaMaxSize, cfg.MediaMaxPixels, timeoutContext)
+	// Sitemap đăng ký như ContentHook để chỉ sinh lại trang bị ảnh hưởng sau mỗi thao tác ghi
+	sitemapUseCase := usecase.NewSitemapUseCase(postRepo, cateRepo, rawCacheRepo, usecase.SitemapConfig{
+		BaseURL:  cfg.PublicBaseURL,
+		PageSize: cfg.SitemapPageSize,
+		TTL:      cfg.CacheSitemapTTL,
+	}, timeoutContext)
+	// Audit log ghi lại mọi thao tác ghi kèm actor/request ID/IP và ảnh chụp trước/sau
+	auditUseCase := usecase.NewAuditUseCase(auditRepo, timeoutContext)
+	// Mỗi phương thức của Post/Category UseCase chạy trong một span
+	postUseCase := usecase.NewTracedPostUseCase(usecase.NewPostUseCase(postRepo, postCacheRepo, mediaUseCase, contentRenderer, rawCacheRepo, transactor, outboxRepo, auditUseCase, cacheTTLs, timeoutContext, sitemapUseCase))
+	cateUseCase := usecase.NewTracedCategoryUseCase(usecase.NewCateUseCase(cateRepo, mediaUseCase, rawCacheRepo, cfg.CategoryChildPolicy, transactor, outboxRepo, auditUseCase, cacheTTLs, timeoutContext, sitemapUseCase))
+	feedUseCase := usecase.NewFeedUseCase(postRepo, cateRepo, contentRenderer, rawCacheRepo, usecase.FeedConfig{
+		BaseURL: cfg.PublicBaseURL,
+		Title:   cfg.FeedTitle,
+		Limit:   cfg.FeedLimit,
+		TTL:     cfg.CacheFeedTTL,
+	}, timeoutContext)
+	// Webhook nhận domain event từ outbox relay, việc gửi HTTP do WebhookDispatcher thực hiện
+	webhookUseCase := usecase.NewWebhookUseCase(webhookRepo, webhook.NewHTTPSender(cfg.WebhookTimeout, cfg.WebhookAllowPrivateNetworks), usecase.WebhookConfig{
+		MaxAttempts:  int(cfg.WebhookMaxAttempts),
+		RetryBase:    cfg.WebhookRetryBase,
+		RetryMax:     6 * time.Hour,
+		DisableAfter: int(cfg.WebhookDisableAfter),
+		Lease:        5 * time.Minute,
+
+		AllowPrivateNetworks: cfg.WebhookAllowPrivateNetworks,
+	}, timeoutContext)
+	// Thông báo thay đổi cho client SSE, phát giữa các instance qua Redis pub/sub
+	streamUseCase := usecase.NewStreamUseCase(redisRepo.NewRedisChangeBroker(redis.Client, cfg.StreamChannel, cfg.StreamReplaySize), timeoutContext)
+	wxrUseCase := usecase.NewWXRImportUseCase(postUseCase, postRepo, cateUseCase, cateRepo, timeoutContext)
+	// Thao tác vận hành (cache, search index, kiểm tra dữ liệu) cho admin CLI
+	maintenanceUseCase := usecase.NewMaintenanceUseCase(maintenanceRepo, redisRepo.NewRedisCacheInspector(redis.Client), cfg.MaintenanceTimeout)
+
+	// Subcommand (ví dụ: import-wxr <file>) chạy xong thì thoát, không khởi động HTTP server
+	if len(args) > 0 {
+		// Subcommand đọc rồi ghi (import, dọn dữ liệu) nên luôn đọc từ primary
+		code := runCommand(domain.WithReadYourWrites(context.Background(), true), args, commandDeps{
+			wxr:         wxrUseCase,
+			posts:       postUseCase,
+			categories:  cateUseCase,
+			maintenance: maintenanceUseCase,
+		})
+		return code
+	}
+
+	// Background worker dùng context riêng để vẫn chạy trong lúc HTTP server drain (request đang xử lý còn ghi outbox)
+	workers := newWorkerGroup()
+
+	// Giới hạn request và feature flag đọc từ cấu hình, được cập nhật khi reload
+	rateLimiter := httphandler.NewRateLimiter(cfg.RateLimitRPS, int(cfg.RateLimitBurst))
+	features := httphandler.NewFeatureFlags(featureFlags(cfg))
+	configStore.OnReload(func(next *config.Config) {
+		cacheTTLs.Store(cacheTTL(next))
+		if err := logging.SetLevel(next.LogLevel); err != nil {
+			slog.Error("Failed to change log level", "error", err)
+		}
+		rateLimiter.SetLimit(next.RateLimitRPS, int(next.RateLimitBurst))
+		features.Set(featureFlags(next))
+	})
+	workers.Go(configStore.Watch)
+
+	// Probe Redis khi circuit breaker mở. Lệnh xóa cache bị bỏ qua trong lúc Redis lỗi,
+	// nên mọi nhóm cache được xóa trước khi dùng lại để không phục vụ dữ liệu cũ.
+	redis.Breaker.OnRecover(func(ctx context.Context) error {
+		for _, family := range maintenanceUseCase.CacheFamilies() {
+			if _, err := maintenanceUseCase.FlushCache(ctx, family); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	workers.Go(redis.Breaker.Run)
+
+	// Đo độ trễ replication, gỡ replica trễ hoặc lỗi khỏi vòng đọc và thêm lại khi phục hồi
+	workers.Go(cluster.Run)
+
+	// Background job dọn thùng rác theo thời gian lưu giữ
+	if cfg.TrashRetention > 0 {
+		retentionJob := worker.NewTrashRetentionJob(postUseCase, cateUseCase, cfg.TrashRetention, cfg.TrashPurgeInterval)
+		workers.Go(retentionJob.Run)
+	}
+
+	// Số bài viết/danh mục theo trạng thái cho /metrics
+	if cfg.MetricsContentInterval > 0 {
+		contentMetrics := worker.NewContentMetricsJob(postRepo, cateRepo, cfg.MetricsContentInterval, timeoutContext)
+		workers.Go(contentMetrics.Run)
+	}
+
+	// Relay giao domain event từ outbox tới webhook, SSE và Redis Streams theo thứ tự id. Webhook nhận ít nhất một lần,
+	// SSE và Redis Streams bỏ qua event phát sinh trong lúc circuit breaker của Redis đang mở
+	sinks := []domain.EventSink{webhookUseCase, streamUseCase}
+	if cfg.OutboxStream != "" {
+		sinks = append(sinks, redisRepo.NewRedisStreamSink(redis.Client, cfg.OutboxStream, cfg.OutboxStreamMaxLen))
+	}
+	relay := worker.NewOutboxRelay(outboxRepo, cfg.OutboxRelayInterval, 100, sinks...)
+	workers.Go(relay.Run)
+
+	// Nhận thông báo từ Redis pub/sub (do relay của instance bất kỳ phát) và đẩy tới client SSE
+	workers.Go(streamUseCase.Run)
+
+	// Gửi webhook delivery tới hạn, thử lại theo exponential backoff
+	dispatcher := worker.NewWebhookDispatcher(webhookUseCase, cfg.WebhookDispatchInterval, 50)
+	workers.Go(dispatcher.Run)
+
+	// Readiness ping MySQL và Redis, trang health của admin thêm thống kê pool và phiên bản schema
+	mysqlHealth := mysql.NewMysqlHealthCheck(db)
+	redisHealth := redisRepo.NewRedisHealthCheck(redis.Client, redis.Breaker)
+	healthChecks := []domain.HealthCheck{mysqlHealth, redisHealth}
+	healthDetailers := []domain.HealthDetailer{mysqlHealth, redisHealth, migrator}
+	if cluster.HasReplicas() {
+		replicaHealth := mysql.NewMysqlReplicaHealthCheck(cluster)
+		healthChecks = append(healthChecks, replicaHealth)
+		healthDetailers = append(healthDetailers, replicaHealth)
+	}
+	healthUseCase := usecase.NewHealthUseCase(healthChecks, healthDetailers, cfg.HealthCheckTimeout)
 
 	// Layer 3: Delivery (HTTP Handler)
-	r := gin.Default()
+	r := gin.New()
+	// ClientIP (rate limit, IP trong audit log) chỉ đọc X-Forwarded-For/X-Real-IP khi kết nối đến từ proxy tin cậy,
+	// mặc định gin tin mọi địa chỉ nên client tự đặt header là đổi được IP
+	if err := r.SetTrustedProxies(cfg.TrustedProxies()); err != nil {
+		slog.Error("Invalid trusted proxies", "error", err)
+		return 1
+	}
+
+	// Gắn actor/request ID/IP vào context cho audit log và log, mở span của request (nối trace theo traceparent),
+	// sau đó access log JSON (thay logger text của gin, kèm trace_id) và recovery ghi panic qua slog
+	r.Use(httphandler.RequestMeta(cfg.TrustedProxies()), httphandler.Tracing(), httphandler.AccessLog(), httphandler.Recovery())
 
 	// Cấu hình để tự động tạo route /metrics
 	p := ginprometheus.NewPrometheus("gin")
 	p.Use(r)
 
+	r.Use(httphandler.RateLimit(rateLimiter), httphandler.FeatureGate(features))
+	if cluster.HasReplicas() {
+		// Request ghi và client vừa ghi đọc từ primary (read-your-writes)
+		r.Use(httphandler.ReadYourWrites(cfg.DBReplicaStickyWindow))
+	}
+
 	// Đăng ký routes và handler
 	httphandler.NewPostHandler(r, postUseCase)
 	httphandler.NewCateHandler(r, cateUseCase)
+	httphandler.NewMediaHandler(r, mediaUseCase, cfg.MediaMaxSize)
+	httphandler.NewFeedHandler(r, feedUseCase)
+	httphandler.NewSitemapHandler(r, sitemapUseCase)
+	httphandler.NewTransferHandler(r, postUseCase, wxrUseCase)
+	httphandler.NewAuditHandler(r, auditUseCase)
+	httphandler.NewWebhookHandler(r, webhookUseCase)
+	httphandler.NewStreamHandler(r, streamUseCase, cfg.StreamHeartbeat)
+	httphandler.NewHealthHandler(r, healthUseCase)
+	httphandler.NewConfigHandler(r, configStore)
+
+	// Phục vụ tệp media đã upload (ảnh gốc và các biến thể)
+	r.Static(cfg.MediaBaseURL, cfg.MediaDir)
 
 	// 4. Run Server
-	serverAddr := cfg.AppPort
-	log.Printf("Server is running on port %s", serverAddr)
-	if err := r.Run(serverAddr); err != nil {
-		log.Fatalf("Failed to run server: %v", err)
+	srv := &http.Server{
+		Addr:              cfg.AppPort,
+		Handler:           r,
+		ReadHeaderTimeout: cfg.HTTPReadHeaderTimeout,
+		ReadTimeout:       cfg.HTTPReadTimeout,
+		WriteTimeout:      cfg.HTTPWriteTimeout,
+		IdleTimeout:       cfg.HTTPIdleTimeout,
+	}
+	// Kết nối SSE không bao giờ rảnh nên phải được ngắt chủ động để Shutdown không chờ tới hết hạn
+	srv.RegisterOnShutdown(streamUseCase.Close)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// Sau tín hiệu đầu tiên, tín hiệu thứ hai dừng tiến trình ngay theo hành vi mặc định
+	context.AfterFunc(ctx, stop)
+
+	slog.Info("Server is running", "addr", cfg.AppPort)
+	code := 0
+	if err := serve(ctx, srv, cfg.ShutdownDelay, cfg.ShutdownTimeout, healthUseCase.SetDraining); err != nil {
+		slog.Error("HTTP server failed", "error", err)
+		code = 1
+	}
+
+	// Dừng worker sau khi không còn request nào, rồi các defer đóng MySQL và Redis
+	if !workers.Stop(cfg.ShutdownTimeout) {
+		slog.Error("Background workers did not stop in time", "timeout", cfg.ShutdownTimeout.String())
+		code = 1
 	}
+	slog.Info("Background workers stopped")
+	return code
 }
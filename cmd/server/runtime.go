@@ -0,0 +1,36 @@
+package main
+
+import (
+	"database/sql"
+
+	"Test2/config"
+	httphandler "Test2/internal/delivery/http"
+	"Test2/internal/usecase"
+)
+
+// cacheTTL lấy TTL của Post/Category từ cấu hình, dùng khi khởi động và sau mỗi lần reload
+func cacheTTL(cfg *config.Config) usecase.CacheTTL {
+	return usecase.CacheTTL{
+		PostList:     cfg.CachePostListTTL,
+		PostDetail:   cfg.CachePostDetailTTL,
+		PostSearch:   cfg.CachePostSearchTTL,
+		PostHTML:     cfg.CachePostHTMLTTL,
+		CategoryTree: cfg.CacheCategoryTreeTTL,
+	}
+}
+
+func featureFlags(cfg *config.Config) map[string]bool {
+	return map[string]bool{
+		httphandler.FeatureStream:   cfg.FeatureStream,
+		httphandler.FeatureSearch:   cfg.FeatureSearch,
+		httphandler.FeatureTransfer: cfg.FeatureTransfer,
+	}
+}
+
+// configurePool áp dụng cấu hình connection pool cho primary và từng read replica
+func configurePool(db *sql.DB, cfg *config.Config) {
+	db.SetMaxOpenConns(int(cfg.DBMaxOpenConns))
+	db.SetMaxIdleConns(int(cfg.DBMaxIdleConns))
+	db.SetConnMaxLifetime(cfg.DBConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.DBConnMaxIdleTime)
+}
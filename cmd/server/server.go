@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// serve chạy srv tới khi ctx bị hủy (SIGINT/SIGTERM), sau đó gọi onDrain (readiness báo draining),
+// chờ delay để load balancer gỡ instance, rồi ngừng nhận kết nối mới
+// và chờ các request đang xử lý hoàn tất trong tối đa timeout
+func serve(ctx context.Context, srv *http.Server, delay, timeout time.Duration, onDrain func()) error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		// ListenAndServe chỉ trả về trước Shutdown khi không mở được cổng
+		return err
+	case <-ctx.Done():
+	}
+
+	onDrain()
+	if delay > 0 {
+		slog.Info("Shutting down, reporting not ready before closing listeners", "delay", delay.String())
+		time.Sleep(delay)
+	}
+
+	slog.Info("Shutting down, draining in-flight requests", "timeout", timeout.String())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		// Hết hạn: đóng cứng các kết nối còn lại
+		_ = srv.Close()
+		return fmt.Errorf("drain: %w", err)
+	}
+	if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	slog.Info("HTTP server stopped")
+	return nil
+}
+
+// workerGroup chạy các background worker với một context chung và chờ chúng dừng khi shutdown
+type workerGroup struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newWorkerGroup() *workerGroup {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &workerGroup{ctx: ctx, cancel: cancel}
+}
+
+// Go chạy run trong goroutine riêng, run phải trả về khi ctx bị hủy
+func (g *workerGroup) Go(run func(ctx context.Context)) {
+	g.wg.Go(func() {
+		run(g.ctx)
+	})
+}
+
+// Stop hủy context của các worker và chờ tối đa timeout, trả về false nếu còn worker chưa dừng
+func (g *workerGroup) Stop(timeout time.Duration) bool {
+	g.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
@@ -1,51 +1,324 @@
 package config
 
 import (
-	"fmt"
-	"os"
+	"net"
+	"strings"
+	"time"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
 )
 
+// Config là cấu hình hiệu lực của ứng dụng.
+//
+// Mỗi trường có tag `config:"a.b"` là một khóa cấu hình, được ghi đè lần lượt theo thứ tự:
+// giá trị mặc định (Default) -> tệp YAML (khóa lồng nhau a: {b: ...}) -> biến môi trường A_B -> flag -a.b.
+// Biến môi trường A_B_FILE (hoặc khóa YAML b_file) đọc giá trị từ tệp, dùng cho Docker/Kubernetes secret.
+// Trường có tag `secret:"true"` bị che khi in cấu hình và không có flag để không lộ qua danh sách tiến trình.
+// Trường có tag `reload:"true"` được áp dụng lúc chạy khi tệp cấu hình thay đổi hoặc nhận SIGHUP (xem Store),
+// các trường còn lại chỉ có hiệu lực sau khi khởi động lại.
 type Config struct {
-	AppPort    string
-	DBUser     string
-	DBPassword string
-	DBHost     string
-	DBPort     string
-	DBName     string
-	RedisHost  string
-	RedisPort  string
-}
-
-// LoadConfig đọc biến môi trường set trong docker-compose
-func LoadConfig() (*Config, error) {
-	cfg := &Config{
-		AppPort:    getEnv("APP_PORT", ":8080"),
-		DBUser:     getEnv("DB_USER", "root"),
-		DBPassword: getEnv("DB_PASSWORD", "secret"),
-		DBHost:     getEnv("DB_HOST", "localhost"),
-		DBPort:     getEnv("DB_PORT", "3306"),
-		DBName:     getEnv("DB_NAME", "cms_db"),
-		RedisHost:  getEnv("REDIS_HOST", "localhost"),
-		RedisPort:  getEnv("REDIS_PORT", "6379"),
+	// AppPort là địa chỉ lắng nghe của HTTP server (host:port, ví dụ ":8080")
+	AppPort string `config:"app.port"`
+	// RequestTimeout là timeout context của mỗi thao tác trong UseCase
+	RequestTimeout time.Duration `config:"app.request_timeout"`
+	// MaintenanceTimeout là timeout của thao tác vận hành (rebuild search index, kiểm tra dữ liệu, flush cache)
+	MaintenanceTimeout time.Duration `config:"maintenance.timeout"`
+
+	DBUser     string `config:"db.user"`
+	DBPassword string `config:"db.password" secret:"true"`
+	DBHost     string `config:"db.host"`
+	DBPort     string `config:"db.port"`
+	DBName     string `config:"db.name"`
+	// DBLocation là múi giờ driver dùng để đọc DATETIME (tham số loc của DSN), ví dụ Local hoặc UTC
+	DBLocation string `config:"db.location"`
+	// Connection pool: số kết nối mở/rảnh tối đa (0 = không giới hạn số mở), tuổi thọ và thời gian rảnh tối đa của kết nối
+	DBMaxOpenConns    int64         `config:"db.max_open_conns"`
+	DBMaxIdleConns    int64         `config:"db.max_idle_conns"`
+	DBConnMaxLifetime time.Duration `config:"db.conn_max_lifetime"`
+	DBConnMaxIdleTime time.Duration `config:"db.conn_max_idle_time"`
+	DBDialTimeout     time.Duration `config:"db.dial_timeout"`
+	// Read replica: danh sách host[:port] cách nhau bởi dấu phẩy (cổng mặc định db.port, rỗng = đọc từ primary),
+	// dùng chung user/password/database và cấu hình pool với primary. Replica trễ quá max_lag bị gỡ khỏi vòng đọc,
+	// độ trễ được kiểm tra mỗi check_interval. Sau khi ghi, client đọc từ primary thêm sticky_window (cookie).
+	DBReplicaHosts         string        `config:"db.replica.hosts"`
+	DBReplicaMaxLag        time.Duration `config:"db.replica.max_lag"`
+	DBReplicaCheckInterval time.Duration `config:"db.replica.check_interval"`
+	DBReplicaStickyWindow  time.Duration `config:"db.replica.sticky_window"`
+
+	RedisHost     string `config:"redis.host"`
+	RedisPort     string `config:"redis.port"`
+	RedisUsername string `config:"redis.username"`
+	RedisPassword string `config:"redis.password" secret:"true"`
+	RedisDB       int64  `config:"redis.db"`
+	// Connection pool và timeout của Redis, 0 dùng mặc định của go-redis
+	RedisPoolSize     int64         `config:"redis.pool_size"`
+	RedisMinIdleConns int64         `config:"redis.min_idle_conns"`
+	RedisDialTimeout  time.Duration `config:"redis.dial_timeout"`
+	RedisReadTimeout  time.Duration `config:"redis.read_timeout"`
+	RedisWriteTimeout time.Duration `config:"redis.write_timeout"`
+	// Circuit breaker: số lỗi kết nối liên tiếp trước khi bỏ qua Redis (chạy không cache) và chu kỳ thử kết nối lại
+	RedisBreakerThreshold     int64         `config:"redis.breaker.failure_threshold"`
+	RedisBreakerProbeInterval time.Duration `config:"redis.breaker.probe_interval"`
+	// TLS tới Redis: CA riêng (rỗng = CA của hệ thống) và server name khi khác host
+	RedisTLS                   bool   `config:"redis.tls.enabled"`
+	RedisTLSServerName         string `config:"redis.tls.server_name"`
+	RedisTLSCAFile             string `config:"redis.tls.ca_file"`
+	RedisTLSInsecureSkipVerify bool   `config:"redis.tls.insecure_skip_verify"`
+
+	// Thời gian sống của từng loại cache
+	CachePostListTTL     time.Duration `config:"cache.post_list_ttl" reload:"true"`
+	CachePostDetailTTL   time.Duration `config:"cache.post_detail_ttl" reload:"true"`
+	CachePostSearchTTL   time.Duration `config:"cache.post_search_ttl" reload:"true"`
+	CachePostHTMLTTL     time.Duration `config:"cache.post_html_ttl" reload:"true"`
+	CacheCategoryTreeTTL time.Duration `config:"cache.category_tree_ttl" reload:"true"`
+	CacheFeedTTL         time.Duration `config:"cache.feed_ttl"`
+	CacheSitemapTTL      time.Duration `config:"cache.sitemap_ttl"`
+
+	// ConfigWatchInterval là chu kỳ kiểm tra tệp cấu hình để tự reload (0 = chỉ reload khi nhận SIGHUP)
+	ConfigWatchInterval time.Duration `config:"config.watch_interval"`
+	// LogLevel: debug, info, warn hoặc error
+	LogLevel string `config:"log.level" reload:"true"`
+	// LogFormat: json (mặc định, cho hệ thống thu thập log) hoặc text (dễ đọc khi chạy local)
+	LogFormat string `config:"log.format"`
+	// Tracing (OpenTelemetry): exporter none (tắt), otlp (OTLP/HTTP tới collector) hoặc stdout (JSON ra
+	// stdout hoặc tracing.file, dùng khi chạy local). TracingEndpoint trống thì dùng biến OTEL_EXPORTER_OTLP_*.
+	TracingExporter    string  `config:"tracing.exporter"`
+	TracingEndpoint    string  `config:"tracing.endpoint"`
+	TracingFile        string  `config:"tracing.file"`
+	TracingServiceName string  `config:"tracing.service_name"`
+	TracingSampleRatio float64 `config:"tracing.sample_ratio"` // Tỉ lệ trace gốc được lấy mẫu, request mang traceparent theo quyết định của nơi gọi
+	// MetricsContentInterval là chu kỳ đếm bài viết/danh mục theo trạng thái cho /metrics (0 = tắt)
+	MetricsContentInterval time.Duration `config:"metrics.content_interval"`
+	// Giới hạn request theo IP client: số request mỗi giây (0 = tắt) và số request dồn tối đa
+	RateLimitRPS   float64 `config:"ratelimit.rps" reload:"true"`
+	RateLimitBurst int64   `config:"ratelimit.burst" reload:"true"`
+	// Feature flag: tắt tạm thời SSE, tìm kiếm bài viết và import/export (transfer) mà không cần deploy
+	FeatureStream   bool `config:"feature.stream" reload:"true"`
+	FeatureSearch   bool `config:"feature.search" reload:"true"`
+	FeatureTransfer bool `config:"feature.transfer" reload:"true"`
+
+	// HTTP server: timeout đọc header, đọc toàn bộ request, ghi response và giữ kết nối keep-alive rảnh.
+	// Route stream/tải tệp lớn (SSE, export, import) không bị giới hạn bởi read/write timeout.
+	HTTPReadHeaderTimeout time.Duration `config:"http.read_header_timeout"`
+	HTTPReadTimeout       time.Duration `config:"http.read_timeout"`
+	HTTPWriteTimeout      time.Duration `config:"http.write_timeout"`
+	HTTPIdleTimeout       time.Duration `config:"http.idle_timeout"`
+	// HTTPTrustedProxies là danh sách IP/CIDR của reverse proxy (phân tách bằng dấu phẩy). Header X-Actor và
+	// X-Forwarded-For (IP client cho rate limit, audit log) chỉ được tin khi kết nối đến từ các địa chỉ này,
+	// rỗng = không tin proxy nào.
+	HTTPTrustedProxies string `config:"http.trusted_proxies"`
+	// ShutdownTimeout là thời gian tối đa chờ request đang xử lý (và sau đó là background worker) khi nhận SIGTERM
+	ShutdownTimeout time.Duration `config:"shutdown.timeout"`
+	// ShutdownDelay là thời gian /readyz báo draining trước khi ngừng nhận kết nối, đủ để load balancer gỡ instance
+	ShutdownDelay time.Duration `config:"shutdown.delay"`
+	// HealthCheckTimeout giới hạn thời gian ping mỗi dependency trong /readyz
+	HealthCheckTimeout time.Duration `config:"health.check_timeout"`
+
+	// AutoMigrate chạy các migration còn thiếu khi khởi động, nếu tắt thì server từ chối khởi động khi schema cũ hơn binary
+	AutoMigrate bool `config:"auto_migrate"`
+
+	// Media: thư mục lưu tệp upload, URL prefix phục vụ tĩnh và dung lượng tối đa (byte)
+	MediaDir     string `config:"media.dir"`
+	MediaBaseURL string `config:"media.base_url"`
+	MediaMaxSize int64  `config:"media.max_size"`
+	// MediaMaxPixels là số điểm ảnh tối đa (rộng x cao) của ảnh upload, kiểm tra trước khi giải nén
+	MediaMaxPixels int64 `config:"media.max_pixels"`
+
+	// PublicBaseURL là URL public của site, dùng cho link tuyệt đối trong feed
+	PublicBaseURL string `config:"public_base_url"`
+	FeedTitle     string `config:"feed.title"`
+	FeedLimit     int64  `config:"feed.limit"`
+
+	// SitemapPageSize là số URL tối đa trong một sitemap con (tối đa 50000)
+	SitemapPageSize int64 `config:"sitemap.page_size"`
+
+	// Thùng rác: thời gian lưu giữ trước khi xóa vĩnh viễn (0 = tắt) và chu kỳ chạy job.
+	// Chỉ danh mục đã xóa (có deleted_at) bị xóa vĩnh viễn, danh mục chỉ bị tắt được giữ nguyên.
+	TrashRetention     time.Duration `config:"trash.retention"`
+	TrashPurgeInterval time.Duration `config:"trash.purge_interval"`
+
+	// CategoryChildPolicy: "reparent" hoặc "block", áp dụng khi di chuyển/xóa danh mục còn danh mục con
+	CategoryChildPolicy string `config:"category.child_policy"`
+
+	// Outbox relay: Redis Stream nhận domain event (rỗng = không ghi stream, event phát sinh khi circuit breaker
+	// của Redis đang mở cũng bị bỏ qua), độ dài tối đa gần đúng của stream (0 = không giới hạn) và chu kỳ quét outbox
+	OutboxStream        string        `config:"outbox.stream"`
+	OutboxStreamMaxLen  int64         `config:"outbox.stream_maxlen"`
+	OutboxRelayInterval time.Duration `config:"outbox.relay_interval"`
+
+	// Webhook: số lần gửi tối đa mỗi delivery, số lần thất bại liên tiếp trước khi tự tắt webhook (0 = không tắt),
+	// timeout của mỗi request, khoảng chờ thử lại ban đầu (nhân đôi sau mỗi lần) và chu kỳ quét delivery
+	WebhookMaxAttempts      int64         `config:"webhook.max_attempts"`
+	WebhookDisableAfter     int64         `config:"webhook.disable_after"`
+	WebhookTimeout          time.Duration `config:"webhook.timeout"`
+	WebhookRetryBase        time.Duration `config:"webhook.retry_base"`
+	WebhookDispatchInterval time.Duration `config:"webhook.dispatch_interval"`
+	// WebhookAllowPrivateNetworks cho phép gửi webhook tới địa chỉ nội bộ (loopback, mạng riêng, link-local),
+	// chỉ nên bật khi phát triển. Mặc định tắt để URL do người dùng đăng ký không chạm được dịch vụ nội bộ.
+	WebhookAllowPrivateNetworks bool `config:"webhook.allow_private_networks"`
+
+	// SSE: kênh Redis pub/sub phát thông báo giữa các instance, số thông báo giữ lại để resume bằng Last-Event-ID
+	// và chu kỳ gửi heartbeat
+	StreamChannel    string        `config:"stream.channel"`
+	StreamReplaySize int64         `config:"stream.replay_size"`
+	StreamHeartbeat  time.Duration `config:"stream.heartbeat"`
+}
+
+// Default trả về cấu hình mặc định, phù hợp để chạy local
+func Default() *Config {
+	return &Config{
+		AppPort:            ":8080",
+		RequestTimeout:     2 * time.Second,
+		MaintenanceTimeout: 30 * time.Second,
+
+		DBUser:            "root",
+		DBPassword:        "secret",
+		DBHost:            "localhost",
+		DBPort:            "3306",
+		DBName:            "cms_db",
+		DBLocation:        "Local",
+		DBMaxOpenConns:    25,
+		DBMaxIdleConns:    25,
+		DBConnMaxLifetime: 5 * time.Minute,
+		DBConnMaxIdleTime: 5 * time.Minute,
+		DBDialTimeout:     5 * time.Second,
+
+		DBReplicaMaxLag:        5 * time.Second,
+		DBReplicaCheckInterval: 2 * time.Second,
+		DBReplicaStickyWindow:  5 * time.Second,
+
+		RedisHost:                 "localhost",
+		RedisPort:                 "6379",
+		RedisBreakerThreshold:     5,
+		RedisBreakerProbeInterval: 5 * time.Second,
+
+		CachePostListTTL:     5 * time.Minute,
+		CachePostDetailTTL:   10 * time.Minute,
+		CachePostSearchTTL:   3 * time.Minute,
+		CachePostHTMLTTL:     24 * time.Hour,
+		CacheCategoryTreeTTL: time.Hour,
+		CacheFeedTTL:         10 * time.Minute,
+		CacheSitemapTTL:      24 * time.Hour,
+
+		ConfigWatchInterval:    5 * time.Second,
+		LogLevel:               "info",
+		LogFormat:              "json",
+		TracingExporter:        "none",
+		TracingServiceName:     "cms",
+		TracingSampleRatio:     1,
+		MetricsContentInterval: time.Minute,
+		RateLimitBurst:         20,
+		FeatureStream:          true,
+		FeatureSearch:          true,
+		FeatureTransfer:        true,
+
+		HTTPReadHeaderTimeout: 5 * time.Second,
+		HTTPReadTimeout:       30 * time.Second,
+		HTTPWriteTimeout:      30 * time.Second,
+		HTTPIdleTimeout:       120 * time.Second,
+		ShutdownTimeout:       20 * time.Second,
+		ShutdownDelay:         5 * time.Second,
+		HealthCheckTimeout:    time.Second,
+
+		MediaDir:       "./uploads",
+		MediaBaseURL:   "/uploads",
+		MediaMaxSize:   10 << 20,
+		MediaMaxPixels: 40_000_000,
+
+		PublicBaseURL: "http://localhost:8080",
+		FeedTitle:     "CMS",
+		FeedLimit:     50,
+
+		SitemapPageSize: 50000,
+
+		TrashRetention:     30 * 24 * time.Hour,
+		TrashPurgeInterval: time.Hour,
+
+		CategoryChildPolicy: "block",
+
+		OutboxStream:        "cms:events",
+		OutboxStreamMaxLen:  100000,
+		OutboxRelayInterval: time.Second,
+
+		WebhookMaxAttempts:      8,
+		WebhookDisableAfter:     20,
+		WebhookTimeout:          10 * time.Second,
+		WebhookRetryBase:        30 * time.Second,
+		WebhookDispatchInterval: 5 * time.Second,
+
+		StreamChannel:    "cms:changes",
+		StreamReplaySize: 1000,
+		StreamHeartbeat:  15 * time.Second,
 	}
-	return cfg, nil
 }
 
-// Helper để lấy DSN (Data Source Name) cho MySQL connection
+// Helper để lấy DSN (Data Source Name) cho MySQL connection, mật khẩu và tham số được escape bởi driver
 func (c *Config) GetDSN() string {
-	// Format: user:password@tcp(host:port)/dbname?parseTime=true
-	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		c.DBUser, c.DBPassword, c.DBHost, c.DBPort, c.DBName)
+	return c.mysqlConfig().FormatDSN()
 }
 
-// Bổ sung Helper để format địa chỉ Redis
-func (c *Config) GetRedisAddr() string {
-	return fmt.Sprintf("%s:%s", c.RedisHost, c.RedisPort)
+// GetMigrationDSN là DSN cho migration runner, cho phép nhiều câu lệnh trong một lần Exec (mỗi tệp .sql)
+func (c *Config) GetMigrationDSN() string {
+	dsn := c.mysqlConfig()
+	dsn.MultiStatements = true
+	return dsn.FormatDSN()
+}
+
+// TrustedProxies trả về danh sách IP/CIDR trong http.trusted_proxies
+func (c *Config) TrustedProxies() []string {
+	var proxies []string
+	for _, p := range strings.Split(c.HTTPTrustedProxies, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			proxies = append(proxies, p)
+		}
+	}
+	return proxies
 }
 
-func getEnv(key, fallback string) string {
-	if value, ok := os.LookupEnv(key); ok {
-		return value
+// ReplicaAddrs trả về địa chỉ host:port của các read replica theo thứ tự cấu hình
+func (c *Config) ReplicaAddrs() []string {
+	var addrs []string
+	for _, host := range strings.Split(c.DBReplicaHosts, ",") {
+		host = strings.TrimSpace(host)
+		if host == "" {
+			continue
+		}
+		if _, _, err := net.SplitHostPort(host); err != nil {
+			host = net.JoinHostPort(strings.Trim(host, "[]"), c.DBPort)
+		}
+		addrs = append(addrs, host)
 	}
-	return fallback
+	return addrs
+}
+
+// GetReplicaDSN là DSN tới một read replica (addr lấy từ ReplicaAddrs)
+func (c *Config) GetReplicaDSN(addr string) string {
+	dsn := c.mysqlConfig()
+	dsn.Addr = addr
+	return dsn.FormatDSN()
+}
+
+func (c *Config) mysqlConfig() *mysqldriver.Config {
+	dsn := mysqldriver.NewConfig()
+	dsn.User = c.DBUser
+	dsn.Passwd = c.DBPassword
+	dsn.Net = "tcp"
+	dsn.Addr = net.JoinHostPort(c.DBHost, c.DBPort)
+	dsn.DBName = c.DBName
+	dsn.Params = map[string]string{"charset": "utf8mb4"}
+	dsn.ParseTime = true
+	dsn.Timeout = c.DBDialTimeout
+	// Validate đã kiểm tra DBLocation, lỗi ở đây chỉ xảy ra khi Config được tạo tay
+	if loc, err := time.LoadLocation(c.DBLocation); err == nil {
+		dsn.Loc = loc
+	}
+	// clientFoundRows: RowsAffected đếm số dòng khớp WHERE (kể cả khi giá trị không đổi),
+	// các thao tác batch/reorder dựa vào đó để phát hiện bản ghi bị sửa giữa chừng
+	dsn.ClientFoundRows = true
+	return dsn
+}
+
+// Bổ sung Helper để format địa chỉ Redis
+func (c *Config) GetRedisAddr() string {
+	return net.JoinHostPort(c.RedisHost, c.RedisPort)
 }
@@ -0,0 +1,307 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clearEnv bỏ mọi biến môi trường cấu hình của máy chạy test, t.Setenv khôi phục lại khi test kết thúc
+func clearEnv(t *testing.T) {
+	t.Helper()
+	names := []string{ConfigFileEnv}
+	for _, f := range Default().fields() {
+		names = append(names, envName(f.key), envName(f.key)+"_FILE")
+	}
+	for _, name := range names {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+}
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfigLayering(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string            // nội dung config.yaml, rỗng là không dùng tệp
+		files    map[string]string // tệp phụ (secret) cạnh config.yaml
+		env      map[string]string // giá trị "$DIR" được thay bằng thư mục tạm
+		args     []string
+		viaEnv   bool // truyền tệp qua CONFIG_FILE thay cho flag -config
+		check    func(*Config) string
+		want     string
+		wantRest []string
+	}{
+		{
+			name: "defaults",
+			want: "info localhost 5s",
+			check: func(c *Config) string {
+				return c.LogLevel + " " + c.DBHost + " " + c.ShutdownDelay.String()
+			},
+		},
+		{
+			name: "file overrides defaults",
+			want: "debug db.internal",
+			file: "log:\n  level: debug\ndb:\n  host: db.internal\n",
+			check: func(c *Config) string {
+				return c.LogLevel + " " + c.DBHost
+			},
+		},
+		{
+			name: "empty file key keeps default",
+			want: "localhost",
+			file: "db:\n  host:\n",
+			check: func(c *Config) string {
+				return c.DBHost
+			},
+		},
+		{
+			name: "env overrides file",
+			want: "warn",
+			file: "log:\n  level: debug\n",
+			env:  map[string]string{"LOG_LEVEL": "warn"},
+			check: func(c *Config) string {
+				return c.LogLevel
+			},
+		},
+		{
+			name: "flag overrides env",
+			want: "error",
+			file: "log:\n  level: debug\n",
+			env:  map[string]string{"LOG_LEVEL": "warn"},
+			args: []string{"-log.level=error"},
+			check: func(c *Config) string {
+				return c.LogLevel
+			},
+		},
+		{
+			name:   "CONFIG_FILE env selects the file",
+			viaEnv: true,
+			want:   "1s",
+			file:   "shutdown:\n  delay: 1s\n",
+			check: func(c *Config) string {
+				return c.ShutdownDelay.String()
+			},
+		},
+		{
+			name:  "secret file in yaml is relative to the config file",
+			want:  "from-file",
+			file:  "db:\n  password_file: db_password\n",
+			files: map[string]string{"db_password": "from-file\n"},
+			check: func(c *Config) string {
+				return c.DBPassword
+			},
+		},
+		{
+			name:  "env secret file overrides yaml",
+			want:  "from-env-file",
+			file:  "db:\n  password: from-yaml\n",
+			files: map[string]string{"db_password": "from-env-file\n"},
+			env:   map[string]string{"DB_PASSWORD_FILE": "$DIR/db_password"},
+			check: func(c *Config) string {
+				return c.DBPassword
+			},
+		},
+		{
+			name: "bool flag without value",
+			want: "true",
+			args: []string{"-auto_migrate"},
+			check: func(c *Config) string {
+				if c.AutoMigrate {
+					return "true"
+				}
+				return "false"
+			},
+		},
+		{
+			name:     "arguments after flags are returned",
+			want:     "info",
+			args:     []string{"-log.level=info", "migrate", "up"},
+			wantRest: []string{"migrate", "up"},
+			check: func(c *Config) string {
+				return c.LogLevel
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				writeFile(t, dir, name, content)
+			}
+
+			args := tt.args
+			if tt.file != "" {
+				path := writeFile(t, dir, "config.yaml", tt.file)
+				if tt.viaEnv {
+					t.Setenv(ConfigFileEnv, path)
+				} else {
+					args = append([]string{"-config", path}, args...)
+				}
+			}
+			for name, value := range tt.env {
+				t.Setenv(name, strings.ReplaceAll(value, "$DIR", dir))
+			}
+
+			cfg, rest, err := LoadConfig(args)
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+			if got := tt.check(cfg); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if strings.Join(rest, " ") != strings.Join(tt.wantRest, " ") {
+				t.Errorf("rest = %q, want %q", rest, tt.wantRest)
+			}
+		})
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		env     map[string]string
+		args    []string
+		wantErr []string
+	}{
+		{
+			name:    "unknown file key",
+			file:    "db:\n  hots: x\n",
+			wantErr: []string{`unknown key "db.hots"`},
+		},
+		{
+			name:    "lists are rejected",
+			file:    "http:\n  trusted_proxies: [10.0.0.1]\n",
+			wantErr: []string{"lists are not supported"},
+		},
+		{
+			name:    "value and file both set in yaml",
+			file:    "db:\n  password: a\n  password_file: b\n",
+			wantErr: []string{"both db.password and db.password_file are set"},
+		},
+		{
+			name:    "value and file both set in env",
+			env:     map[string]string{"DB_PASSWORD": "a", "DB_PASSWORD_FILE": "b"},
+			wantErr: []string{"both DB_PASSWORD and DB_PASSWORD_FILE are set"},
+		},
+		{
+			name:    "all errors are reported together",
+			file:    "app:\n  request_timeout: soon\n",
+			env:     map[string]string{"DB_MAX_OPEN_CONNS": "many"},
+			wantErr: []string{"app.request_timeout", "env DB_MAX_OPEN_CONNS"},
+		},
+		{
+			name:    "invalid flag value",
+			args:    []string{"-shutdown.delay=later"},
+			wantErr: []string{"shutdown.delay"},
+		},
+		{
+			name:    "secrets are not accepted as flags",
+			args:    []string{"-db.password=x"},
+			wantErr: []string{"db.password"},
+		},
+		{
+			name:    "result is validated",
+			env:     map[string]string{"LOG_FORMAT": "xml"},
+			wantErr: []string{"log.format must be json or text"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			args := tt.args
+			if tt.file != "" {
+				args = append([]string{"-config", writeFile(t, t.TempDir(), "config.yaml", tt.file)}, args...)
+			}
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			_, _, err := LoadConfig(args)
+			if err == nil {
+				t.Fatal("LoadConfig() error = nil")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		wantErr string // rỗng là cấu hình hợp lệ
+	}{
+		{"defaults", func(*Config) {}, ""},
+		{"random listen port", func(c *Config) { c.AppPort = "127.0.0.1:0" }, ""},
+		{"listen address without port", func(c *Config) { c.AppPort = "8080" }, "app.port"},
+		{"port out of range", func(c *Config) { c.DBPort = "70000" }, "db.port: invalid port"},
+		{"negative duration", func(c *Config) { c.ShutdownDelay = -time.Second }, "shutdown.delay must not be negative"},
+		{"zero shutdown delay", func(c *Config) { c.ShutdownDelay = 0 }, ""},
+		{"zero shutdown timeout", func(c *Config) { c.ShutdownTimeout = 0 }, "shutdown.timeout must be positive"},
+		{"idle above open conns", func(c *Config) { c.DBMaxOpenConns, c.DBMaxIdleConns = 5, 10 }, "db.max_idle_conns (10)"},
+		{"unlimited open conns", func(c *Config) { c.DBMaxOpenConns, c.DBMaxIdleConns = 0, 10 }, ""},
+		{"trusted proxies", func(c *Config) { c.HTTPTrustedProxies = "10.0.0.0/8, 127.0.0.1" }, ""},
+		{"invalid trusted proxy", func(c *Config) { c.HTTPTrustedProxies = "proxy.local" }, "http.trusted_proxies"},
+		{"replica without port uses db.port", func(c *Config) { c.DBReplicaHosts = "replica-1, replica-2:3307" }, ""},
+		{"invalid replica port", func(c *Config) { c.DBReplicaHosts = "replica-1:0" }, "db.replica.hosts"},
+		{"redis username without password", func(c *Config) { c.RedisUsername = "app" }, "redis.username requires redis.password"},
+		{"redis tls option without tls", func(c *Config) { c.RedisTLSServerName = "redis" }, "redis.tls.* options require"},
+		{"unknown log level", func(c *Config) { c.LogLevel = "trace" }, "log.level"},
+		{"log level is case insensitive", func(c *Config) { c.LogLevel = "DEBUG" }, ""},
+		{"otlp endpoint must be a URL", func(c *Config) { c.TracingExporter, c.TracingEndpoint = "otlp", "collector:4318" }, "tracing.endpoint"},
+		{"sample ratio above one", func(c *Config) { c.TracingSampleRatio = 1.5 }, "tracing.sample_ratio"},
+		{"rate limit without burst", func(c *Config) { c.RateLimitRPS, c.RateLimitBurst = 10, 0 }, "ratelimit.burst"},
+		{"relative public base url", func(c *Config) { c.PublicBaseURL = "/blog" }, "public_base_url"},
+		{"sitemap page size", func(c *Config) { c.SitemapPageSize = 50001 }, "sitemap.page_size"},
+		{"retention without purge interval", func(c *Config) { c.TrashRetention, c.TrashPurgeInterval = time.Hour, 0 }, "trash.purge_interval"},
+		{"unknown child policy", func(c *Config) { c.CategoryChildPolicy = "cascade" }, "category.child_policy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			tt.modify(cfg)
+			err := cfg.Validate()
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("Validate() error = %v", err)
+			case tt.wantErr != "" && err == nil:
+				t.Errorf("Validate() error = nil, want %q", tt.wantErr)
+			case tt.wantErr != "" && !strings.Contains(err.Error(), tt.wantErr):
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStringRedactsSecrets(t *testing.T) {
+	cfg := Default()
+	cfg.DBPassword = "hunter2"
+	cfg.RedisPassword = "hunter3"
+	s := cfg.String()
+	if strings.Contains(s, "hunter2") || strings.Contains(s, "hunter3") {
+		t.Errorf("String() leaks a secret: %s", s)
+	}
+	if !strings.Contains(s, "db.password="+redactedValue) {
+		t.Errorf("String() = %s, want db.password redacted", s)
+	}
+}
@@ -0,0 +1,337 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileEnv là biến môi trường chỉ định tệp YAML khi không truyền flag -config
+const ConfigFileEnv = "CONFIG_FILE"
+
+// redactedValue thay cho giá trị của khóa bí mật khi in cấu hình
+const redactedValue = "******"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// field là một khóa cấu hình gắn với trường tương ứng của Config
+type field struct {
+	key    string
+	secret bool
+	reload bool
+	value  reflect.Value
+}
+
+// fields liệt kê các khóa cấu hình theo thứ tự khai báo trong Config
+func (c *Config) fields() []field {
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+
+	fields := make([]field, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		key, ok := t.Field(i).Tag.Lookup("config")
+		if !ok {
+			continue
+		}
+		fields = append(fields, field{
+			key:    key,
+			secret: t.Field(i).Tag.Get("secret") == "true",
+			reload: t.Field(i).Tag.Get("reload") == "true",
+			value:  v.Field(i),
+		})
+	}
+	return fields
+}
+
+// envName: db.max_open_conns -> DB_MAX_OPEN_CONNS
+func envName(key string) string {
+	return strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// parse chuyển chuỗi sang kiểu của trường mà không gán, để báo lỗi sớm (ví dụ khi đọc flag)
+func (f field) parse(raw string) (reflect.Value, error) {
+	raw = strings.TrimSpace(raw)
+	switch {
+	case f.value.Type() == durationType:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("invalid duration %q (expected e.g. 500ms, 30s, 5m)", raw)
+		}
+		return reflect.ValueOf(d), nil
+	case f.value.Kind() == reflect.String:
+		return reflect.ValueOf(raw), nil
+	case f.value.Kind() == reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("invalid integer %q", raw)
+		}
+		return reflect.ValueOf(n), nil
+	case f.value.Kind() == reflect.Float64:
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("invalid number %q", raw)
+		}
+		return reflect.ValueOf(n), nil
+	case f.value.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("invalid boolean %q", raw)
+		}
+		return reflect.ValueOf(b), nil
+	default:
+		return reflect.Value{}, fmt.Errorf("unsupported type %s", f.value.Type())
+	}
+}
+
+func (f field) set(raw string) error {
+	v, err := f.parse(raw)
+	if err != nil {
+		return err
+	}
+	f.value.Set(v)
+	return nil
+}
+
+// String trả về giá trị để in ra log, khóa bí mật bị che nếu đã được đặt
+func (f field) String() string {
+	if f.secret && !f.value.IsZero() {
+		return redactedValue
+	}
+	return fmt.Sprint(f.value.Interface())
+}
+
+// readSecretFile đọc giá trị từ tệp secret, bỏ ký tự xuống dòng ở cuối mà editor/`echo` thường thêm vào
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// flagValue giữ lại giá trị của flag để áp dụng sau tệp YAML và biến môi trường
+type flagValue struct {
+	f       field
+	pending *[]pendingFlag
+}
+
+type pendingFlag struct {
+	f   field
+	raw string
+}
+
+func (v flagValue) String() string {
+	return ""
+}
+
+func (v flagValue) Set(raw string) error {
+	if _, err := v.f.parse(raw); err != nil {
+		return err
+	}
+	*v.pending = append(*v.pending, pendingFlag{f: v.f, raw: raw})
+	return nil
+}
+
+// IsBoolFlag cho phép viết -auto_migrate thay cho -auto_migrate=true
+func (v flagValue) IsBoolFlag() bool {
+	return v.f.value.Kind() == reflect.Bool
+}
+
+// LoadConfig đọc cấu hình theo thứ tự: mặc định -> tệp YAML (-config hoặc CONFIG_FILE) -> biến môi trường -> flag.
+// args là tham số dòng lệnh không gồm tên chương trình, phần còn lại sau các flag (subcommand) được trả về.
+// Mọi lỗi (khóa lạ, sai kiểu, giá trị không hợp lệ) được gom lại và trả về cùng lúc.
+func LoadConfig(args []string) (*Config, []string, error) {
+	cfg, rest, _, err := load(args)
+	return cfg, rest, err
+}
+
+// load giống LoadConfig và trả thêm đường dẫn tệp YAML đã dùng (rỗng nếu không có)
+func load(args []string) (*Config, []string, string, error) {
+	cfg := Default()
+	fields := cfg.fields()
+
+	var pending []pendingFlag
+	fs := flag.NewFlagSet("server", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	configFile := fs.String("config", os.Getenv(ConfigFileEnv), "path to YAML config file")
+	for _, f := range fields {
+		// Secret không nhận qua flag để không lộ trong danh sách tiến trình, dùng biến môi trường hoặc *_FILE
+		if f.secret {
+			continue
+		}
+		fs.Var(flagValue{f: f, pending: &pending}, f.key, "overrides "+envName(f.key))
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fs.SetOutput(os.Stderr)
+			fs.PrintDefaults()
+		}
+		return nil, nil, "", err
+	}
+
+	var errs []error
+	if *configFile != "" {
+		if err := cfg.loadFile(*configFile, fields); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	errs = append(errs, loadEnv(fields)...)
+	for _, p := range pending {
+		// Giá trị đã được kiểm tra trong flagValue.Set
+		_ = p.f.set(p.raw)
+	}
+	if len(errs) > 0 {
+		return nil, nil, "", errors.Join(errs...)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, "", err
+	}
+	return cfg, fs.Args(), *configFile, nil
+}
+
+// loadFile áp dụng tệp YAML, khóa lạ là lỗi để phát hiện lỗi chính tả.
+// Đường dẫn tương đối trong khóa *_file được tính từ thư mục chứa tệp cấu hình.
+func (c *Config) loadFile(path string, fields []field) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	if err := flattenYAML("", doc, values); err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	byKey := make(map[string]field, len(fields))
+	for _, f := range fields {
+		byKey[f.key] = f
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var errs []error
+	for _, key := range keys {
+		raw := values[key]
+		if f, ok := byKey[key]; ok {
+			if err := f.set(raw); err != nil {
+				errs = append(errs, fmt.Errorf("config file %s: %s: %w", path, key, err))
+			}
+			continue
+		}
+
+		f, ok := byKey[strings.TrimSuffix(key, "_file")]
+		if !ok || !strings.HasSuffix(key, "_file") {
+			errs = append(errs, fmt.Errorf("config file %s: unknown key %q", path, key))
+			continue
+		}
+		if _, dup := values[f.key]; dup {
+			errs = append(errs, fmt.Errorf("config file %s: both %s and %s are set", path, f.key, key))
+			continue
+		}
+		if !filepath.IsAbs(raw) {
+			raw = filepath.Join(filepath.Dir(path), raw)
+		}
+		secret, err := readSecretFile(raw)
+		if err == nil {
+			err = f.set(secret)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("config file %s: %s: %w", path, key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// flattenYAML chuyển {db: {host: x}} thành {"db.host": "x"}
+func flattenYAML(prefix string, node map[string]any, out map[string]string) error {
+	for k, v := range node {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		switch v := v.(type) {
+		case nil:
+			// Khóa để trống giữ nguyên giá trị mặc định
+		case map[string]any:
+			if err := flattenYAML(key, v, out); err != nil {
+				return err
+			}
+		case []any:
+			return fmt.Errorf("%s: lists are not supported", key)
+		default:
+			out[key] = fmt.Sprint(v)
+		}
+	}
+	return nil
+}
+
+// loadEnv áp dụng biến môi trường A_B và A_B_FILE
+func loadEnv(fields []field) []error {
+	var errs []error
+	for _, f := range fields {
+		name := envName(f.key)
+		value, ok := os.LookupEnv(name)
+		path, fromFile := os.LookupEnv(name + "_FILE")
+
+		switch {
+		case ok && fromFile:
+			errs = append(errs, fmt.Errorf("env: both %s and %s_FILE are set", name, name))
+			continue
+		case fromFile:
+			secret, err := readSecretFile(path)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("env %s_FILE: %w", name, err))
+				continue
+			}
+			value = secret
+		case !ok:
+			continue
+		}
+
+		if err := f.set(value); err != nil {
+			errs = append(errs, fmt.Errorf("env %s: %w", name, err))
+		}
+	}
+	return errs
+}
+
+// String in cấu hình hiệu lực dạng key=value, giá trị của khóa bí mật bị che
+func (c *Config) String() string {
+	fields := c.fields()
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		parts[i] = f.key + "=" + f.String()
+	}
+	return strings.Join(parts, " ")
+}
+
+// LogValue ghi cấu hình hiệu lực thành một nhóm key/value trong log có cấu trúc, khóa bí mật bị che như String
+func (c *Config) LogValue() slog.Value {
+	fields := c.fields()
+	attrs := make([]slog.Attr, len(fields))
+	for i, f := range fields {
+		attrs[i] = slog.String(f.key, f.String())
+	}
+	return slog.GroupValue(attrs...)
+}
@@ -0,0 +1,196 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"Test2/internal/domain"
+)
+
+// snapshot là một phiên bản cấu hình, không bao giờ bị sửa sau khi được công bố
+type snapshot struct {
+	cfg      *Config
+	version  int64
+	loadedAt time.Time
+}
+
+// Store giữ cấu hình hiện hành và reload lúc chạy khi tệp cấu hình thay đổi hoặc nhận SIGHUP.
+// Chỉ khóa có tag reload:"true" được áp dụng, khóa khác giữ giá trị đang chạy tới khi khởi động lại.
+// Cấu hình mới được kiểm tra toàn bộ trước khi thay thế, reload lỗi giữ nguyên cấu hình cũ.
+type Store struct {
+	args []string // Dòng lệnh ban đầu, biến môi trường và flag được áp dụng lại khi reload
+	file string
+
+	current atomic.Pointer[snapshot]
+
+	mu             sync.Mutex // Tuần tự hóa Reload và bảo vệ các trường bên dưới
+	listeners      []func(*Config)
+	lastReloadAt   time.Time
+	lastReloadErr  error
+	pendingRestart []string
+}
+
+// NewStore đọc cấu hình như LoadConfig và trả về Store cùng các tham số còn lại (subcommand)
+func NewStore(args []string) (*Store, []string, error) {
+	cfg, rest, file, err := load(args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s := &Store{args: args, file: file}
+	s.current.Store(&snapshot{cfg: cfg, version: 1, loadedAt: time.Now().UTC()})
+	return s, rest, nil
+}
+
+// Current trả về cấu hình hiện hành, người gọi không được sửa
+func (s *Store) Current() *Config {
+	return s.current.Load().cfg
+}
+
+// OnReload đăng ký hàm được gọi (tuần tự, theo thứ tự đăng ký) sau mỗi lần reload làm thay đổi cấu hình
+func (s *Store) OnReload(fn func(*Config)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, fn)
+}
+
+func (s *Store) Reload() (domain.ConfigStatus, error) {
+	err := s.reload()
+	return s.Status(), err
+}
+
+func (s *Store) reload() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastReloadAt = time.Now().UTC()
+	next, _, _, err := load(s.args)
+	if err == nil {
+		err = s.apply(next)
+	}
+	s.lastReloadErr = err
+	if err != nil {
+		return fmt.Errorf("%w: %w", domain.ErrInvalidConfig, err)
+	}
+	return nil
+}
+
+// apply giữ giá trị đang chạy cho các khóa cần khởi động lại rồi công bố phiên bản mới nếu có thay đổi
+func (s *Store) apply(next *Config) error {
+	cur := s.current.Load()
+
+	var changed, pending []string
+	curFields, nextFields := cur.cfg.fields(), next.fields()
+	for i, f := range nextFields {
+		old := curFields[i].value
+		if f.value.Equal(old) {
+			continue
+		}
+		if !f.reload {
+			pending = append(pending, f.key)
+			f.value.Set(old)
+			continue
+		}
+		changed = append(changed, f.key)
+	}
+	s.pendingRestart = pending
+
+	if len(changed) == 0 {
+		return nil
+	}
+	// Kiểm tra lại vì ràng buộc giữa các khóa có thể bị phá khi giữ giá trị cũ của khóa cần khởi động lại
+	if err := next.Validate(); err != nil {
+		return err
+	}
+
+	s.current.Store(&snapshot{cfg: next, version: cur.version + 1, loadedAt: s.lastReloadAt})
+	for _, fn := range s.listeners {
+		fn(next)
+	}
+	slog.Info("Config reloaded", "version", cur.version+1, "changed", changed)
+	return nil
+}
+
+func (s *Store) Status() domain.ConfigStatus {
+	snap := s.current.Load()
+
+	status := domain.ConfigStatus{
+		Version:  snap.version,
+		Source:   s.file,
+		LoadedAt: snap.loadedAt,
+		Settings: make(map[string]string),
+	}
+	for _, f := range snap.cfg.fields() {
+		status.Settings[f.key] = f.String()
+		if f.reload {
+			status.Reloadable = append(status.Reloadable, f.key)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.lastReloadAt.IsZero() {
+		at := s.lastReloadAt
+		status.LastReloadAt = &at
+	}
+	if s.lastReloadErr != nil {
+		status.LastReloadError = s.lastReloadErr.Error()
+	}
+	status.PendingRestart = s.pendingRestart
+	return status
+}
+
+// fileStamp nhận biết tệp cấu hình đã đổi (kể cả khi ConfigMap của Kubernetes thay symlink)
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+func statFile(path string) fileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}
+}
+
+// Watch reload khi nhận SIGHUP hoặc khi tệp cấu hình thay đổi (kiểm tra theo config.watch_interval) tới khi ctx bị hủy
+func (s *Store) Watch(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	if interval := s.Current().ConfigWatchInterval; s.file != "" && interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	last := statFile(s.file)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			slog.Info("Received SIGHUP, reloading config")
+		case <-tick:
+			stamp := statFile(s.file)
+			if stamp == last {
+				continue
+			}
+			last = stamp
+		}
+
+		if err := s.reload(); err != nil {
+			slog.Error("Config reload rejected, keeping current version", "version", s.current.Load().version, "error", err)
+		}
+	}
+}
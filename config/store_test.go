@@ -0,0 +1,114 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"Test2/internal/domain"
+)
+
+func TestStoreReload(t *testing.T) {
+	tests := []struct {
+		name        string
+		initial     string // config.yaml lúc khởi động
+		updated     string // config.yaml lúc reload
+		wantErr     bool
+		wantVersion int64
+		want        string   // log.level và db.host của cấu hình hiện hành sau reload
+		wantPending []string // khóa chờ khởi động lại trong Status
+		wantCalls   []string // log.level và db.host mà mỗi subscriber nhận được
+	}{
+		{
+			name:        "reloadable key is applied",
+			initial:     "log:\n  level: info\n",
+			updated:     "log:\n  level: debug\n",
+			wantVersion: 2,
+			want:        "debug localhost",
+			wantCalls:   []string{"debug localhost"},
+		},
+		{
+			name:        "rejected reload keeps the previous version",
+			initial:     "log:\n  level: info\n",
+			updated:     "log:\n  level: trace\n",
+			wantErr:     true,
+			wantVersion: 1,
+			want:        "info localhost",
+		},
+		{
+			name:        "unknown key is rejected",
+			initial:     "log:\n  level: info\n",
+			updated:     "log:\n  level: debug\n  levle: warn\n",
+			wantErr:     true,
+			wantVersion: 1,
+			want:        "info localhost",
+		},
+		{
+			name:        "restart-only key is pending and not applied",
+			initial:     "db:\n  host: db-1\n",
+			updated:     "db:\n  host: db-2\n",
+			wantVersion: 1,
+			want:        "info db-1",
+			wantPending: []string{"db.host"},
+		},
+		{
+			name:        "reloadable keys apply while restart-only keys wait",
+			initial:     "log:\n  level: info\ndb:\n  host: db-1\n",
+			updated:     "log:\n  level: warn\ndb:\n  host: db-2\n",
+			wantVersion: 2,
+			want:        "warn db-1",
+			wantPending: []string{"db.host"},
+			wantCalls:   []string{"warn db-1"},
+		},
+		{
+			name:        "unchanged file does not bump the version",
+			initial:     "log:\n  level: debug\n",
+			updated:     "log:\n  level: debug\n",
+			wantVersion: 1,
+			want:        "debug localhost",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			dir := t.TempDir()
+			path := writeFile(t, dir, "config.yaml", tt.initial)
+
+			store, _, err := NewStore([]string{"-config", path})
+			if err != nil {
+				t.Fatalf("NewStore() error = %v", err)
+			}
+			var calls []string
+			store.OnReload(func(c *Config) {
+				calls = append(calls, c.LogLevel+" "+c.DBHost)
+			})
+
+			writeFile(t, dir, "config.yaml", tt.updated)
+			status, err := store.Reload()
+			switch {
+			case tt.wantErr && !errors.Is(err, domain.ErrInvalidConfig):
+				t.Errorf("Reload() error = %v, want ErrInvalidConfig", err)
+			case !tt.wantErr && err != nil:
+				t.Errorf("Reload() error = %v", err)
+			}
+			if tt.wantErr != (status.LastReloadError != "") {
+				t.Errorf("LastReloadError = %q", status.LastReloadError)
+			}
+
+			if status.Version != tt.wantVersion {
+				t.Errorf("Version = %d, want %d", status.Version, tt.wantVersion)
+			}
+			cur := store.Current()
+			if got := cur.LogLevel + " " + cur.DBHost; got != tt.want {
+				t.Errorf("Current() = %q, want %q", got, tt.want)
+			}
+			if got, want := strings.Join(status.PendingRestart, ","), strings.Join(tt.wantPending, ","); got != want {
+				t.Errorf("PendingRestart = %q, want %q", got, want)
+			}
+			if got, want := strings.Join(calls, ","), strings.Join(tt.wantCalls, ","); got != want {
+				t.Errorf("subscribers saw %q, want %q", got, want)
+			}
+		})
+	}
+}
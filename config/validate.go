@@ -0,0 +1,161 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Validate kiểm tra cấu hình hiệu lực và trả về mọi lỗi cùng lúc
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	// Thời lượng âm không có nghĩa với bất kỳ khóa nào
+	for _, f := range c.fields() {
+		if f.value.Type() == durationType && f.value.Interface().(time.Duration) < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative", f.key))
+		}
+	}
+
+	if _, port, err := net.SplitHostPort(c.AppPort); err != nil {
+		errs = append(errs, fmt.Errorf("app.port: %q is not a listen address (expected host:port or :port)", c.AppPort))
+	} else {
+		check(validPort(port, true), "app.port: invalid port %q", port)
+	}
+	check(c.RequestTimeout > 0, "app.request_timeout must be positive")
+	check(c.MaintenanceTimeout > 0, "maintenance.timeout must be positive")
+	for _, p := range c.TrustedProxies() {
+		_, errPrefix := netip.ParsePrefix(p)
+		_, errAddr := netip.ParseAddr(p)
+		check(errPrefix == nil || errAddr == nil, "http.trusted_proxies: %q is not an IP address or CIDR", p)
+	}
+
+	check(c.DBHost != "", "db.host is required")
+	check(validPort(c.DBPort, false), "db.port: invalid port %q", c.DBPort)
+	check(c.DBUser != "", "db.user is required")
+	check(c.DBName != "", "db.name is required")
+	if _, err := time.LoadLocation(c.DBLocation); err != nil {
+		errs = append(errs, fmt.Errorf("db.location: %w", err))
+	}
+	check(c.DBMaxOpenConns >= 0, "db.max_open_conns must not be negative")
+	check(c.DBMaxIdleConns >= 0, "db.max_idle_conns must not be negative")
+	check(c.DBMaxOpenConns == 0 || c.DBMaxIdleConns <= c.DBMaxOpenConns,
+		"db.max_idle_conns (%d) must not exceed db.max_open_conns (%d)", c.DBMaxIdleConns, c.DBMaxOpenConns)
+	check(c.DBDialTimeout > 0, "db.dial_timeout must be positive")
+	if replicas := c.ReplicaAddrs(); len(replicas) > 0 {
+		for _, addr := range replicas {
+			host, port, _ := net.SplitHostPort(addr)
+			check(host != "" && validPort(port, false), "db.replica.hosts: invalid address %q", addr)
+		}
+		check(c.DBReplicaMaxLag > 0, "db.replica.max_lag must be positive")
+		check(c.DBReplicaCheckInterval > 0, "db.replica.check_interval must be positive")
+	}
+
+	check(c.RedisHost != "", "redis.host is required")
+	check(validPort(c.RedisPort, false), "redis.port: invalid port %q", c.RedisPort)
+	check(c.RedisDB >= 0, "redis.db must not be negative")
+	check(c.RedisPoolSize >= 0, "redis.pool_size must not be negative")
+	check(c.RedisMinIdleConns >= 0, "redis.min_idle_conns must not be negative")
+	check(c.RedisBreakerThreshold > 0, "redis.breaker.failure_threshold must be positive")
+	check(c.RedisBreakerProbeInterval > 0, "redis.breaker.probe_interval must be positive")
+	check(c.RedisUsername == "" || c.RedisPassword != "", "redis.username requires redis.password")
+	if !c.RedisTLS {
+		check(c.RedisTLSServerName == "" && c.RedisTLSCAFile == "" && !c.RedisTLSInsecureSkipVerify,
+			"redis.tls.* options require redis.tls.enabled")
+	}
+	if c.RedisTLSCAFile != "" {
+		if _, err := os.Stat(c.RedisTLSCAFile); err != nil {
+			errs = append(errs, fmt.Errorf("redis.tls.ca_file: %w", err))
+		}
+	}
+
+	for _, ttl := range []struct {
+		key   string
+		value time.Duration
+	}{
+		{"cache.post_list_ttl", c.CachePostListTTL},
+		{"cache.post_detail_ttl", c.CachePostDetailTTL},
+		{"cache.post_search_ttl", c.CachePostSearchTTL},
+		{"cache.post_html_ttl", c.CachePostHTMLTTL},
+		{"cache.category_tree_ttl", c.CacheCategoryTreeTTL},
+		{"cache.feed_ttl", c.CacheFeedTTL},
+		{"cache.sitemap_ttl", c.CacheSitemapTTL},
+	} {
+		check(ttl.value > 0, "%s must be positive", ttl.key)
+	}
+
+	switch strings.ToLower(c.LogLevel) {
+	case "debug", "info", "warn", "error":
+	default:
+		errs = append(errs, fmt.Errorf("log.level must be debug, info, warn or error, got %q", c.LogLevel))
+	}
+	check(c.LogFormat == "json" || c.LogFormat == "text", "log.format must be json or text, got %q", c.LogFormat)
+	switch c.TracingExporter {
+	case "none", "stdout":
+	case "otlp":
+		if c.TracingEndpoint != "" {
+			if u, err := url.Parse(c.TracingEndpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				errs = append(errs, fmt.Errorf("tracing.endpoint: %q is not an absolute http(s) URL", c.TracingEndpoint))
+			}
+		}
+	default:
+		errs = append(errs, fmt.Errorf("tracing.exporter must be none, otlp or stdout, got %q", c.TracingExporter))
+	}
+	check(c.TracingServiceName != "", "tracing.service_name is required")
+	check(c.TracingSampleRatio >= 0 && c.TracingSampleRatio <= 1, "tracing.sample_ratio must be between 0 and 1")
+	check(c.RateLimitRPS >= 0, "ratelimit.rps must not be negative")
+	check(c.RateLimitRPS == 0 || c.RateLimitBurst > 0, "ratelimit.burst must be positive when ratelimit.rps is set")
+
+	check(c.ShutdownTimeout > 0, "shutdown.timeout must be positive")
+	check(c.HealthCheckTimeout > 0, "health.check_timeout must be positive")
+
+	check(c.MediaDir != "", "media.dir is required")
+	check(strings.HasPrefix(c.MediaBaseURL, "/"), "media.base_url must start with /")
+	check(c.MediaMaxSize > 0, "media.max_size must be positive")
+	check(c.MediaMaxPixels > 0, "media.max_pixels must be positive")
+
+	if u, err := url.Parse(c.PublicBaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("public_base_url: %q is not an absolute http(s) URL", c.PublicBaseURL))
+	}
+	check(c.FeedLimit > 0, "feed.limit must be positive")
+	check(c.SitemapPageSize > 0 && c.SitemapPageSize <= 50000, "sitemap.page_size must be between 1 and 50000")
+
+	check(c.TrashRetention == 0 || c.TrashPurgeInterval > 0, "trash.purge_interval must be positive when trash.retention is set")
+	check(c.CategoryChildPolicy == "block" || c.CategoryChildPolicy == "reparent",
+		"category.child_policy must be block or reparent, got %q", c.CategoryChildPolicy)
+
+	check(c.OutboxStreamMaxLen >= 0, "outbox.stream_maxlen must not be negative")
+	check(c.OutboxRelayInterval > 0, "outbox.relay_interval must be positive")
+
+	check(c.WebhookMaxAttempts > 0, "webhook.max_attempts must be positive")
+	check(c.WebhookDisableAfter >= 0, "webhook.disable_after must not be negative")
+	check(c.WebhookTimeout > 0, "webhook.timeout must be positive")
+	check(c.WebhookRetryBase > 0, "webhook.retry_base must be positive")
+	check(c.WebhookDispatchInterval > 0, "webhook.dispatch_interval must be positive")
+
+	check(c.StreamChannel != "", "stream.channel is required")
+	check(c.StreamReplaySize > 0, "stream.replay_size must be positive")
+	check(c.StreamHeartbeat > 0, "stream.heartbeat must be positive")
+
+	return errors.Join(errs...)
+}
+
+// validPort: số cổng TCP, allowZero cho địa chỉ lắng nghe (cổng ngẫu nhiên)
+func validPort(port string, allowZero bool) bool {
+	n, err := strconv.Atoi(port)
+	if err != nil {
+		return false
+	}
+	return n <= 65535 && (n > 0 || (allowZero && n == 0))
+}
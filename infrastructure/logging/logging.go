@@ -0,0 +1,73 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"Test2/internal/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// level là mức log hiện hành, đổi được lúc chạy qua SetLevel
+var level = new(slog.LevelVar)
+
+// Setup đặt slog làm logger mặc định ghi ra stderr dạng JSON (hoặc text khi chạy local) với mức log đổi được lúc chạy.
+// Bản ghi log kèm context (slog.InfoContext, ...) tự có request_id và actor của request đang xử lý.
+// Lời gọi log.Printf của thư viện bên thứ ba đi qua slog ở mức INFO.
+func Setup(lvl, format string) error {
+	if err := SetLevel(lvl); err != nil {
+		return err
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case "json":
+		handler = slog.NewJSONHandler(os.Stderr, opts)
+	case "text":
+		handler = slog.NewTextHandler(os.Stderr, opts)
+	default:
+		return fmt.Errorf("logging: unknown format %q", format)
+	}
+	slog.SetDefault(slog.New(contextHandler{handler}))
+	return nil
+}
+
+// SetLevel đổi mức log: debug, info, warn hoặc error
+func SetLevel(lvl string) error {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(strings.ToLower(lvl))); err != nil {
+		return fmt.Errorf("logging: %w", err)
+	}
+	level.Set(l)
+	return nil
+}
+
+// contextHandler thêm request_id và actor từ domain.RequestMeta của context vào mỗi bản ghi, cùng trace_id
+// và span_id khi context mang span, nhờ vậy có thể lần theo một request qua handler, UseCase, MySQL và Redis
+// và nhảy từ log sang trace tương ứng
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if meta, ok := domain.RequestMetaFromContext(ctx); ok {
+		r.AddAttrs(slog.String("request_id", meta.RequestID), slog.String("actor", meta.Actor))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,369 @@
+// Package migrate áp dụng các migration SQL có phiên bản (xem package migrations) lên MySQL.
+//
+// Các migration đã chạy được ghi trong bảng schema_migrations. Mỗi thao tác up/down giữ một named lock
+// (GET_LOCK) nên nhiều instance khởi động cùng lúc sẽ chạy lần lượt thay vì chạy trùng.
+// DDL của MySQL không nằm trong transaction được, vì vậy migration được đánh dấu dirty trước khi chạy
+// và chỉ được xóa dấu khi thành công; migration lỗi giữa chừng phải được sửa tay rồi `migrate force`.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	lockName    = "schema_migrations"
+	lockTimeout = 60 // giây
+)
+
+var (
+	ErrDirty         = errors.New("schema is dirty, a previous migration failed part-way")
+	ErrLockTimeout   = errors.New("timed out waiting for the migration lock")
+	ErrUnknownTarget = errors.New("unknown migration version")
+)
+
+var fileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration là một cặp tệp up/down
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status là trạng thái của một migration. Migration chỉ có trong database (do binary mới hơn chạy) có Unknown = true.
+type Status struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	Dirty     bool       `json:"dirty,omitempty"`
+	Unknown   bool       `json:"unknown,omitempty"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+type appliedRow struct {
+	name      string
+	dirty     bool
+	appliedAt time.Time
+}
+
+// Migrator chạy migration trên db. db phải được mở với multiStatements=true vì mỗi tệp có thể chứa nhiều câu lệnh.
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// New đọc các migration trong fsys và kiểm tra mỗi version có đủ cặp up/down
+func New(db *sql.DB, fsys fs.FS) (*Migrator, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migrate: invalid migration file name %q", entry.Name())
+		}
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		body, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migrate: version %d has two names (%s, %s)", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if strings.TrimSpace(m.Up) == "" || strings.TrimSpace(m.Down) == "" {
+			return nil, fmt.Errorf("migrate: version %d (%s) needs both an up and a down file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+func (m *Migrator) ensureTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		dirty BOOLEAN NOT NULL DEFAULT FALSE,
+		applied_at DATETIME NOT NULL
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`)
+	return err
+}
+
+func (m *Migrator) applied(ctx context.Context, conn *sql.Conn) (map[int64]appliedRow, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version, name, dirty, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[int64]appliedRow)
+	for rows.Next() {
+		var version int64
+		var row appliedRow
+		if err := rows.Scan(&version, &row.name, &row.dirty, &row.appliedAt); err != nil {
+			return nil, err
+		}
+		result[version] = row
+	}
+	return result, rows.Err()
+}
+
+// withLock chạy fn trên một connection riêng đang giữ named lock
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var acquired sql.NullInt64
+	if err := conn.QueryRowContext(ctx, `SELECT GET_LOCK(?, ?)`, lockName, lockTimeout).Scan(&acquired); err != nil {
+		return err
+	}
+	if !acquired.Valid || acquired.Int64 != 1 {
+		return ErrLockTimeout
+	}
+	defer conn.ExecContext(context.WithoutCancel(ctx), `SELECT RELEASE_LOCK(?)`, lockName)
+
+	if err := m.ensureTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+func checkDirty(applied map[int64]appliedRow) error {
+	for version, row := range applied {
+		if row.dirty {
+			return fmt.Errorf("%w: version %d (%s)", ErrDirty, version, row.name)
+		}
+	}
+	return nil
+}
+
+// Up chạy tối đa n migration chưa áp dụng theo thứ tự version (n <= 0: tất cả), trả về các migration đã chạy
+func (m *Migrator) Up(ctx context.Context, n int) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := checkDirty(applied); err != nil {
+			return err
+		}
+
+		for _, mig := range m.migrations {
+			if n > 0 && len(done) >= n {
+				break
+			}
+			if _, ok := applied[mig.Version]; ok {
+				continue
+			}
+
+			if _, err := conn.ExecContext(ctx, `INSERT INTO schema_migrations (version, name, dirty, applied_at) VALUES (?, ?, TRUE, ?)`,
+				mig.Version, mig.Name, time.Now()); err != nil {
+				return err
+			}
+			if _, err := conn.ExecContext(ctx, mig.Up); err != nil {
+				return fmt.Errorf("migrate: up %d (%s): %w", mig.Version, mig.Name, err)
+			}
+			if _, err := conn.ExecContext(ctx, `UPDATE schema_migrations SET dirty = FALSE, applied_at = ? WHERE version = ?`,
+				time.Now(), mig.Version); err != nil {
+				return err
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down hoàn tác n migration đã áp dụng có version cao nhất (n <= 0 được hiểu là 1), trả về các migration đã hoàn tác
+func (m *Migrator) Down(ctx context.Context, n int) ([]Migration, error) {
+	if n <= 0 {
+		n = 1
+	}
+
+	var done []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := checkDirty(applied); err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && len(done) < n; i-- {
+			mig := m.migrations[i]
+			if _, ok := applied[mig.Version]; !ok {
+				continue
+			}
+
+			if _, err := conn.ExecContext(ctx, `UPDATE schema_migrations SET dirty = TRUE WHERE version = ?`, mig.Version); err != nil {
+				return err
+			}
+			if _, err := conn.ExecContext(ctx, mig.Down); err != nil {
+				return fmt.Errorf("migrate: down %d (%s): %w", mig.Version, mig.Name, err)
+			}
+			if _, err := conn.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = ?`, mig.Version); err != nil {
+				return err
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Force xóa dấu dirty của version, coi như migration đã được áp dụng đầy đủ (sau khi đã sửa tay)
+func (m *Migrator) Force(ctx context.Context, version int64) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		var name string
+		for _, mig := range m.migrations {
+			if mig.Version == version {
+				name = mig.Name
+			}
+		}
+		if name == "" {
+			return fmt.Errorf("%w: %d", ErrUnknownTarget, version)
+		}
+
+		_, err := conn.ExecContext(ctx, `INSERT INTO schema_migrations (version, name, dirty, applied_at) VALUES (?, ?, FALSE, ?)
+			ON DUPLICATE KEY UPDATE dirty = FALSE`, version, name, time.Now())
+		return err
+	})
+}
+
+// Status liệt kê mọi migration đã biết cùng các version chỉ có trong database, theo thứ tự version
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := m.ensureTable(ctx, conn); err != nil {
+		return nil, err
+	}
+	applied, err := m.applied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	known := make(map[int64]bool, len(m.migrations))
+	for _, mig := range m.migrations {
+		known[mig.Version] = true
+		s := Status{Version: mig.Version, Name: mig.Name}
+		if row, ok := applied[mig.Version]; ok {
+			s.Applied = true
+			s.Dirty = row.dirty
+			s.AppliedAt = &row.appliedAt
+		}
+		statuses = append(statuses, s)
+	}
+	for version, row := range applied {
+		if !known[version] {
+			appliedAt := row.appliedAt
+			statuses = append(statuses, Status{Version: version, Name: row.name, Applied: true, Dirty: row.dirty, Unknown: true, AppliedAt: &appliedAt})
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+// Check trả về lỗi khi schema có migration dirty hoặc còn migration chưa áp dụng, dùng trước khi khởi động server
+func (m *Migrator) Check(ctx context.Context) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	var pending []string
+	for _, s := range statuses {
+		if s.Dirty {
+			return fmt.Errorf("%w: version %d (%s)", ErrDirty, s.Version, s.Name)
+		}
+		if !s.Applied {
+			pending = append(pending, fmt.Sprintf("%d_%s", s.Version, s.Name))
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("schema is behind, %d pending migration(s): %s", len(pending), strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// SchemaVersion tóm tắt trạng thái schema cho trang health
+type SchemaVersion struct {
+	Version int64  `json:"version"` // Version cao nhất đã áp dụng, 0 khi chưa có
+	Name    string `json:"name,omitempty"`
+	Dirty   bool   `json:"dirty"`
+	Pending int    `json:"pending"` // Số migration của binary chưa được áp dụng
+	Unknown int    `json:"unknown"` // Số migration đã áp dụng nhưng binary không biết (binary cũ hơn schema)
+}
+
+// Name và Details cho phép dùng Migrator làm domain.HealthDetailer
+func (m *Migrator) Name() string {
+	return "schema"
+}
+
+func (m *Migrator) Details(ctx context.Context) (any, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &SchemaVersion{}
+	for _, s := range statuses {
+		switch {
+		case !s.Applied:
+			v.Pending++
+		default:
+			if s.Unknown {
+				v.Unknown++
+			}
+			if s.Dirty {
+				v.Dirty = true
+			}
+			if s.Version > v.Version {
+				v.Version, v.Name = s.Version, s.Name
+			}
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,229 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"Test2/internal/domain"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	redisclient "github.com/redis/go-redis/v9"
+)
+
+var (
+	circuitOpenGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "cms_redis_circuit_open",
+		Help: "1 khi circuit breaker của Redis đang mở (ứng dụng chạy không cache), 0 khi bình thường.",
+	})
+	circuitTripsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "cms_redis_circuit_trips_total",
+		Help: "Số lần circuit breaker của Redis chuyển sang trạng thái mở.",
+	})
+	circuitSkippedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "cms_redis_circuit_skipped_total",
+		Help: "Số lệnh Redis bị bỏ qua vì circuit breaker đang mở.",
+	})
+)
+
+// probeKey đánh dấu context của lệnh probe, được đi qua khi breaker đang mở
+type probeKey struct{}
+
+// CircuitBreaker là hook của go-redis: sau threshold lỗi kết nối liên tiếp, mọi lệnh và pipeline
+// trả về domain.ErrCacheUnavailable ngay mà không chạm tới mạng. Run ping Redis theo chu kỳ
+// và đóng breaker khi Redis phản hồi lại.
+type CircuitBreaker struct {
+	ping          func(ctx context.Context) error // client.Ping, thay được trong test
+	threshold     int
+	probeInterval time.Duration
+
+	mu        sync.Mutex
+	open      bool
+	failures  int
+	openedAt  time.Time
+	trips     int64
+	skipped   int64
+	onRecover []func(ctx context.Context) error
+}
+
+func NewCircuitBreaker(client *redisclient.Client, threshold int, probeInterval time.Duration) *CircuitBreaker {
+	b := &CircuitBreaker{
+		ping:          func(ctx context.Context) error { return client.Ping(ctx).Err() },
+		threshold:     max(threshold, 1),
+		probeInterval: probeInterval,
+	}
+	client.AddHook(b)
+	return b
+}
+
+// OnRecover đăng ký hàm chạy khi Redis phục hồi, trước khi breaker đóng lại. Dùng để xóa cache
+// có thể đã cũ vì các lệnh xóa cache bị bỏ qua trong lúc Redis lỗi; hàm lỗi thì breaker vẫn mở và thử lại ở lần probe sau.
+func (b *CircuitBreaker) OnRecover(fn func(ctx context.Context) error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onRecover = append(b.onRecover, fn)
+}
+
+func (b *CircuitBreaker) Stats() domain.CircuitStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stats := domain.CircuitStats{
+		State:               domain.CircuitClosed,
+		ConsecutiveFailures: b.failures,
+		Trips:               b.trips,
+		Skipped:             b.skipped,
+	}
+	if b.open {
+		stats.State = domain.CircuitOpen
+		openedAt := b.openedAt
+		stats.OpenedAt = &openedAt
+	}
+	return stats
+}
+
+// isFailure: chỉ lỗi kết nối/timeout làm breaker mở. Cache miss, lỗi do Redis trả về (kết nối vẫn tốt)
+// và request bị client hủy không được tính.
+func isFailure(err error) bool {
+	if err == nil || errors.Is(err, redisclient.Nil) || errors.Is(err, context.Canceled) ||
+		errors.Is(err, domain.ErrCacheUnavailable) {
+		return false
+	}
+	var redisErr redisclient.Error
+	return !errors.As(err, &redisErr)
+}
+
+// allow trả về false khi breaker đang mở và ctx không phải của probe
+func (b *CircuitBreaker) allow(ctx context.Context) bool {
+	if ctx.Value(probeKey{}) != nil {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.open {
+		return true
+	}
+	b.skipped++
+	circuitSkippedTotal.Inc()
+	return false
+}
+
+func (b *CircuitBreaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !isFailure(err) {
+		if err == nil {
+			b.failures = 0
+		}
+		return
+	}
+	b.failures++
+	if !b.open && b.failures >= b.threshold {
+		b.trip(err)
+	}
+}
+
+// trip mở breaker, người gọi giữ b.mu
+func (b *CircuitBreaker) trip(cause error) {
+	b.open = true
+	b.openedAt = time.Now().UTC()
+	b.trips++
+	circuitTripsTotal.Inc()
+	circuitOpenGauge.Set(1)
+	slog.Warn("Redis circuit breaker opened, running uncached", "consecutive_failures", b.failures, "error", cause)
+}
+
+// Trip mở breaker ngay, dùng khi Redis không phản hồi lúc khởi động
+func (b *CircuitBreaker) Trip(cause error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.open {
+		b.failures = max(b.failures, b.threshold)
+		b.trip(cause)
+	}
+}
+
+// DialHook không chặn: pool của go-redis tự dial lại ở nền sau lỗi và phải dial được khi Redis phục hồi,
+// lỗi dial của lệnh thường đã được ProcessHook ghi nhận
+func (b *CircuitBreaker) DialHook(next redisclient.DialHook) redisclient.DialHook {
+	return next
+}
+
+func (b *CircuitBreaker) ProcessHook(next redisclient.ProcessHook) redisclient.ProcessHook {
+	return func(ctx context.Context, cmd redisclient.Cmder) error {
+		if !b.allow(ctx) {
+			cmd.SetErr(domain.ErrCacheUnavailable)
+			return domain.ErrCacheUnavailable
+		}
+		err := next(ctx, cmd)
+		b.record(err)
+		return err
+	}
+}
+
+func (b *CircuitBreaker) ProcessPipelineHook(next redisclient.ProcessPipelineHook) redisclient.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redisclient.Cmder) error {
+		if !b.allow(ctx) {
+			for _, cmd := range cmds {
+				cmd.SetErr(domain.ErrCacheUnavailable)
+			}
+			return domain.ErrCacheUnavailable
+		}
+		err := next(ctx, cmds)
+		b.record(err)
+		return err
+	}
+}
+
+// Run ping Redis mỗi probeInterval khi breaker đang mở tới khi ctx bị hủy
+func (b *CircuitBreaker) Run(ctx context.Context) {
+	ticker := time.NewTicker(b.probeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		b.mu.Lock()
+		open := b.open
+		b.mu.Unlock()
+		if open {
+			b.probe(ctx)
+		}
+	}
+}
+
+func (b *CircuitBreaker) probe(ctx context.Context) {
+	c, cancel := context.WithTimeout(context.WithValue(ctx, probeKey{}, true), b.probeInterval)
+	defer cancel()
+
+	if err := b.ping(c); err != nil {
+		return
+	}
+
+	b.mu.Lock()
+	hooks := b.onRecover
+	b.mu.Unlock()
+	// Lệnh của hook cũng đi qua breaker nên được đánh dấu như probe
+	for _, fn := range hooks {
+		if err := fn(c); err != nil {
+			slog.Warn("Redis is reachable but recovery failed, keeping circuit breaker open", "error", err)
+			return
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.open = false
+	b.failures = 0
+	circuitOpenGauge.Set(0)
+	slog.Info("Redis is reachable again, circuit breaker closed", "open_for", time.Since(b.openedAt).Round(time.Second).String())
+}
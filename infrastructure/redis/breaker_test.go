@@ -0,0 +1,142 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"Test2/internal/domain"
+
+	redisclient "github.com/redis/go-redis/v9"
+)
+
+// replyError là lỗi Redis trả về qua kết nối còn tốt (ví dụ WRONGTYPE)
+type replyError string
+
+func (e replyError) Error() string { return string(e) }
+func (replyError) RedisError()     {}
+
+var errConnRefused = &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+
+func TestCircuitBreakerStateMachine(t *testing.T) {
+	// Bước: ok, miss, reply, canceled, conn là kết quả của một lệnh; trip là Trip();
+	// probe-ok/probe-fail là một lần probe; recover-fail là probe thành công nhưng hook OnRecover lỗi
+	tests := []struct {
+		name      string
+		threshold int
+		steps     []string
+		wantState string
+		wantTrips int64
+		wantFails int
+	}{
+		{"closed on success", 2, []string{"ok", "ok"}, domain.CircuitClosed, 0, 0},
+		{"below threshold stays closed", 3, []string{"conn", "conn"}, domain.CircuitClosed, 0, 2},
+		{"opens at threshold", 2, []string{"conn", "conn"}, domain.CircuitOpen, 1, 2},
+		{"success resets the count", 2, []string{"conn", "ok", "conn"}, domain.CircuitClosed, 0, 1},
+		{"cache miss is not a failure", 1, []string{"miss", "miss"}, domain.CircuitClosed, 0, 0},
+		{"reply error is not a failure", 1, []string{"reply"}, domain.CircuitClosed, 0, 0},
+		{"canceled request is not a failure", 1, []string{"canceled"}, domain.CircuitClosed, 0, 0},
+		{"non-failure keeps the count", 2, []string{"conn", "miss", "conn"}, domain.CircuitOpen, 1, 2},
+		{"open breaker skips commands", 1, []string{"conn", "ok", "ok"}, domain.CircuitOpen, 1, 1},
+		{"failed probe keeps it open", 1, []string{"conn", "probe-fail"}, domain.CircuitOpen, 1, 1},
+		{"successful probe closes it", 1, []string{"conn", "probe-ok"}, domain.CircuitClosed, 1, 0},
+		{"failed recovery keeps it open", 1, []string{"conn", "recover-fail"}, domain.CircuitOpen, 1, 1},
+		{"reopens after recovery", 1, []string{"conn", "probe-ok", "conn"}, domain.CircuitOpen, 2, 1},
+		{"trip opens immediately", 5, []string{"trip"}, domain.CircuitOpen, 1, 5},
+		{"trip on open breaker is a no-op", 1, []string{"conn", "trip"}, domain.CircuitOpen, 1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := redisclient.NewClient(&redisclient.Options{Addr: "127.0.0.1:0"})
+			defer client.Close()
+			b := NewCircuitBreaker(client, tt.threshold, time.Second)
+
+			var pingErr, recoverErr error
+			b.ping = func(context.Context) error { return pingErr }
+			b.OnRecover(func(context.Context) error { return recoverErr })
+
+			for _, step := range tt.steps {
+				pingErr, recoverErr = nil, nil
+				switch step {
+				case "trip":
+					b.Trip(errConnRefused)
+					continue
+				case "probe-ok":
+					b.probe(context.Background())
+					continue
+				case "probe-fail":
+					pingErr = errConnRefused
+					b.probe(context.Background())
+					continue
+				case "recover-fail":
+					recoverErr = errors.New("flush failed")
+					b.probe(context.Background())
+					continue
+				}
+
+				result := map[string]error{
+					"ok":       nil,
+					"miss":     redisclient.Nil,
+					"reply":    replyError("WRONGTYPE"),
+					"canceled": context.Canceled,
+					"conn":     errConnRefused,
+				}[step]
+				wasOpen := b.Stats().State == domain.CircuitOpen
+				called := false
+				hook := b.ProcessHook(func(context.Context, redisclient.Cmder) error {
+					called = true
+					return result
+				})
+				err := hook(context.Background(), redisclient.NewStatusCmd(context.Background(), "ping"))
+
+				if wasOpen && (called || !errors.Is(err, domain.ErrCacheUnavailable)) {
+					t.Fatalf("step %s: open breaker let the command through (called=%v, err=%v)", step, called, err)
+				}
+				if !wasOpen && !called {
+					t.Fatalf("step %s: closed breaker skipped the command", step)
+				}
+			}
+
+			stats := b.Stats()
+			if stats.State != tt.wantState {
+				t.Errorf("State = %s, want %s", stats.State, tt.wantState)
+			}
+			if stats.Trips != tt.wantTrips {
+				t.Errorf("Trips = %d, want %d", stats.Trips, tt.wantTrips)
+			}
+			if stats.ConsecutiveFailures != tt.wantFails {
+				t.Errorf("ConsecutiveFailures = %d, want %d", stats.ConsecutiveFailures, tt.wantFails)
+			}
+			if (stats.OpenedAt != nil) != (tt.wantState == domain.CircuitOpen) {
+				t.Errorf("OpenedAt = %v with state %s", stats.OpenedAt, stats.State)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerProbeBypassesOpenBreaker(t *testing.T) {
+	client := redisclient.NewClient(&redisclient.Options{Addr: "127.0.0.1:0"})
+	defer client.Close()
+	b := NewCircuitBreaker(client, 1, time.Second)
+	b.Trip(errConnRefused)
+
+	called := false
+	hook := b.ProcessPipelineHook(func(context.Context, []redisclient.Cmder) error {
+		called = true
+		return nil
+	})
+	ctx := context.WithValue(context.Background(), probeKey{}, true)
+	if err := hook(ctx, nil); err != nil || !called {
+		t.Errorf("probe pipeline: called=%v, err=%v", called, err)
+	}
+
+	called = false
+	cmd := redisclient.NewStatusCmd(context.Background(), "ping")
+	err := hook(context.Background(), []redisclient.Cmder{cmd})
+	if called || !errors.Is(err, domain.ErrCacheUnavailable) || !errors.Is(cmd.Err(), domain.ErrCacheUnavailable) {
+		t.Errorf("pipeline on open breaker: called=%v, err=%v, cmd err=%v", called, err, cmd.Err())
+	}
+}
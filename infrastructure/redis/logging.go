@@ -0,0 +1,67 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	redisclient "github.com/redis/go-redis/v9"
+)
+
+// loggingHook ghi mỗi lệnh và pipeline Redis ở mức debug kèm khóa, thời gian và kết quả.
+// request_id được logger mặc định lấy từ ctx.
+type loggingHook struct{}
+
+func (loggingHook) DialHook(next redisclient.DialHook) redisclient.DialHook {
+	return next
+}
+
+func (loggingHook) ProcessHook(next redisclient.ProcessHook) redisclient.ProcessHook {
+	return func(ctx context.Context, cmd redisclient.Cmder) error {
+		if !slog.Default().Enabled(ctx, slog.LevelDebug) {
+			return next(ctx, cmd)
+		}
+
+		start := time.Now()
+		err := next(ctx, cmd)
+		attrs := []slog.Attr{
+			slog.String("cmd", cmd.Name()),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+		}
+		// Tham số đầu tiên sau tên lệnh là khóa (hoặc kênh), giá trị không được ghi
+		if args := cmd.Args(); len(args) > 1 {
+			if key, ok := args[1].(string); ok {
+				attrs = append(attrs, slog.String("key", key))
+			}
+		}
+		switch {
+		case errors.Is(err, redisclient.Nil):
+			attrs = append(attrs, slog.String("result", "miss"))
+		case err != nil:
+			attrs = append(attrs, slog.Any("error", err))
+		}
+		slog.LogAttrs(ctx, slog.LevelDebug, "redis command", attrs...)
+		return err
+	}
+}
+
+func (loggingHook) ProcessPipelineHook(next redisclient.ProcessPipelineHook) redisclient.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redisclient.Cmder) error {
+		if !slog.Default().Enabled(ctx, slog.LevelDebug) {
+			return next(ctx, cmds)
+		}
+
+		start := time.Now()
+		err := next(ctx, cmds)
+		attrs := []slog.Attr{
+			slog.Int("cmds", len(cmds)),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+		}
+		if err != nil && !errors.Is(err, redisclient.Nil) {
+			attrs = append(attrs, slog.Any("error", err))
+		}
+		slog.LogAttrs(ctx, slog.LevelDebug, "redis pipeline", attrs...)
+		return err
+	}
+}
@@ -0,0 +1,46 @@
+package redis
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	redisclient "github.com/redis/go-redis/v9"
+)
+
+// poolCollector xuất PoolStats của go-redis mỗi lần /metrics được scrape
+type poolCollector struct {
+	client *redisclient.Client
+
+	hits, misses, timeouts, waits, waitSeconds, stale *prometheus.Desc
+	total, idle                                       *prometheus.Desc
+}
+
+func newPoolCollector(client *redisclient.Client) *poolCollector {
+	return &poolCollector{
+		client:      client,
+		hits:        prometheus.NewDesc("cms_redis_pool_hits_total", "Số lần lấy được kết nối rảnh có sẵn trong pool Redis.", nil, nil),
+		misses:      prometheus.NewDesc("cms_redis_pool_misses_total", "Số lần pool Redis không có kết nối rảnh và phải mở kết nối mới.", nil, nil),
+		timeouts:    prometheus.NewDesc("cms_redis_pool_timeouts_total", "Số lần chờ kết nối từ pool Redis bị quá hạn (pool cạn).", nil, nil),
+		waits:       prometheus.NewDesc("cms_redis_pool_waits_total", "Số lần phải chờ kết nối vì pool Redis đã dùng hết.", nil, nil),
+		waitSeconds: prometheus.NewDesc("cms_redis_pool_wait_seconds_total", "Tổng thời gian chờ kết nối từ pool Redis.", nil, nil),
+		stale:       prometheus.NewDesc("cms_redis_pool_stale_connections_total", "Số kết nối cũ bị gỡ khỏi pool Redis.", nil, nil),
+		total:       prometheus.NewDesc("cms_redis_pool_connections", "Số kết nối đang mở trong pool Redis.", nil, nil),
+		idle:        prometheus.NewDesc("cms_redis_pool_idle_connections", "Số kết nối rảnh trong pool Redis.", nil, nil),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{c.hits, c.misses, c.timeouts, c.waits, c.waitSeconds, c.stale, c.total, c.idle} {
+		ch <- d
+	}
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.client.PoolStats()
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(c.timeouts, prometheus.CounterValue, float64(stats.Timeouts))
+	ch <- prometheus.MustNewConstMetric(c.waits, prometheus.CounterValue, float64(stats.WaitCount))
+	ch <- prometheus.MustNewConstMetric(c.waitSeconds, prometheus.CounterValue, float64(stats.WaitDurationNs)/1e9)
+	ch <- prometheus.MustNewConstMetric(c.stale, prometheus.CounterValue, float64(stats.StaleConns))
+	ch <- prometheus.MustNewConstMetric(c.total, prometheus.GaugeValue, float64(stats.TotalConns))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stats.IdleConns))
+}
@@ -2,28 +2,89 @@ package redis
 
 import (
 	"context"
-	"log"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
 
 	"Test2/config"
+
+	"github.com/prometheus/client_golang/prometheus"
 	redisclient "github.com/redis/go-redis/v9"
 )
 
 var Ctx = context.Background()
 var Client *redisclient.Client
 
-// Sửa chữ ký hàm để nhận tham số cấu hình
-func InitRedis(cfg *config.Config) {
-	Client = redisclient.NewClient(&redisclient.Options{
-		Addr:     cfg.GetRedisAddr(), // Sử dụng địa chỉ động từ config
-		Password: "",                 // Có thể mở rộng để lấy Password từ config nếu cần
-		DB:       0,
-	})
+// Breaker bảo vệ Client, khi mở thì cache bị bỏ qua và ứng dụng chạy chỉ với MySQL
+var Breaker *CircuitBreaker
+
+// ErrUnavailable: Redis không phản hồi lúc khởi động, Client vẫn được tạo với Breaker đang mở
+var ErrUnavailable = errors.New("redis is unavailable")
+
+// InitRedis khởi tạo client toàn cục và kiểm tra kết nối. Khi Redis không phản hồi, lỗi bọc ErrUnavailable
+// được trả về nhưng Client và Breaker vẫn dùng được (ứng dụng chạy không cache tới khi Redis phục hồi).
+func InitRedis(cfg *config.Config) error {
+	opts := &redisclient.Options{
+		Addr:         cfg.GetRedisAddr(), // Sử dụng địa chỉ động từ config
+		Username:     cfg.RedisUsername,
+		Password:     cfg.RedisPassword,
+		DB:           int(cfg.RedisDB),
+		PoolSize:     int(cfg.RedisPoolSize),
+		MinIdleConns: int(cfg.RedisMinIdleConns),
+		DialTimeout:  cfg.RedisDialTimeout,
+		ReadTimeout:  cfg.RedisReadTimeout,
+		WriteTimeout: cfg.RedisWriteTimeout,
+	}
+	if cfg.RedisTLS {
+		tlsConfig, err := tlsConfig(cfg)
+		if err != nil {
+			return err
+		}
+		opts.TLSConfig = tlsConfig
+	}
+	Client = redisclient.NewClient(opts)
+	// Hook tracing thêm trước breaker nên bọc ngoài breaker: lệnh bị breaker bỏ qua vẫn có span (lỗi ErrCacheUnavailable)
+	Client.AddHook(newTracingHook(opts.Addr, opts.DB))
+	Breaker = NewCircuitBreaker(Client, int(cfg.RedisBreakerThreshold), cfg.RedisBreakerProbeInterval)
+	// Hook thêm sau chạy bên trong breaker: lệnh bị breaker bỏ qua không được log
+	Client.AddHook(loggingHook{})
+	// Thống kê pool (kết nối mở/rảnh, số lần chờ) xuất trên /metrics
+	prometheus.MustRegister(newPoolCollector(Client))
 
 	// Kiểm tra kết nối
-	_, err := Client.Ping(Ctx).Result()
-	if err != nil {
-		log.Fatalf("Redis connection failed at %s: %v", cfg.GetRedisAddr(), err)
+	if _, err := Client.Ping(Ctx).Result(); err != nil {
+		Breaker.Trip(err)
+		return fmt.Errorf("%w at %s: %w", ErrUnavailable, cfg.GetRedisAddr(), err)
 	}
 
-	log.Printf("Redis connected successfully at %s", cfg.GetRedisAddr())
+	slog.Info("Redis connected", "addr", cfg.GetRedisAddr())
+	return nil
+}
+
+// tlsConfig dựng cấu hình TLS, CA riêng được thêm vào thay cho CA của hệ thống
+func tlsConfig(cfg *config.Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         cfg.RedisTLSServerName,
+		InsecureSkipVerify: cfg.RedisTLSInsecureSkipVerify, // Chỉ dùng cho môi trường thử nghiệm
+	}
+	if tlsConfig.ServerName == "" {
+		tlsConfig.ServerName = cfg.RedisHost
+	}
+
+	if cfg.RedisTLSCAFile != "" {
+		pem, err := os.ReadFile(cfg.RedisTLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("redis tls: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("redis tls: no certificates found in " + cfg.RedisTLSCAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
 }
@@ -0,0 +1,105 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+
+	redisclient "github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("Test2/infrastructure/redis")
+
+// tracingHook tạo span con cho mỗi lệnh và pipeline Redis chạy trong một trace đang được ghi.
+// Chỉ tên lệnh và khóa được ghi vào span, giá trị thì không.
+type tracingHook struct {
+	attrs []attribute.KeyValue
+}
+
+func newTracingHook(addr string, db int) tracingHook {
+	attrs := []attribute.KeyValue{semconv.DBSystemNameRedis, semconv.DBNamespace(strconv.Itoa(db))}
+	if host, port, err := net.SplitHostPort(addr); err == nil {
+		attrs = append(attrs, semconv.ServerAddress(host))
+		if n, err := strconv.Atoi(port); err == nil {
+			attrs = append(attrs, semconv.ServerPort(n))
+		}
+	}
+	return tracingHook{attrs: attrs}
+}
+
+func (h tracingHook) DialHook(next redisclient.DialHook) redisclient.DialHook {
+	return next
+}
+
+// commandText là tên lệnh kèm khóa (tham số đầu tiên sau tên lệnh), ví dụ "GET post:1"
+func commandText(cmd redisclient.Cmder) string {
+	text := strings.ToUpper(cmd.Name())
+	if args := cmd.Args(); len(args) > 1 {
+		if key, ok := args[1].(string); ok {
+			text += " " + key
+		}
+	}
+	return text
+}
+
+func (h tracingHook) start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(h.attrs...),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// end ghi lỗi vào span, cache miss (redis.Nil) không phải lỗi
+func end(span trace.Span, err error) {
+	switch {
+	case errors.Is(err, redisclient.Nil):
+		span.SetAttributes(attribute.String("db.redis.result", "miss"))
+	case err != nil:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func (h tracingHook) ProcessHook(next redisclient.ProcessHook) redisclient.ProcessHook {
+	return func(ctx context.Context, cmd redisclient.Cmder) error {
+		if !trace.SpanFromContext(ctx).IsRecording() {
+			return next(ctx, cmd)
+		}
+
+		operation := strings.ToUpper(cmd.Name())
+		ctx, span := h.start(ctx, operation, semconv.DBOperationName(operation), semconv.DBQueryText(commandText(cmd)))
+		err := next(ctx, cmd)
+		end(span, err)
+		return err
+	}
+}
+
+func (h tracingHook) ProcessPipelineHook(next redisclient.ProcessPipelineHook) redisclient.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redisclient.Cmder) error {
+		if !trace.SpanFromContext(ctx).IsRecording() {
+			return next(ctx, cmds)
+		}
+
+		texts := make([]string, len(cmds))
+		for i, cmd := range cmds {
+			texts[i] = commandText(cmd)
+		}
+		ctx, span := h.start(ctx, "PIPELINE",
+			semconv.DBOperationName("PIPELINE"),
+			semconv.DBOperationBatchSize(len(cmds)),
+			semconv.DBQueryText(strings.Join(texts, "\n")),
+		)
+		err := next(ctx, cmds)
+		end(span, err)
+		return err
+	}
+}
@@ -0,0 +1,78 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	goldmarkhtml "github.com/yuin/goldmark/renderer/html"
+
+	"Test2/internal/domain"
+)
+
+// contentRenderer chuyển nội dung bài viết sang HTML an toàn.
+// Mọi định dạng đều đi qua sanitiser allow-list trước khi trả về.
+type contentRenderer struct {
+	markdown goldmark.Markdown
+	policy   *bluemonday.Policy
+}
+
+func NewContentRenderer() domain.ContentRenderer {
+	md := goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		// Cho phép HTML thô trong Markdown, phần nguy hiểm sẽ bị sanitiser loại bỏ
+		goldmark.WithRendererOptions(goldmarkhtml.WithUnsafe()),
+	)
+
+	// UGCPolicy: allow-list các thẻ/thuộc tính định dạng thông dụng, chặn script, style, event handler
+	policy := bluemonday.UGCPolicy()
+	policy.RequireNoFollowOnLinks(true)
+	policy.AddTargetBlankToFullyQualifiedLinks(true)
+
+	return &contentRenderer{
+		markdown: md,
+		policy:   policy,
+	}
+}
+
+func (r *contentRenderer) Render(format string, content string) (string, error) {
+	var raw string
+
+	switch format {
+	case domain.ContentFormatMarkdown:
+		var buf bytes.Buffer
+		if err := r.markdown.Convert([]byte(content), &buf); err != nil {
+			return "", err
+		}
+		raw = buf.String()
+	case domain.ContentFormatHTML:
+		raw = content
+	case domain.ContentFormatPlaintext:
+		raw = plaintextToHTML(content)
+	default:
+		return "", fmt.Errorf("%w: %q", domain.ErrInvalidContentFormat, format)
+	}
+
+	return r.policy.Sanitize(raw), nil
+}
+
+// plaintextToHTML escape toàn bộ nội dung, mỗi đoạn (cách nhau bởi dòng trống) thành một thẻ <p>
+func plaintextToHTML(content string) string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+
+	var sb strings.Builder
+	for _, para := range strings.Split(content, "\n\n") {
+		para = strings.TrimSpace(para)
+		if para == "" {
+			continue
+		}
+		sb.WriteString("<p>")
+		sb.WriteString(strings.ReplaceAll(html.EscapeString(para), "\n", "<br>\n"))
+		sb.WriteString("</p>\n")
+	}
+	return sb.String()
+}
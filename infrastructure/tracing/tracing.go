@@ -0,0 +1,97 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+
+	"Test2/config"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
+)
+
+// defaultOTLPPath là đường dẫn nhận trace của OTLP/HTTP khi tracing.endpoint không ghi rõ
+const defaultOTLPPath = "/v1/traces"
+
+// Setup cài TracerProvider toàn cục theo tracing.exporter và propagator W3C (traceparent, tracestate, baggage).
+// Propagator luôn được cài để trace context của nơi gọi (traceparent) vẫn có trong ctx và log kể cả khi tracing tắt.
+// Shutdown trả về đẩy nốt các span còn trong hàng đợi, cần gọi trước khi thoát.
+func Setup(ctx context.Context, cfg *config.Config) (shutdown func(ctx context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		slog.Warn("OpenTelemetry error", "error", err)
+	}))
+
+	var (
+		exporter sdktrace.SpanExporter
+		closer   io.Closer
+	)
+	switch cfg.TracingExporter {
+	case "none":
+		// TracerProvider mặc định của otel không ghi span, chi phí gần như bằng 0
+		return func(context.Context) error { return nil }, nil
+	case "otlp":
+		var opts []otlptracehttp.Option
+		if cfg.TracingEndpoint != "" {
+			u, err := url.Parse(cfg.TracingEndpoint)
+			if err != nil {
+				return nil, fmt.Errorf("tracing: %w", err)
+			}
+			if u.Path == "" || u.Path == "/" {
+				u.Path = defaultOTLPPath
+			}
+			opts = append(opts, otlptracehttp.WithEndpointURL(u.String()))
+		}
+		// Exporter không kết nối khi khởi tạo, collector lỗi chỉ làm mất span chứ không chặn server
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	case "stdout":
+		var w io.Writer = os.Stdout
+		if cfg.TracingFile != "" {
+			f, ferr := os.OpenFile(cfg.TracingFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+			if ferr != nil {
+				return nil, fmt.Errorf("tracing: %w", ferr)
+			}
+			w, closer = f, f
+		}
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(w))
+	default:
+		return nil, fmt.Errorf("tracing: unknown exporter %q", cfg.TracingExporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("tracing: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(cfg.TracingServiceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("tracing: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		// Request có traceparent theo quyết định lấy mẫu của nơi gọi, trace gốc lấy mẫu theo tỉ lệ
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.TracingSampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	slog.Info("Tracing enabled", "exporter", cfg.TracingExporter, "sample_ratio", cfg.TracingSampleRatio)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closer != nil {
+			err = errors.Join(err, closer.Close())
+		}
+		return err
+	}, nil
+}
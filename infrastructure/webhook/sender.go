@@ -0,0 +1,93 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+
+	"Test2/internal/domain"
+)
+
+// maxResponseBody là số byte tối đa của response được đọc và lưu vào delivery log
+const maxResponseBody = 1 << 10
+
+// ErrNonPublicAddress: URL của webhook phân giải ra địa chỉ nội bộ (loopback, mạng riêng, link-local, ...)
+var ErrNonPublicAddress = errors.New("webhook: refusing to connect to a non-public address")
+
+// httpSender gửi webhook bằng net/http, không theo redirect để tránh chuyển payload đã ký sang URL khác
+type httpSender struct {
+	client *http.Client
+}
+
+// NewHTTPSender khởi tạo sender. Khi allowPrivate = false, địa chỉ IP được kiểm tra ngay trước khi kết nối
+// (sau khi phân giải DNS) nên tên miền trỏ về hoặc bị đổi sang (DNS rebinding) IP nội bộ cũng bị chặn.
+func NewHTTPSender(timeout time.Duration, allowPrivate bool) domain.WebhookSender {
+	dialer := &net.Dialer{Timeout: 10 * time.Second, KeepAlive: 30 * time.Second}
+	if !allowPrivate {
+		dialer.Control = checkDialAddr
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// Proxy của môi trường sẽ nhận kết nối thay cho receiver và bỏ qua kiểm tra địa chỉ
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &httpSender{
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// checkDialAddr chạy với địa chỉ IP đã phân giải của từng lần kết nối
+func checkDialAddr(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrNonPublicAddress, address)
+	}
+	if !domain.IsPublicWebhookAddr(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrNonPublicAddress, addrPort.Addr())
+	}
+	return nil
+}
+
+func (s *httpSender) Send(ctx context.Context, url string, headers map[string]string, body []byte) domain.WebhookResponse {
+	start := time.Now()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return domain.WebhookResponse{Err: err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "cms-webhook/1.0")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return domain.WebhookResponse{Duration: time.Since(start), Err: err}
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	// Đọc nốt phần còn lại (có giới hạn) để connection được tái sử dụng
+	_, _ = io.CopyN(io.Discard, resp.Body, 64<<10)
+
+	return domain.WebhookResponse{
+		StatusCode: resp.StatusCode,
+		Body:       string(data),
+		Duration:   time.Since(start),
+	}
+}
@@ -0,0 +1,105 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSenderDeliversToReceiver(t *testing.T) {
+	type received struct {
+		body    string
+		headers http.Header
+	}
+	got := make(chan received, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		got <- received{body: string(data), headers: r.Header.Clone()}
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = io.WriteString(w, strings.Repeat("x", 2*maxResponseBody))
+	}))
+	defer receiver.Close()
+
+	sender := NewHTTPSender(5*time.Second, true)
+	resp := sender.Send(context.Background(), receiver.URL, map[string]string{"X-Webhook-Signature": "sha256=abc"}, []byte(`{"id":1}`))
+
+	if resp.Err != nil {
+		t.Fatalf("Send() error = %v", resp.Err)
+	}
+	if resp.StatusCode != http.StatusAccepted {
+		t.Errorf("StatusCode = %d, want %d", resp.StatusCode, http.StatusAccepted)
+	}
+	if len(resp.Body) != maxResponseBody {
+		t.Errorf("len(Body) = %d, want %d (truncated)", len(resp.Body), maxResponseBody)
+	}
+	req := <-got
+	if req.body != `{"id":1}` {
+		t.Errorf("receiver got body %q", req.body)
+	}
+	if sig := req.headers.Get("X-Webhook-Signature"); sig != "sha256=abc" {
+		t.Errorf("receiver got signature %q", sig)
+	}
+	if ct := req.headers.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("receiver got Content-Type %q", ct)
+	}
+}
+
+func TestSenderBlocksNonPublicAddresses(t *testing.T) {
+	var hits atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer receiver.Close()
+	port := receiver.URL[strings.LastIndex(receiver.URL, ":")+1:]
+
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{"loopback ip", receiver.URL},
+		// Tên miền được kiểm tra theo IP sau khi phân giải, giống trường hợp DNS rebinding
+		{"hostname resolving to loopback", "http://localhost:" + port},
+		{"cloud metadata", "http://169.254.169.254/latest/meta-data/"},
+	}
+	sender := NewHTTPSender(2*time.Second, false)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := sender.Send(context.Background(), tt.url, nil, []byte(`{}`))
+			if !errors.Is(resp.Err, ErrNonPublicAddress) {
+				t.Fatalf("Send(%s) error = %v, want ErrNonPublicAddress", tt.url, resp.Err)
+			}
+		})
+	}
+	if n := hits.Load(); n != 0 {
+		t.Errorf("receiver was reached %d times", n)
+	}
+}
+
+func TestSenderDoesNotFollowRedirects(t *testing.T) {
+	var redirected atomic.Bool
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redirected.Store(true)
+	}))
+	defer target.Close()
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	}))
+	defer receiver.Close()
+
+	resp := NewHTTPSender(5*time.Second, true).Send(context.Background(), receiver.URL, nil, []byte(`{}`))
+	if resp.Err != nil {
+		t.Fatalf("Send() error = %v", resp.Err)
+	}
+	if resp.StatusCode != http.StatusTemporaryRedirect {
+		t.Errorf("StatusCode = %d, want %d", resp.StatusCode, http.StatusTemporaryRedirect)
+	}
+	if redirected.Load() {
+		t.Error("redirect was followed")
+	}
+}
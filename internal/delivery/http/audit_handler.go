@@ -0,0 +1,76 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"Test2/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditHandler cho phép tra cứu audit log của Post/Category
+type AuditHandler struct {
+	AuditUseCase domain.AuditUseCase
+}
+
+// NewAuditHandler khởi tạo Handler và đăng ký routes
+func NewAuditHandler(r *gin.Engine, us domain.AuditUseCase) {
+	handler := &AuditHandler{
+		AuditUseCase: us,
+	}
+
+	admin := r.Group("/api/v1/admin")
+	{
+		admin.GET("/audit", handler.Fetch)
+	}
+}
+
+// parseTimeQuery đọc tham số thời gian dạng RFC3339, rỗng trả về nil
+func parseTimeQuery(c *gin.Context, name string) (*time.Time, bool) {
+	raw := c.Query(name)
+	if raw == "" {
+		return nil, true
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + name + ", expected RFC3339"})
+		return nil, false
+	}
+	return &t, true
+}
+
+// Fetch: ?entity=post|category&entity_id=&actor=&from=&to=&page=&page_size=
+func (h *AuditHandler) Fetch(c *gin.Context) {
+	page, _ := strconv.ParseInt(c.DefaultQuery("page", "1"), 10, 64)
+	pageSize, _ := strconv.ParseInt(c.DefaultQuery("page_size", "10"), 10, 64)
+
+	filter := domain.AuditFilter{
+		EntityType: c.Query("entity"),
+		Actor:      c.Query("actor"),
+	}
+	if raw := c.Query("entity_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid entity_id"})
+			return
+		}
+		filter.EntityID = id
+	}
+
+	var ok bool
+	if filter.From, ok = parseTimeQuery(c, "from"); !ok {
+		return
+	}
+	if filter.To, ok = parseTimeQuery(c, "to"); !ok {
+		return
+	}
+
+	entries, err := h.AuditUseCase.Fetch(c.Request.Context(), filter, page, pageSize)
+	if err != nil {
+		c.JSON(inputErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": entries})
+}
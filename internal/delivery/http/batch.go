@@ -0,0 +1,22 @@
+package http
+
+import "Test2/internal/domain"
+
+// Body của các endpoint thao tác hàng loạt, giới hạn số phần tử do UseCase kiểm tra (domain.BatchMaxItems)
+
+type batchIDsRequest struct {
+	IDs []int64 `json:"ids" binding:"required"`
+}
+
+type batchStatusRequest struct {
+	IDs    []int64 `json:"ids" binding:"required"`
+	Status string  `json:"status" binding:"required"`
+}
+
+type batchPostsRequest struct {
+	Items []domain.Post `json:"items" binding:"required"`
+}
+
+type batchCategoriesRequest struct {
+	Items []domain.Category `json:"items" binding:"required"`
+}
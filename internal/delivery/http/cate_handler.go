@@ -23,8 +23,19 @@ func NewCateHandler(r *gin.Engine, us domain.CategoryUseCase) {
 		v1.POST("/categories/add", handler.Store)
 		v1.GET("/categories/list", handler.Fetch)
 		v1.GET("/categories/find/:id", handler.GetByID)
+		v1.GET("/categories/tree", handler.Tree)
+		v1.GET("/categories/breadcrumb/:id", handler.Breadcrumb)
+		v1.PUT("/categories/reorder", handler.Reorder)
+
+		// Thao tác hàng loạt, chạy trong một transaction và trả về kết quả từng phần tử
+		v1.POST("/categories/batch/add", handler.StoreBatch)
+		v1.POST("/categories/batch/delete", handler.DeleteBatch)
+		v1.PUT("/categories/batch/restore", handler.RestoreBatch)
 		v1.PUT("/categories/update/:id", handler.Update)
 		v1.DELETE("/categories/delete/:id", handler.Delete)
+		v1.GET("/categories/trash", handler.FetchTrash)
+		v1.PUT("/categories/restore/:id", handler.Restore)
+		v1.DELETE("/categories/purge/:id", handler.Purge)
 	}
 }
 
@@ -40,7 +51,7 @@ func (h *CateHandler) Store(c *gin.Context) {
 	err := h.CateUseCase.Store(ctx, &cate)
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(inputErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -48,13 +59,14 @@ func (h *CateHandler) Store(c *gin.Context) {
 }
 
 func (h *CateHandler) Fetch(c *gin.Context) {
-	// Lấy params page & page_size từ URL
+	// Lấy params page, page_size & sort (newest | oldest | title | position) từ URL
 	page, _ := strconv.ParseInt(c.DefaultQuery("page", "1"), 10, 64)
 	pageSize, _ := strconv.ParseInt(c.DefaultQuery("page_size", "10"), 10, 64)
+	sort := c.DefaultQuery("sort", domain.CategorySortNewest)
 
-	categories, err := h.CateUseCase.Fetch(c.Request.Context(), page, pageSize)
+	categories, err := h.CateUseCase.Fetch(c.Request.Context(), page, pageSize, sort)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(inputErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"data": categories})
@@ -93,7 +105,7 @@ func (h *CateHandler) Update(c *gin.Context) {
 	err = h.CateUseCase.Update(c.Request.Context(), &cate)
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(inputErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -109,8 +121,149 @@ func (h *CateHandler) Delete(c *gin.Context) {
 
 	err = h.CateUseCase.Delete(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(inputErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Category soft deleted successfully"})
 }
+
+// Whole category hierarchy
+func (h *CateHandler) Tree(c *gin.Context) {
+	tree, err := h.CateUseCase.Tree(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": tree})
+}
+
+// Path from the root category down to the requested one
+func (h *CateHandler) Breadcrumb(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	path, err := h.CateUseCase.Breadcrumb(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": path})
+}
+
+// reorderRequest là body của PUT /categories/reorder, ids là thứ tự mới của toàn bộ danh mục con
+type reorderRequest struct {
+	ParentID *int64  `json:"parent_id"`
+	IDs      []int64 `json:"ids" binding:"required"`
+}
+
+// Apply a drag-and-drop ordering within one parent scope
+func (h *CateHandler) Reorder(c *gin.Context) {
+	var req reorderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := h.CateUseCase.Reorder(c.Request.Context(), req.ParentID, req.IDs)
+	if err != nil {
+		c.JSON(inputErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Categories reordered successfully"})
+}
+
+// List soft-deleted items (trash)
+func (h *CateHandler) FetchTrash(c *gin.Context) {
+	page, _ := strconv.ParseInt(c.DefaultQuery("page", "1"), 10, 64)
+	pageSize, _ := strconv.ParseInt(c.DefaultQuery("page_size", "10"), 10, 64)
+
+	categories, err := h.CateUseCase.FetchTrash(c.Request.Context(), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": categories})
+}
+
+// Restore from trash to the pre-delete status
+func (h *CateHandler) Restore(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	err = h.CateUseCase.Restore(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Category restored successfully"})
+}
+
+// Permanently delete an item that is already in the trash
+func (h *CateHandler) Purge(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	err = h.CateUseCase.Purge(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Category permanently deleted"})
+}
+
+// Create many categories at once
+func (h *CateHandler) StoreBatch(c *gin.Context) {
+	var req batchCategoriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.CateUseCase.StoreBatch(c.Request.Context(), req.Items)
+	if err != nil {
+		c.JSON(inputErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
+
+// Soft delete many categories, child categories follow CATEGORY_CHILD_POLICY
+func (h *CateHandler) DeleteBatch(c *gin.Context) {
+	var req batchIDsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.CateUseCase.DeleteBatch(c.Request.Context(), req.IDs)
+	if err != nil {
+		c.JSON(inputErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
+
+// Restore many categories from the trash
+func (h *CateHandler) RestoreBatch(c *gin.Context) {
+	var req batchIDsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.CateUseCase.RestoreBatch(c.Request.Context(), req.IDs)
+	if err != nil {
+		c.JSON(inputErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
@@ -0,0 +1,41 @@
+package http
+
+import (
+	"net/http"
+
+	"Test2/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConfigHandler cho admin xem phiên bản cấu hình đang chạy và reload cấu hình
+type ConfigHandler struct {
+	ConfigManager domain.ConfigManager
+}
+
+// NewConfigHandler khởi tạo Handler và đăng ký routes
+func NewConfigHandler(r *gin.Engine, cm domain.ConfigManager) {
+	handler := &ConfigHandler{
+		ConfigManager: cm,
+	}
+
+	admin := r.Group("/api/v1/admin")
+	{
+		admin.GET("/config", handler.Status)
+		admin.POST("/config/reload", handler.Reload)
+	}
+}
+
+func (h *ConfigHandler) Status(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": h.ConfigManager.Status()})
+}
+
+// Reload đọc lại tệp cấu hình như khi nhận SIGHUP, cấu hình không hợp lệ bị từ chối và cấu hình cũ được giữ nguyên
+func (h *ConfigHandler) Reload(c *gin.Context) {
+	status, err := h.ConfigManager.Reload()
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": status})
+}
@@ -0,0 +1,56 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"Test2/internal/domain"
+)
+
+// errorStatus ánh xạ lỗi nghiệp vụ sang HTTP status, các lỗi khác dùng fallback
+func errorStatus(err error, fallback int) int {
+	switch {
+	case errors.Is(err, domain.ErrMediaTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, domain.ErrUnsupportedMediaType):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, domain.ErrMediaNotFound),
+		errors.Is(err, domain.ErrCategoryNotFound),
+		errors.Is(err, domain.ErrWebhookNotFound),
+		errors.Is(err, domain.ErrWebhookDeliveryNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidWebhookURL),
+		errors.Is(err, domain.ErrInvalidWebhookEvent),
+		errors.Is(err, domain.ErrInvalidWebhookStatus):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrInvalidConfig):
+		return http.StatusUnprocessableEntity
+	default:
+		return fallback
+	}
+}
+
+// inputErrorStatus dùng cho các thao tác ghi của Post/Category: dữ liệu tham chiếu hoặc định dạng sai là lỗi của client
+func inputErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrMediaNotFound),
+		errors.Is(err, domain.ErrInvalidContentFormat),
+		errors.Is(err, domain.ErrParentCategoryNotFound),
+		errors.Is(err, domain.ErrCategoryCycle),
+		errors.Is(err, domain.ErrInvalidCategorySort),
+		errors.Is(err, domain.ErrInvalidReorder),
+		errors.Is(err, domain.ErrInvalidStatus),
+		errors.Is(err, domain.ErrEmptyBatch),
+		errors.Is(err, domain.ErrBatchTooLarge),
+		errors.Is(err, domain.ErrInvalidSlug),
+		errors.Is(err, domain.ErrInvalidTransferFormat),
+		errors.Is(err, domain.ErrInvalidAuditFilter):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrCategoryHasChildren),
+		errors.Is(err, domain.ErrBatchConflict),
+		errors.Is(err, domain.ErrDuplicateSlug):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,57 @@
+package http
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Tên feature flag
+const (
+	FeatureStream   = "stream"   // SSE thông báo thay đổi
+	FeatureSearch   = "search"   // Tìm kiếm bài viết
+	FeatureTransfer = "transfer" // Export/import bài viết và import WXR
+)
+
+// featureRoutes cho biết route (theo c.FullPath) thuộc feature nào, route không có trong danh sách luôn bật
+var featureRoutes = map[string]string{
+	"/api/v1/stream":                FeatureStream,
+	"/api/v1/posts/search/:keyword": FeatureSearch,
+	"/api/v1/admin/export/posts":    FeatureTransfer,
+	"/api/v1/admin/import/posts":    FeatureTransfer,
+	"/api/v1/admin/import/wxr":      FeatureTransfer,
+}
+
+// FeatureFlags giữ trạng thái bật/tắt của các feature, thay được lúc chạy qua Set
+type FeatureFlags struct {
+	v atomic.Pointer[map[string]bool]
+}
+
+func NewFeatureFlags(flags map[string]bool) *FeatureFlags {
+	f := &FeatureFlags{}
+	f.Set(flags)
+	return f
+}
+
+// Set thay toàn bộ trạng thái trong một bước
+func (f *FeatureFlags) Set(flags map[string]bool) {
+	f.v.Store(&flags)
+}
+
+// Enabled: feature không có trong danh sách được coi là bật
+func (f *FeatureFlags) Enabled(name string) bool {
+	enabled, ok := (*f.v.Load())[name]
+	return !ok || enabled
+}
+
+// FeatureGate trả về 503 cho route thuộc feature đang tắt
+func FeatureGate(f *FeatureFlags) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if name, ok := featureRoutes[c.FullPath()]; ok && !f.Enabled(name) {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Feature " + name + " is disabled"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,85 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"Test2/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FeedHandler phục vụ RSS, Atom và JSON Feed cho các bài Published
+type FeedHandler struct {
+	FeedUseCase domain.FeedUseCase
+}
+
+// NewFeedHandler khởi tạo Handler và đăng ký routes (ngoài /api/v1 để aggregator dễ dùng)
+func NewFeedHandler(r *gin.Engine, us domain.FeedUseCase) {
+	handler := &FeedHandler{
+		FeedUseCase: us,
+	}
+
+	feeds := r.Group("/feeds")
+	{
+		feeds.GET("/posts.rss", handler.serve(domain.FeedFormatRSS))
+		feeds.GET("/posts.atom", handler.serve(domain.FeedFormatAtom))
+		feeds.GET("/posts.json", handler.serve(domain.FeedFormatJSON))
+		feeds.GET("/categories/:id/posts.rss", handler.serve(domain.FeedFormatRSS))
+		feeds.GET("/categories/:id/posts.atom", handler.serve(domain.FeedFormatAtom))
+		feeds.GET("/categories/:id/posts.json", handler.serve(domain.FeedFormatJSON))
+	}
+}
+
+func (h *FeedHandler) serve(format string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var categoryID int64
+		if raw := c.Param("id"); raw != "" {
+			id, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil || id <= 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+				return
+			}
+			categoryID = id
+		}
+
+		feed, err := h.FeedUseCase.GetPostFeed(c.Request.Context(), format, categoryID)
+		if err != nil {
+			c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Header("ETag", feed.ETag)
+		c.Header("Last-Modified", feed.LastModified.UTC().Format(http.TimeFormat))
+		c.Header("Cache-Control", "public, max-age=300")
+
+		if notModified(c.Request, feed) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+
+		c.Data(http.StatusOK, feed.ContentType, feed.Body)
+	}
+}
+
+// notModified xử lý conditional GET: If-None-Match được ưu tiên hơn If-Modified-Since (RFC 9110)
+func notModified(r *http.Request, feed *domain.Feed) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == feed.ETag {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" {
+		if t, err := http.ParseTime(ims); err == nil {
+			return !feed.LastModified.Truncate(time.Second).After(t)
+		}
+	}
+	return false
+}
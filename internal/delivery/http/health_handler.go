@@ -0,0 +1,59 @@
+package http
+
+import (
+	"net/http"
+
+	"Test2/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HealthHandler phục vụ liveness/readiness probe cho orchestrator và trang health chi tiết cho admin
+type HealthHandler struct {
+	HealthUseCase domain.HealthUseCase
+}
+
+// NewHealthHandler khởi tạo Handler và đăng ký routes. /healthz và /readyz nằm ngoài /api/v1
+// theo quy ước của Kubernetes/docker healthcheck.
+func NewHealthHandler(r *gin.Engine, uh domain.HealthUseCase) {
+	handler := &HealthHandler{
+		HealthUseCase: uh,
+	}
+
+	r.GET("/healthz", handler.Liveness)
+	r.GET("/readyz", handler.Readiness)
+
+	admin := r.Group("/api/v1/admin")
+	{
+		admin.GET("/health", handler.Details)
+	}
+}
+
+// Liveness chỉ cho biết tiến trình còn phục vụ được request, không kiểm tra dependency
+// để orchestrator không khởi động lại app khi MySQL/Redis gặp sự cố
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": domain.HealthStatusOK})
+}
+
+// Readiness trả về 503 khi có dependency bắt buộc không phản hồi hoặc instance đang shutdown,
+// degraded (Redis lỗi, chạy không cache) vẫn trả về 200 vì MySQL đủ để phục vụ mọi request
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	report := h.HealthUseCase.Readiness(c.Request.Context())
+	c.Header("Cache-Control", "no-store")
+	c.JSON(healthStatusCode(report.Status), report)
+}
+
+func (h *HealthHandler) Details(c *gin.Context) {
+	details := h.HealthUseCase.Details(c.Request.Context())
+	c.Header("Cache-Control", "no-store")
+	c.JSON(healthStatusCode(details.Status), details)
+}
+
+func healthStatusCode(status string) int {
+	switch status {
+	case domain.HealthStatusOK, domain.HealthStatusDegraded:
+		return http.StatusOK
+	default:
+		return http.StatusServiceUnavailable
+	}
+}
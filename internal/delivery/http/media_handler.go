@@ -0,0 +1,110 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"Test2/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead là phần dư cho boundary/header của multipart ngoài dung lượng tệp
+const multipartOverhead = 1 << 20
+
+// MediaHandler hứng các request upload và quản lý thư viện media
+type MediaHandler struct {
+	MediaUseCase  domain.MediaUseCase
+	MaxUploadSize int64
+}
+
+// NewMediaHandler khởi tạo Handler và đăng ký routes
+func NewMediaHandler(r *gin.Engine, us domain.MediaUseCase, maxUploadSize int64) {
+	handler := &MediaHandler{
+		MediaUseCase:  us,
+		MaxUploadSize: maxUploadSize,
+	}
+
+	v1 := r.Group("/api/v1")
+	{
+		v1.POST("/media/upload", handler.Upload)
+		v1.GET("/media/list", handler.Fetch)
+		v1.GET("/media/find/:id", handler.GetByID)
+		v1.DELETE("/media/delete/:id", handler.Delete)
+	}
+}
+
+// Upload nhận multipart/form-data với field "file"
+func (h *MediaHandler) Upload(c *gin.Context) {
+	// Chặn body quá lớn ngay ở tầng HTTP, UseCase vẫn kiểm tra lại dung lượng tệp
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.MaxUploadSize+multipartOverhead)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": domain.ErrMediaTooLarge.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing file field"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	media, err := h.MediaUseCase.Upload(c.Request.Context(), fileHeader.Filename, file)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, media)
+}
+
+func (h *MediaHandler) Fetch(c *gin.Context) {
+	page, _ := strconv.ParseInt(c.DefaultQuery("page", "1"), 10, 64)
+	pageSize, _ := strconv.ParseInt(c.DefaultQuery("page_size", "10"), 10, 64)
+
+	items, err := h.MediaUseCase.Fetch(c.Request.Context(), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": items})
+}
+
+func (h *MediaHandler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	media, err := h.MediaUseCase.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, media)
+}
+
+func (h *MediaHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	err = h.MediaUseCase.Delete(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Media deleted successfully"})
+}
@@ -0,0 +1,249 @@
+package http
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"Test2/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("Test2/internal/delivery/http")
+
+const (
+	// HeaderActor do gateway/reverse proxy gán sau khi xác thực người dùng, server không tự xác thực header này
+	// nên chỉ nhận nó từ proxy tin cậy (http.trusted_proxies)
+	HeaderActor     = "X-Actor"
+	HeaderRequestID = "X-Request-ID"
+
+	maxRequestIDLength = 64
+	maxActorLength     = 255
+)
+
+// newRequestID sinh id ngẫu nhiên 128 bit dạng hex
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// validRequestID chỉ nhận chữ, số và . _ : - để request ID từ client không làm hỏng log hay header
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', strings.ContainsRune("._:-", r):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// parseTrustedProxies chuyển danh sách IP/CIDR (đã được kiểm tra khi nạp cấu hình) sang prefix, mục sai bị bỏ qua
+func parseTrustedProxies(proxies []string) []netip.Prefix {
+	prefixes := make([]netip.Prefix, 0, len(proxies))
+	for _, p := range proxies {
+		if prefix, err := netip.ParsePrefix(p); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+		} else if addr, err := netip.ParseAddr(p); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+		}
+	}
+	return prefixes
+}
+
+// fromTrustedProxy kiểm tra kết nối (RemoteAddr, không phải X-Forwarded-For) đến từ một proxy tin cậy
+func fromTrustedProxy(r *http.Request, proxies []netip.Prefix) bool {
+	if len(proxies) == 0 {
+		return false
+	}
+	addrPort, err := netip.ParseAddrPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	addr := addrPort.Addr().Unmap()
+	for _, p := range proxies {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// RequestMeta gắn actor, request ID và IP của client vào context của request.
+// Request ID nhận từ header X-Request-ID (nếu hợp lệ) hoặc được sinh mới, và luôn được trả lại trong response.
+// X-Actor chỉ được nhận khi request đến từ trustedProxies, các request khác có actor "anonymous".
+// Middleware này phải đứng đầu để access log và mọi bản ghi log của request mang request_id.
+func RequestMeta(trustedProxies []string) gin.HandlerFunc {
+	proxies := parseTrustedProxies(trustedProxies)
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(HeaderRequestID)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+
+		actor := ""
+		if fromTrustedProxy(c.Request, proxies) {
+			actor = c.GetHeader(HeaderActor)
+		}
+		if actor == "" {
+			actor = domain.AuditActorAnonymous
+		} else if len(actor) > maxActorLength {
+			actor = actor[:maxActorLength]
+		}
+
+		meta := domain.RequestMeta{
+			Actor:     actor,
+			RequestID: requestID,
+			IP:        c.ClientIP(),
+		}
+		c.Request = c.Request.WithContext(domain.WithRequestMeta(c.Request.Context(), meta))
+		c.Header(HeaderRequestID, requestID)
+		c.Next()
+	}
+}
+
+// Tracing mở span server cho mỗi request, nối vào trace của nơi gọi khi request mang header traceparent (W3C).
+// Span của UseCase, MySQL và Redis là con của span này qua context của request.
+func Tracing() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		route := c.FullPath() // Rỗng khi không khớp route nào (404)
+		name := c.Request.Method
+		if route != "" {
+			name += " " + route
+		}
+		attrs := []attribute.KeyValue{
+			semconv.HTTPRequestMethodKey.String(c.Request.Method),
+			semconv.HTTPRoute(route),
+			semconv.URLPath(c.Request.URL.Path),
+			semconv.ClientAddress(c.ClientIP()),
+			semconv.UserAgentOriginal(c.Request.UserAgent()),
+		}
+		if meta, ok := domain.RequestMetaFromContext(ctx); ok {
+			attrs = append(attrs, attribute.String("request.id", meta.RequestID))
+		}
+		ctx, span := tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(attrs...))
+		defer span.End()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+		if len(c.Errors) > 0 {
+			span.RecordError(c.Errors.Last().Err)
+		}
+	}
+}
+
+// accessLogQuiet là các route probe/scrape được log ở mức debug thay vì info để không làm ngập access log
+var accessLogQuiet = map[string]bool{
+	"/healthz": true,
+	"/readyz":  true,
+	"/metrics": true,
+}
+
+// AccessLog ghi mỗi request thành một bản ghi: route template, status, thời gian xử lý, kích thước response
+// và kết quả tra cache (hit, miss hoặc partial). request_id được logger lấy từ context do RequestMeta gắn.
+func AccessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		ctx, cache := domain.WithCacheStats(c.Request.Context())
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		route := c.FullPath() // Rỗng khi không khớp route nào (404)
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("route", route),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("bytes", max(c.Writer.Size(), 0)),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if result := cache.Result(); result != "" {
+			attrs = append(attrs, slog.String("cache", result))
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case accessLogQuiet[route]:
+			level = slog.LevelDebug
+		}
+		slog.LogAttrs(c.Request.Context(), level, "http request", attrs...)
+	}
+}
+
+// Recovery trả về 500 khi handler panic và ghi panic cùng stack trace qua slog thay cho logger mặc định của gin
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recovered any) {
+		slog.ErrorContext(c.Request.Context(), "panic recovered", "panic", fmt.Sprint(recovered), "stack", string(debug.Stack()))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	})
+}
+
+// CookieReadPrimary đánh dấu client vừa ghi dữ liệu, request tiếp theo của client đọc từ MySQL primary
+const CookieReadPrimary = "cms_read_primary"
+
+// ReadYourWrites chọn nguồn đọc cho request khi có MySQL replica. Request ghi (POST, PUT, PATCH, DELETE)
+// đọc từ primary và đặt cookie để các request của client trong stickyWindow tiếp theo cũng đọc từ primary,
+// tránh thấy dữ liệu cũ trên replica chưa nhận thay đổi. Request đọc khác chuyển sang primary khi có ghi.
+func ReadYourWrites(stickyWindow time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		primary := true
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			_, err := c.Cookie(CookieReadPrimary)
+			primary = err == nil
+		default:
+			if stickyWindow > 0 {
+				maxAge := int((stickyWindow + time.Second - 1) / time.Second)
+				c.SetSameSite(http.SameSiteLaxMode)
+				c.SetCookie(CookieReadPrimary, "1", maxAge, "/", "", false, true)
+			}
+		}
+		c.Request = c.Request.WithContext(domain.WithReadYourWrites(c.Request.Context(), primary))
+		c.Next()
+	}
+}
+
+// LongRunning bỏ read/write timeout của server cho route stream hoặc nhận/trả tệp lớn (SSE, export, import),
+// các route còn lại vẫn bị giới hạn bởi timeout cấu hình trên http.Server
+func LongRunning() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rc := http.NewResponseController(c.Writer)
+		_ = rc.SetReadDeadline(time.Time{})
+		_ = rc.SetWriteDeadline(time.Time{})
+		c.Next()
+	}
+}
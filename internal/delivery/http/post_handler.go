@@ -28,7 +28,16 @@ func NewPostHandler(r *gin.Engine, us domain.PostUseCase) {
 		v1.GET("/posts/find/:id", handler.GetByID)
 		v1.PUT("/posts/update/:id", handler.Update)
 		v1.DELETE("/posts/delete/:id", handler.Delete)
+		v1.GET("/posts/trash", handler.FetchTrash)
+		v1.PUT("/posts/restore/:id", handler.Restore)
+		v1.DELETE("/posts/purge/:id", handler.Purge)
 		v1.GET("/posts/search/:keyword", handler.Search)
+
+		// Thao tác hàng loạt, chạy trong một transaction và trả về kết quả từng phần tử
+		v1.POST("/posts/batch/add", handler.StoreBatch)
+		v1.PUT("/posts/batch/status", handler.UpdateStatusBatch)
+		v1.POST("/posts/batch/delete", handler.DeleteBatch)
+		v1.PUT("/posts/batch/restore", handler.RestoreBatch)
 	}
 }
 
@@ -44,7 +53,7 @@ func (h *PostHandler) Store(c *gin.Context) {
 	ctx := c.Request.Context()
 	err := h.PostUseCase.Store(ctx, &post)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(inputErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -98,7 +107,7 @@ func (h *PostHandler) Update(c *gin.Context) {
 
 	err = h.PostUseCase.Update(c.Request.Context(), &post)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(inputErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -136,3 +145,112 @@ func (h *PostHandler) Search(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, gin.H{"data": posts})
 }
+
+// List soft-deleted items (trash)
+func (h *PostHandler) FetchTrash(c *gin.Context) {
+	page, _ := strconv.ParseInt(c.DefaultQuery("page", "1"), 10, 64)
+	pageSize, _ := strconv.ParseInt(c.DefaultQuery("page_size", "10"), 10, 64)
+
+	posts, err := h.PostUseCase.FetchTrash(c.Request.Context(), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": posts})
+}
+
+// Restore from trash to the pre-delete status
+func (h *PostHandler) Restore(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	err = h.PostUseCase.Restore(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Post restored successfully"})
+}
+
+// Permanently delete an item that is already in the trash
+func (h *PostHandler) Purge(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	err = h.PostUseCase.Purge(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Post permanently deleted"})
+}
+
+// Create many posts at once
+func (h *PostHandler) StoreBatch(c *gin.Context) {
+	var req batchPostsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.PostUseCase.StoreBatch(c.Request.Context(), req.Items)
+	if err != nil {
+		c.JSON(inputErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
+
+// Change the status of many posts (publish, unpublish, ...)
+func (h *PostHandler) UpdateStatusBatch(c *gin.Context) {
+	var req batchStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.PostUseCase.UpdateStatusBatch(c.Request.Context(), req.IDs, req.Status)
+	if err != nil {
+		c.JSON(inputErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
+
+// Soft delete many posts
+func (h *PostHandler) DeleteBatch(c *gin.Context) {
+	var req batchIDsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.PostUseCase.DeleteBatch(c.Request.Context(), req.IDs)
+	if err != nil {
+		c.JSON(inputErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
+
+// Restore many posts from the trash
+func (h *PostHandler) RestoreBatch(c *gin.Context) {
+	var req batchIDsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.PostUseCase.RestoreBatch(c.Request.Context(), req.IDs)
+	if err != nil {
+		c.JSON(inputErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
@@ -0,0 +1,136 @@
+package http
+
+import (
+	"math"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rateLimitSweepInterval là chu kỳ dọn bucket của các client không còn gửi request
+const rateLimitSweepInterval = time.Minute
+
+// maxRateLimitBuckets chặn bộ nhớ của RateLimiter khi có rất nhiều IP trong một chu kỳ dọn
+const maxRateLimitBuckets = 100000
+
+// rateLimitExempt là các route không bị giới hạn để probe và Prometheus không bị chặn
+var rateLimitExempt = map[string]bool{
+	"/healthz": true,
+	"/readyz":  true,
+	"/metrics": true,
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// RateLimiter giới hạn request theo IP client bằng token bucket trong bộ nhớ của instance,
+// giới hạn đổi được lúc chạy qua SetLimit
+type RateLimiter struct {
+	mu        sync.Mutex
+	rps       float64
+	burst     float64
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// NewRateLimiter: rps = 0 tắt giới hạn
+func NewRateLimiter(rps float64, burst int) *RateLimiter {
+	l := &RateLimiter{buckets: make(map[string]*tokenBucket)}
+	l.SetLimit(rps, burst)
+	return l
+}
+
+// SetLimit đổi giới hạn, bucket hiện có giữ số token còn lại (không vượt burst mới)
+func (l *RateLimiter) SetLimit(rps float64, burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.rps = rps
+	l.burst = float64(max(burst, 1))
+	for _, b := range l.buckets {
+		b.tokens = math.Min(b.tokens, l.burst)
+	}
+}
+
+// allow lấy một token của key, trả về thời gian cần chờ khi hết token
+func (l *RateLimiter) allow(key string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.rps <= 0 {
+		return true, 0
+	}
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= maxRateLimitBuckets {
+			// Bỏ một bucket bất kỳ: client đó chỉ được cấp lại burst, còn bộ nhớ luôn bị chặn
+			for k := range l.buckets {
+				delete(l.buckets, k)
+				break
+			}
+		}
+		b = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rps)
+	b.last = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / l.rps * float64(time.Second))
+		return false, wait
+	}
+	b.tokens--
+	return true, 0
+}
+
+// sweep xóa bucket đã rảnh đủ lâu để đầy lại, tương đương với bucket mới
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < rateLimitSweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	idle := time.Duration(l.burst / l.rps * float64(time.Second))
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= idle {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// rateLimitKey gộp địa chỉ IPv6 theo /64 vì một client thường được cấp cả dải /64,
+// nếu không mỗi địa chỉ trong dải là một bucket mới
+func rateLimitKey(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil || !addr.Unmap().Is6() {
+		return ip
+	}
+	prefix, _ := addr.WithZone("").Prefix(64)
+	return prefix.String()
+}
+
+// RateLimit trả về 429 kèm Retry-After khi client vượt giới hạn
+func RateLimit(l *RateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if rateLimitExempt[c.Request.URL.Path] {
+			c.Next()
+			return
+		}
+
+		ok, wait := l.allow(rateLimitKey(c.ClientIP()), time.Now())
+		if !ok {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests"})
+			return
+		}
+		c.Next()
+	}
+}
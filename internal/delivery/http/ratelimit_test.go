@@ -0,0 +1,99 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRateLimitKey(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want string
+	}{
+		{"203.0.113.7", "203.0.113.7"},
+		{"::ffff:203.0.113.7", "::ffff:203.0.113.7"},
+		{"2001:db8:1:2:aaaa::1", "2001:db8:1:2::/64"},
+		{"2001:db8:1:2:bbbb::2", "2001:db8:1:2::/64"},
+		{"fe80::1%eth0", "fe80::/64"},
+		{"not-an-ip", "not-an-ip"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			if got := rateLimitKey(tt.ip); got != tt.want {
+				t.Errorf("rateLimitKey(%q) = %q, want %q", tt.ip, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimiterBucketsAreBounded(t *testing.T) {
+	l := NewRateLimiter(1, 1)
+	now := time.Now()
+	for i := range maxRateLimitBuckets + 10 {
+		l.allow(fmt.Sprintf("key-%d", i), now)
+	}
+	if n := len(l.buckets); n > maxRateLimitBuckets {
+		t.Errorf("len(buckets) = %d, want <= %d", n, maxRateLimitBuckets)
+	}
+}
+
+func TestRateLimitClientIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		trusted    []string
+		remoteAddr []string // mỗi request đến từ một địa chỉ kết nối
+		forwarded  []string // X-Forwarded-For tương ứng
+		wantLast   int
+	}{
+		{
+			name:       "forwarded header from untrusted client is ignored",
+			remoteAddr: []string{"198.51.100.1:1000", "198.51.100.1:1001"},
+			forwarded:  []string{"203.0.113.1", "203.0.113.2"},
+			wantLast:   http.StatusTooManyRequests,
+		},
+		{
+			name:       "forwarded header from trusted proxy selects the client",
+			trusted:    []string{"10.0.0.0/8"},
+			remoteAddr: []string{"10.0.0.2:1000", "10.0.0.2:1001"},
+			forwarded:  []string{"203.0.113.1", "203.0.113.2"},
+			wantLast:   http.StatusOK,
+		},
+		{
+			name:       "same client behind trusted proxy is limited",
+			trusted:    []string{"10.0.0.0/8"},
+			remoteAddr: []string{"10.0.0.2:1000", "10.0.0.3:1001"},
+			forwarded:  []string{"203.0.113.1", "203.0.113.1"},
+			wantLast:   http.StatusTooManyRequests,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			if err := r.SetTrustedProxies(tt.trusted); err != nil {
+				t.Fatal(err)
+			}
+			r.Use(RateLimit(NewRateLimiter(0.001, 1)))
+			r.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			var code int
+			for i := range tt.remoteAddr {
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				req.RemoteAddr = tt.remoteAddr[i]
+				req.Header.Set("X-Forwarded-For", tt.forwarded[i])
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, req)
+				code = w.Code
+			}
+			if code != tt.wantLast {
+				t.Errorf("last status = %d, want %d", code, tt.wantLast)
+			}
+		})
+	}
+}
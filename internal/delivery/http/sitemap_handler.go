@@ -0,0 +1,58 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"Test2/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SitemapHandler phục vụ sitemap index và các sitemap con cho search engine
+type SitemapHandler struct {
+	SitemapUseCase domain.SitemapUseCase
+}
+
+// NewSitemapHandler khởi tạo Handler và đăng ký routes ở gốc site
+func NewSitemapHandler(r *gin.Engine, us domain.SitemapUseCase) {
+	handler := &SitemapHandler{
+		SitemapUseCase: us,
+	}
+
+	r.GET("/sitemap.xml", handler.Index)
+	r.GET("/sitemaps/:kind/:page", handler.Page)
+}
+
+func (h *SitemapHandler) Index(c *gin.Context) {
+	body, err := h.SitemapUseCase.Index(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", body)
+}
+
+// Page phục vụ /sitemaps/posts/1.xml, /sitemaps/categories/1.xml
+func (h *SitemapHandler) Page(c *gin.Context) {
+	kind := c.Param("kind")
+	if kind != domain.SitemapKindPosts && kind != domain.SitemapKindCategories {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown sitemap"})
+		return
+	}
+
+	raw, ok := strings.CutSuffix(c.Param("page"), ".xml")
+	page, err := strconv.ParseInt(raw, 10, 64)
+	if !ok || err != nil || page <= 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invalid sitemap page"})
+		return
+	}
+
+	body, err := h.SitemapUseCase.Page(c.Request.Context(), kind, page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", body)
+}
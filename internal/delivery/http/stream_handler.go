@@ -0,0 +1,118 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"Test2/internal/domain"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+// StreamHandler đẩy thông báo thay đổi của Post/Category tới client qua Server-Sent Events
+type StreamHandler struct {
+	StreamUseCase domain.StreamUseCase
+	heartbeat     time.Duration
+}
+
+// NewStreamHandler khởi tạo Handler và đăng ký routes, heartbeat giữ kết nối qua proxy khi không có thay đổi
+func NewStreamHandler(r *gin.Engine, us domain.StreamUseCase, heartbeat time.Duration) {
+	handler := &StreamHandler{
+		StreamUseCase: us,
+		heartbeat:     heartbeat,
+	}
+
+	v1 := r.Group("/api/v1")
+	{
+		v1.GET("/stream", LongRunning(), handler.Stream)
+	}
+}
+
+// splitQuery tách tham số dạng "a,b" thành danh sách, bỏ phần tử rỗng
+func splitQuery(raw string) []string {
+	var values []string
+	for _, v := range strings.Split(raw, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// Stream: ?entity=post,category&status=Published,Draft, resume bằng header Last-Event-ID (hoặc ?last_event_id=)
+func (h *StreamHandler) Stream(c *gin.Context) {
+	filter := domain.ChangeFilter{
+		Entities: splitQuery(c.Query("entity")),
+		Statuses: splitQuery(c.Query("status")),
+	}
+	for _, e := range filter.Entities {
+		if e != domain.AggregatePost && e != domain.AggregateCategory {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid entity, expected post or category"})
+			return
+		}
+	}
+
+	var lastID int64
+	raw := c.GetHeader("Last-Event-ID")
+	if raw == "" {
+		raw = c.Query("last_event_id")
+	}
+	if raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Last-Event-ID"})
+			return
+		}
+		lastID = id
+	}
+
+	ctx := c.Request.Context()
+	backlog, live, err := h.StreamUseCase.Subscribe(ctx, filter, lastID)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // Tắt buffer của nginx
+	c.Status(http.StatusOK)
+
+	send := func(n domain.ChangeNotification) {
+		// Thông báo có thể đến lại qua live sau khi đã gửi từ backlog
+		if n.ID <= lastID {
+			return
+		}
+		c.Render(-1, sse.Event{Id: strconv.FormatInt(n.ID, 10), Data: n})
+		lastID = n.ID
+	}
+
+	for _, n := range backlog {
+		send(n)
+	}
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case n, ok := <-live:
+			if !ok {
+				// Bị ngắt vì đọc chậm hoặc server đang tắt, client tự kết nối lại với Last-Event-ID
+				return
+			}
+			send(n)
+			c.Writer.Flush()
+		case <-ticker.C:
+			_, _ = c.Writer.WriteString(": ping\n\n")
+			c.Writer.Flush()
+		}
+	}
+}
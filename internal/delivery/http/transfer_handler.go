@@ -0,0 +1,128 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"Test2/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TransferHandler phục vụ export/import bài viết (backup, chuyển dữ liệu giữa các môi trường)
+type TransferHandler struct {
+	PostUseCase domain.PostUseCase
+	WXRUseCase  domain.WXRImportUseCase
+}
+
+// NewTransferHandler khởi tạo Handler và đăng ký routes
+func NewTransferHandler(r *gin.Engine, us domain.PostUseCase, wxr domain.WXRImportUseCase) {
+	handler := &TransferHandler{
+		PostUseCase: us,
+		WXRUseCase:  wxr,
+	}
+
+	admin := r.Group("/api/v1/admin")
+	{
+		admin.GET("/export/posts", LongRunning(), handler.ExportPosts)
+		admin.POST("/import/posts", LongRunning(), handler.ImportPosts)
+		admin.POST("/import/wxr", LongRunning(), handler.ImportWXR)
+	}
+}
+
+var transferContentTypes = map[string]string{
+	domain.TransferFormatJSONL: "application/x-ndjson",
+	domain.TransferFormatCSV:   "text/csv; charset=utf-8",
+}
+
+// ExportPosts stream toàn bộ bài viết: ?format=jsonl|csv&include_deleted=true
+func (h *TransferHandler) ExportPosts(c *gin.Context) {
+	format := c.DefaultQuery("format", domain.TransferFormatJSONL)
+	contentType, ok := transferContentTypes[format]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidTransferFormat.Error()})
+		return
+	}
+	includeDeleted, _ := strconv.ParseBool(c.DefaultQuery("include_deleted", "false"))
+
+	fileName := "posts-" + time.Now().Format("20060102-150405") + "." + format
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", `attachment; filename="`+fileName+`"`)
+	c.Status(http.StatusOK)
+
+	// Header đã được gửi nên lỗi giữa chừng chỉ có thể ghi log, client nhận file bị cắt ngang
+	if err := h.PostUseCase.Export(c.Request.Context(), c.Writer, format, includeDeleted); err != nil {
+		slog.ErrorContext(c.Request.Context(), "export: posts aborted", "format", format, "error", err)
+		_ = c.Error(err)
+		c.Abort()
+	}
+}
+
+// uploadedBody trả về file trong multipart field "file", hoặc body của request nếu không phải multipart
+func uploadedBody(c *gin.Context) (io.Reader, func(), bool) {
+	if !strings.HasPrefix(c.ContentType(), "multipart/") {
+		return c.Request.Body, func() {}, true
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing file field"})
+		return nil, nil, false
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, nil, false
+	}
+	return file, func() { file.Close() }, true
+}
+
+// ImportPosts nhận file qua multipart field "file" hoặc trực tiếp trong body: ?format=jsonl|csv&dry_run=true
+func (h *TransferHandler) ImportPosts(c *gin.Context) {
+	format := c.DefaultQuery("format", domain.TransferFormatJSONL)
+	dryRun, _ := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+
+	body, closeBody, ok := uploadedBody(c)
+	if !ok {
+		return
+	}
+	defer closeBody()
+
+	report, err := h.PostUseCase.Import(c.Request.Context(), body, format, dryRun)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidTransferFormat) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		// Các lô trước đó có thể đã được ghi, trả kèm báo cáo để client biết dừng ở đâu
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "data": report})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": report})
+}
+
+// ImportWXR nhận file export của WordPress (Tools > Export), chạy lại cùng file không tạo bản ghi trùng
+func (h *TransferHandler) ImportWXR(c *gin.Context) {
+	body, closeBody, ok := uploadedBody(c)
+	if !ok {
+		return
+	}
+	defer closeBody()
+
+	summary, err := h.WXRUseCase.Import(c.Request.Context(), body)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, domain.ErrInvalidWXR) {
+			status = http.StatusBadRequest
+		}
+		// Phần đã import trước khi gặp lỗi vẫn được giữ, trả kèm tổng kết
+		c.JSON(status, gin.H{"error": err.Error(), "data": summary})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": summary})
+}
@@ -0,0 +1,205 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"Test2/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookHandler quản lý đăng ký webhook và delivery log
+type WebhookHandler struct {
+	WebhookUseCase domain.WebhookUseCase
+}
+
+// NewWebhookHandler khởi tạo Handler và đăng ký routes
+func NewWebhookHandler(r *gin.Engine, us domain.WebhookUseCase) {
+	handler := &WebhookHandler{
+		WebhookUseCase: us,
+	}
+
+	v1 := r.Group("/api/v1")
+	{
+		v1.POST("/webhooks/add", handler.Store)
+		v1.GET("/webhooks/list", handler.Fetch)
+		v1.GET("/webhooks/find/:id", handler.GetByID)
+		v1.PUT("/webhooks/update/:id", handler.Update)
+		v1.DELETE("/webhooks/delete/:id", handler.Delete)
+		v1.POST("/webhooks/test/:id", handler.Test)
+
+		// Delivery log: lọc theo webhook/trạng thái, xem chi tiết và gửi lại
+		v1.GET("/webhooks/deliveries/list", handler.FetchDeliveries)
+		v1.GET("/webhooks/deliveries/find/:id", handler.GetDelivery)
+		v1.POST("/webhooks/deliveries/replay/:id", handler.Replay)
+	}
+}
+
+// webhookRequest là body của add/update. Secret rỗng: tự sinh khi tạo, giữ nguyên khi cập nhật.
+// Active bỏ trống khi cập nhật nghĩa là giữ nguyên trạng thái hiện tại.
+type webhookRequest struct {
+	URL    string   `json:"url" binding:"required"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret"`
+	Active *bool    `json:"active"`
+}
+
+func (h *WebhookHandler) Store(c *gin.Context) {
+	var req webhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	w := domain.Webhook{URL: req.URL, Events: req.Events, Secret: req.Secret}
+	if err := h.WebhookUseCase.Store(c.Request.Context(), &w); err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, w)
+}
+
+func (h *WebhookHandler) Fetch(c *gin.Context) {
+	page, _ := strconv.ParseInt(c.DefaultQuery("page", "1"), 10, 64)
+	pageSize, _ := strconv.ParseInt(c.DefaultQuery("page_size", "10"), 10, 64)
+
+	hooks, err := h.WebhookUseCase.Fetch(c.Request.Context(), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": hooks})
+}
+
+func (h *WebhookHandler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	w, err := h.WebhookUseCase.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, w)
+}
+
+func (h *WebhookHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID to Update"})
+		return
+	}
+
+	var req webhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	w := domain.Webhook{ID: id, URL: req.URL, Events: req.Events, Secret: req.Secret}
+	if req.Active != nil {
+		w.Active = *req.Active
+	} else {
+		existing, err := h.WebhookUseCase.GetByID(ctx, id)
+		if err != nil {
+			c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+			return
+		}
+		w.Active = existing.Active
+	}
+
+	if err := h.WebhookUseCase.Update(ctx, &w); err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": w})
+}
+
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	if err := h.WebhookUseCase.Delete(c.Request.Context(), id); err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted successfully"})
+}
+
+// Send a Ping event right away and return the logged delivery
+func (h *WebhookHandler) Test(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	delivery, err := h.WebhookUseCase.Test(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": delivery})
+}
+
+// FetchDeliveries: ?webhook_id=&status=pending|succeeded|failed&page=&page_size=
+func (h *WebhookHandler) FetchDeliveries(c *gin.Context) {
+	page, _ := strconv.ParseInt(c.DefaultQuery("page", "1"), 10, 64)
+	pageSize, _ := strconv.ParseInt(c.DefaultQuery("page_size", "10"), 10, 64)
+
+	filter := domain.WebhookDeliveryFilter{Status: c.Query("status")}
+	if raw := c.Query("webhook_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook_id"})
+			return
+		}
+		filter.WebhookID = id
+	}
+
+	deliveries, err := h.WebhookUseCase.FetchDeliveries(c.Request.Context(), filter, page, pageSize)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": deliveries})
+}
+
+func (h *WebhookHandler) GetDelivery(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	delivery, err := h.WebhookUseCase.GetDelivery(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, delivery)
+}
+
+// Queue a copy of a delivery with the same payload
+func (h *WebhookHandler) Replay(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	delivery, err := h.WebhookUseCase.Replay(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"data": delivery})
+}
@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// --- ENUMS & CONSTANTS ---
+const (
+	AuditEntityPost     = "post"
+	AuditEntityCategory = "category"
+
+	// AuditActorSystem là actor của thao tác không xuất phát từ request HTTP (retention job, ...)
+	AuditActorSystem = "system"
+	// AuditActorAnonymous là actor của request không mang header định danh
+	AuditActorAnonymous = "anonymous"
+)
+
+// --- ENTITIES ---
+
+// RequestMeta là thông tin về người gọi được gắn vào context ở tầng Delivery
+type RequestMeta struct {
+	Actor     string
+	RequestID string
+	IP        string
+}
+
+type requestMetaKey struct{}
+
+// WithRequestMeta gắn RequestMeta vào context, UseCase và hook đọc lại qua RequestMetaFromContext
+func WithRequestMeta(ctx context.Context, meta RequestMeta) context.Context {
+	return context.WithValue(ctx, requestMetaKey{}, meta)
+}
+
+// RequestMetaFromContext trả về RequestMeta của context, ok = false khi không có
+func RequestMetaFromContext(ctx context.Context) (RequestMeta, bool) {
+	meta, ok := ctx.Value(requestMetaKey{}).(RequestMeta)
+	return meta, ok
+}
+
+// AuditEntry là một bản ghi trong bảng `audit_log` (chỉ thêm, không sửa/xóa).
+// Before là nil khi tạo mới, After là nil khi xóa (vào thùng rác hoặc vĩnh viễn).
+type AuditEntry struct {
+	ID         int64           `json:"id"`
+	Actor      string          `json:"actor"`
+	RequestID  string          `json:"request_id"`
+	IP         string          `json:"ip"`
+	EntityType string          `json:"entity_type"`
+	EntityID   int64           `json:"entity_id"`
+	Action     string          `json:"action"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// AuditFilter lọc audit log, trường rỗng/nil nghĩa là không lọc theo trường đó
+type AuditFilter struct {
+	EntityType string
+	EntityID   int64
+	Actor      string
+	From       *time.Time // Bao gồm
+	To         *time.Time // Không bao gồm
+}
+
+// --- INTERFACES (PORTS) ---
+
+type AuditRepository interface {
+	// Store ghi nhiều bản ghi trong một câu INSERT
+	Store(ctx context.Context, entries []AuditEntry) error
+	// Fetch trả về các bản ghi mới nhất trước
+	Fetch(ctx context.Context, filter AuditFilter, limit int64, offset int64) ([]AuditEntry, error)
+}
+
+// AuditUseCase ghi lại mọi thao tác ghi lên Post/Category. Post/Category UseCase gọi Record* với ctx
+// mang transaction của thao tác ghi, audit log được commit hoặc rollback cùng thay đổi (như outbox).
+type AuditUseCase interface {
+	RecordPosts(ctx context.Context, action string, changes []PostChange) error
+	RecordCategories(ctx context.Context, action string, changes []CategoryChange) error
+	Fetch(ctx context.Context, filter AuditFilter, page int64, pageSize int64) ([]AuditEntry, error)
+}
@@ -0,0 +1,30 @@
+package domain
+
+// --- ENUMS & CONSTANTS ---
+
+// BatchMaxItems giới hạn số phần tử của một thao tác hàng loạt để transaction không giữ khóa quá lâu
+const BatchMaxItems = 100
+
+// Trạng thái của từng phần tử trong kết quả thao tác hàng loạt
+const (
+	BatchItemOK     = "ok"
+	BatchItemFailed = "failed"
+)
+
+// --- ENTITIES ---
+
+// BatchItemResult là kết quả của một phần tử, Index là vị trí của phần tử trong request
+type BatchItemResult struct {
+	Index  int    `json:"index"`
+	ID     int64  `json:"id,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// BatchResult tổng hợp kết quả thao tác hàng loạt. Các phần tử hợp lệ được ghi trong cùng
+// một transaction, phần tử không hợp lệ bị bỏ qua và báo lỗi riêng.
+type BatchResult struct {
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	Items     []BatchItemResult `json:"items"`
+}
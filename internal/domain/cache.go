@@ -2,11 +2,60 @@ package domain
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 )
 
 type CacheRepository interface {
 	Get(ctx context.Context, key string) ([]Post, bool)
 	Set(ctx context.Context, key string, value []Post, ttl time.Duration) error
-	Delete(ctx context.Context, key string) error // Bổ sung cơ chế Invalidation
+	Delete(ctx context.Context, keys ...string) error // Bổ sung cơ chế Invalidation
+}
+
+// RawCacheRepository lưu dữ liệu dạng byte đã được xử lý sẵn (HTML đã render, ...)
+type RawCacheRepository interface {
+	Get(ctx context.Context, key string) ([]byte, bool)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// CacheStats đếm lượt tra cache của một request, tầng Delivery ghi kết quả vào access log
+type CacheStats struct {
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+type cacheStatsKey struct{}
+
+// WithCacheStats gắn bộ đếm vào context, repository cache ghi nhận mỗi lần tra qua RecordCacheLookup
+func WithCacheStats(ctx context.Context) (context.Context, *CacheStats) {
+	stats := &CacheStats{}
+	return context.WithValue(ctx, cacheStatsKey{}, stats), stats
+}
+
+// RecordCacheLookup ghi nhận một lần tra cache, không làm gì khi context không mang bộ đếm
+func RecordCacheLookup(ctx context.Context, hit bool) {
+	stats, ok := ctx.Value(cacheStatsKey{}).(*CacheStats)
+	if !ok {
+		return
+	}
+	if hit {
+		stats.hits.Add(1)
+	} else {
+		stats.misses.Add(1)
+	}
+}
+
+// Result là "hit" hoặc "miss" khi mọi lần tra cùng kết quả, "partial" khi lẫn lộn và rỗng khi request không tra cache
+func (s *CacheStats) Result() string {
+	hits, misses := s.hits.Load(), s.misses.Load()
+	switch {
+	case hits > 0 && misses > 0:
+		return "partial"
+	case hits > 0:
+		return "hit"
+	case misses > 0:
+		return "miss"
+	}
+	return ""
 }
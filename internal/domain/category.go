@@ -11,6 +11,20 @@ const (
 	CategoryStatusInactive = "Inactive"
 )
 
+// Các kiểu sắp xếp hợp lệ cho danh sách danh mục
+const (
+	CategorySortNewest   = "newest"   // created_at DESC (mặc định)
+	CategorySortOldest   = "oldest"   // created_at ASC
+	CategorySortTitle    = "title"    // title ASC
+	CategorySortPosition = "position" // Thứ tự thủ công do biên tập viên sắp xếp
+)
+
+// Quy tắc xử lý danh mục con khi di chuyển hoặc xóa danh mục cha
+const (
+	CategoryChildPolicyReparent = "reparent" // Đưa danh mục con lên danh mục cha cũ (ông)
+	CategoryChildPolicyBlock    = "block"    // Từ chối thao tác khi còn danh mục con
+)
+
 // --- ENTITIES ---
 
 // Category đại diện cho danh mục trong hệ thống
@@ -20,9 +34,23 @@ type Category struct {
 	Title       string    `json:"title"`
 	Description string    `json:"description"`
 	Thumbnail   string    `json:"thumbnail"`
+	MediaID     *int64    `json:"media_id"`
+	Media       *Media    `json:"media,omitempty"` // Được gắn khi đọc, không lưu trong bảng categories
+	ParentID    *int64    `json:"parent_id"`       // nil: danh mục gốc
+	Position    int       `json:"position"`        // Thứ tự trong cùng một danh mục cha
 	Status      string    `json:"status"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Thông tin thùng rác, chỉ có giá trị khi danh mục đã bị xóa mềm
+	DeletedAt      *time.Time `json:"deleted_at,omitempty"`
+	PreviousStatus string     `json:"previous_status,omitempty"`
+}
+
+// CategoryNode là một nút trong cây danh mục
+type CategoryNode struct {
+	Category
+	Children []CategoryNode `json:"children"`
 }
 
 // --- INTERFACES (PORTS) ---
@@ -31,17 +59,74 @@ type Category struct {
 // Lớp Repository (MySQL) sẽ phải implement interface này.
 
 type CategoryRepository interface {
-	Fetch(ctx context.Context, limit int64, offset int64) ([]Category, error)
+	Fetch(ctx context.Context, limit int64, offset int64, sort string) ([]Category, error)
 	GetByID(ctx context.Context, id int64) (*Category, error)
+	// GetByIDForUpdate đọc danh mục chưa bị tắt và khóa dòng (SELECT ... FOR UPDATE) tới hết transaction trong ctx
+	GetByIDForUpdate(ctx context.Context, id int64) (*Category, error)
 	Store(ctx context.Context, c *Category) error
 	Update(ctx context.Context, c *Category) error
 	Delete(ctx context.Context, id int64) error
+	// FetchAll lấy toàn bộ danh mục Active (dùng để dựng cây)
+	FetchAll(ctx context.Context) ([]Category, error)
+	// FetchChildren lấy các danh mục con trực tiếp đang Active theo position (parentID nil: danh mục gốc)
+	FetchChildren(ctx context.Context, parentID *int64) ([]Category, error)
+	// NextPosition trả về position kế tiếp ở cuối một danh mục cha
+	NextPosition(ctx context.Context, parentID *int64) (int, error)
+	// Reorder gán position theo thứ tự ids trong một transaction
+	Reorder(ctx context.Context, parentID *int64, ids []int64) error
+	// ReparentChildren chuyển toàn bộ danh mục con của parentID sang newParentID (nil: thành gốc)
+	ReparentChildren(ctx context.Context, parentID int64, newParentID *int64) error
+	// Thùng rác gồm danh mục Inactive có deleted_at, danh mục chỉ bị tắt (deleted_at NULL) không bao giờ
+	// được liệt kê, khôi phục hay xóa vĩnh viễn qua các hàm dưới đây
+	FetchTrash(ctx context.Context, limit int64, offset int64) ([]Category, error)
+	GetTrashedByID(ctx context.Context, id int64) (*Category, error)
+	FetchTrashedBefore(ctx context.Context, cutoff time.Time, limit int64) ([]Category, error)
+	Restore(ctx context.Context, id int64) error
+	// Purge xóa vĩnh viễn danh mục trong thùng rác, các bài viết thuộc danh mục được gỡ category_id
+	Purge(ctx context.Context, id int64) error
+	// SitemapPages đếm các trang sitemap (chia theo khoảng id) có danh mục Active
+	SitemapPages(ctx context.Context, pageSize int64) ([]SitemapPage, error)
+	// SitemapEntries lấy id và updated_at của các danh mục Active thuộc một trang sitemap
+	SitemapEntries(ctx context.Context, page int64, pageSize int64) ([]SitemapEntry, error)
+	// CountByStatus đếm danh mục theo trạng thái (kể cả trong thùng rác), dùng cho metric nội dung
+	CountByStatus(ctx context.Context) (map[string]int64, error)
+
+	// --- Batch API: mỗi hàm chạy trong một transaction, lỗi ở bất kỳ bản ghi nào sẽ rollback cả lô ---
+
+	// GetByIDs lấy các danh mục Active theo danh sách id (bỏ qua id không tồn tại)
+	GetByIDs(ctx context.Context, ids []int64) ([]Category, error)
+	// GetTrashedByIDs lấy các danh mục đang nằm trong thùng rác theo danh sách id
+	GetTrashedByIDs(ctx context.Context, ids []int64) ([]Category, error)
+	// StoreBatch tạo mới nhiều danh mục và gán ID cho từng phần tử
+	StoreBatch(ctx context.Context, categories []*Category) error
+	// DeleteBatch xóa mềm nhiều danh mục theo thứ tự ids. reparent = true thì danh mục con
+	// của mỗi danh mục bị xóa được chuyển lên danh mục cha hiện tại của nó.
+	DeleteBatch(ctx context.Context, ids []int64, reparent bool) error
+	// RestoreBatch khôi phục nhiều danh mục, parent_id được ghi lại theo giá trị trong từng phần tử
+	RestoreBatch(ctx context.Context, categories []*Category) error
+	// FetchByTitles lấy các danh mục (kể cả đã xóa mềm) theo tên, tên danh mục là duy nhất
+	FetchByTitles(ctx context.Context, titles []string) ([]Category, error)
 }
 
 type CategoryUseCase interface {
-	Fetch(ctx context.Context, page int64, pageSize int64) ([]Category, error)
+	Fetch(ctx context.Context, page int64, pageSize int64, sort string) ([]Category, error)
 	GetByID(ctx context.Context, id int64) (*Category, error)
 	Store(ctx context.Context, c *Category) error
 	Update(ctx context.Context, c *Category) error
 	Delete(ctx context.Context, id int64) error
+	FetchTrash(ctx context.Context, page int64, pageSize int64) ([]Category, error)
+	Restore(ctx context.Context, id int64) error
+	Purge(ctx context.Context, id int64) error
+	PurgeExpired(ctx context.Context, olderThan time.Duration) (int, error)
+	// Tree trả về toàn bộ cây danh mục Active
+	Tree(ctx context.Context) ([]CategoryNode, error)
+	// Breadcrumb trả về đường đi từ danh mục gốc tới danh mục id
+	Breadcrumb(ctx context.Context, id int64) ([]Category, error)
+	// Reorder sắp xếp lại toàn bộ danh mục con của parentID theo thứ tự ids
+	Reorder(ctx context.Context, parentID *int64, ids []int64) error
+
+	// Thao tác hàng loạt: tối đa BatchMaxItems phần tử, báo kết quả theo từng phần tử
+	StoreBatch(ctx context.Context, categories []Category) (*BatchResult, error)
+	DeleteBatch(ctx context.Context, ids []int64) (*BatchResult, error)
+	RestoreBatch(ctx context.Context, ids []int64) (*BatchResult, error)
 }
@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// --- ENTITIES ---
+
+// ConfigStatus mô tả cấu hình đang có hiệu lực và lần reload gần nhất
+type ConfigStatus struct {
+	Version         int64             `json:"version"`          // Tăng sau mỗi lần reload làm thay đổi cấu hình
+	Source          string            `json:"source,omitempty"` // Tệp YAML, rỗng khi chỉ dùng mặc định/biến môi trường/flag
+	LoadedAt        time.Time         `json:"loaded_at"`
+	LastReloadAt    *time.Time        `json:"last_reload_at,omitempty"`
+	LastReloadError string            `json:"last_reload_error,omitempty"` // Lỗi của lần reload gần nhất, cấu hình cũ được giữ nguyên
+	PendingRestart  []string          `json:"pending_restart,omitempty"`   // Khóa đã đổi nhưng chỉ có hiệu lực sau khi khởi động lại
+	Reloadable      []string          `json:"reloadable"`
+	Settings        map[string]string `json:"settings"` // Giá trị hiện hành, secret đã bị che
+}
+
+// --- INTERFACES (PORTS) ---
+
+// ConfigManager cho phép xem và reload cấu hình lúc chạy
+type ConfigManager interface {
+	Status() ConfigStatus
+	// Reload đọc lại cấu hình, lỗi bọc ErrInvalidConfig khi cấu hình mới không hợp lệ (cấu hình cũ được giữ nguyên)
+	Reload() (ConfigStatus, error)
+}
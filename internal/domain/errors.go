@@ -0,0 +1,46 @@
+package domain
+
+import "errors"
+
+// Các lỗi nghiệp vụ dùng chung, lớp Delivery dựa vào đây để chọn HTTP status
+var (
+	ErrMediaTooLarge        = errors.New("media file exceeds the maximum allowed size")
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+	ErrMediaNotFound        = errors.New("media not found")
+	ErrInvalidContentFormat = errors.New("invalid content format")
+	ErrInvalidFeedFormat    = errors.New("invalid feed format")
+
+	ErrCategoryNotFound       = errors.New("category not found")
+	ErrParentCategoryNotFound = errors.New("parent category not found")
+	ErrCategoryCycle          = errors.New("category cannot be moved under itself or its descendants")
+	ErrCategoryHasChildren    = errors.New("category has child categories")
+	ErrInvalidCategorySort    = errors.New("invalid category sort")
+	ErrInvalidReorder         = errors.New("invalid reorder request")
+
+	ErrInvalidStatus = errors.New("invalid status")
+	ErrEmptyBatch    = errors.New("batch must contain at least one item")
+	ErrBatchTooLarge = errors.New("batch exceeds the maximum number of items")
+	ErrBatchConflict = errors.New("batch item was modified concurrently")
+
+	ErrInvalidSlug           = errors.New("invalid slug")
+	ErrDuplicateSlug         = errors.New("slug is already used by another post")
+	ErrInvalidTransferFormat = errors.New("invalid transfer format")
+	ErrInvalidWXR            = errors.New("invalid WordPress export file")
+
+	ErrInvalidAuditFilter = errors.New("invalid audit filter")
+
+	ErrOutboxBusy = errors.New("outbox is being dispatched by another relay")
+
+	ErrWebhookNotFound         = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+	ErrInvalidWebhookURL       = errors.New("invalid webhook url")
+	ErrInvalidWebhookEvent     = errors.New("invalid webhook event")
+	ErrInvalidWebhookStatus    = errors.New("invalid webhook delivery status")
+
+	ErrUnknownCacheFamily = errors.New("unknown cache family")
+	ErrCacheUnavailable   = errors.New("cache backend is unavailable, running uncached")
+
+	ErrStreamClosed = errors.New("change stream is shutting down")
+
+	ErrInvalidConfig = errors.New("invalid configuration")
+)
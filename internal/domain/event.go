@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// --- ENUMS & CONSTANTS ---
+
+// Loại domain event được ghi vào outbox
+const (
+	EventPostCreated       = "PostCreated"
+	EventPostUpdated       = "PostUpdated"
+	EventPostStatusChanged = "PostStatusChanged" // Kèm theo PostCreated/PostUpdated/Restore khi trạng thái thay đổi
+	EventPostDeleted       = "PostDeleted"       // Vào thùng rác (action delete) hoặc xóa vĩnh viễn (action purge)
+	EventCategoryChanged   = "CategoryChanged"   // Mọi thay đổi của danh mục, phân biệt qua action
+)
+
+// Loại aggregate của event
+const (
+	AggregatePost     = "post"
+	AggregateCategory = "category"
+)
+
+// --- ENTITIES ---
+
+// DomainEvent là một bản ghi trong bảng `outbox`. ID tăng dần theo thứ tự insert (không phải thứ tự commit)
+// và được consumer dùng để loại bỏ event trùng (giao ít nhất một lần).
+type DomainEvent struct {
+	ID            int64           `json:"id"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   int64           `json:"aggregate_id"`
+	Payload       json.RawMessage `json:"payload"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+}
+
+// PostEventPayload là payload của các event Post*. Post là trạng thái sau thay đổi,
+// riêng PostDeleted là trạng thái trước khi xóa.
+type PostEventPayload struct {
+	Action     string `json:"action"`
+	FromStatus string `json:"from_status,omitempty"`
+	ToStatus   string `json:"to_status,omitempty"`
+	Post       *Post  `json:"post"`
+}
+
+// CategoryEventPayload là payload của CategoryChanged, Category là trạng thái trước khi xóa khi action là delete/purge
+type CategoryEventPayload struct {
+	Action   string    `json:"action"`
+	Category *Category `json:"category"`
+}
+
+// --- INTERFACES (PORTS) ---
+
+// Transactor chạy fn trong một transaction. Repository nhận ctx của fn sẽ ghi trong transaction đó,
+// fn trả về lỗi thì toàn bộ thay đổi bị rollback.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type OutboxRepository interface {
+	// Append ghi event vào outbox, phải được gọi trong WithinTx cùng với thay đổi dữ liệu
+	Append(ctx context.Context, events []DomainEvent) error
+	// Dispatch giữ khóa relay, đọc tối đa limit event đã commit có ID nhỏ nhất và gọi publish. publish trả về số event đầu tiên
+	// đã giao thành công, các event này bị xóa khỏi outbox, phần còn lại được thử lại ở lần sau.
+	// Trả về ErrOutboxBusy khi một relay khác đang giữ khóa.
+	Dispatch(ctx context.Context, limit int, publish func(ctx context.Context, events []DomainEvent) (int, error)) (int, error)
+}
+
+// EventSink là đích nhận event từ outbox relay (Redis Streams, ...)
+type EventSink interface {
+	Name() string
+	// Publish giao lần lượt các event theo thứ tự, trả về số event đầu tiên đã giao thành công
+	Publish(ctx context.Context, events []DomainEvent) (int, error)
+}
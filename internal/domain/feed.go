@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// --- ENUMS & CONSTANTS ---
+const (
+	FeedFormatRSS  = "rss"
+	FeedFormatAtom = "atom"
+	FeedFormatJSON = "json"
+)
+
+// --- ENTITIES ---
+
+// Feed là tài liệu syndication đã render sẵn, kèm thông tin cho conditional GET
+type Feed struct {
+	Body         []byte    `json:"body"`
+	ContentType  string    `json:"content_type"`
+	ETag         string    `json:"etag"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+// --- INTERFACES (PORTS) ---
+
+type FeedUseCase interface {
+	// GetPostFeed trả về feed các bài Published, categoryID = 0 nghĩa là feed toàn site
+	GetPostFeed(ctx context.Context, format string, categoryID int64) (*Feed, error)
+}
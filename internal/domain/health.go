@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// --- ENUMS & CONSTANTS ---
+
+// Trạng thái tổng thể của instance
+const (
+	HealthStatusOK          = "ok"
+	HealthStatusDegraded    = "degraded"    // Dependency không bắt buộc (Redis) lỗi, vẫn phục vụ được
+	HealthStatusUnavailable = "unavailable" // Có dependency bắt buộc không phản hồi
+	HealthStatusDraining    = "draining"    // Đang shutdown, không nhận thêm traffic
+)
+
+// Trạng thái của một dependency
+const (
+	DependencyUp   = "up"
+	DependencyDown = "down"
+)
+
+// Trạng thái của circuit breaker
+const (
+	CircuitClosed = "closed" // Gọi dependency bình thường
+	CircuitOpen   = "open"   // Bỏ qua dependency, chờ probe nền thấy nó phục hồi
+)
+
+// --- ENTITIES ---
+
+// DependencyStatus là kết quả kiểm tra một dependency
+type DependencyStatus struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Optional  bool    `json:"optional,omitempty"` // Lỗi chỉ làm instance degraded, không làm mất readiness
+	Error     string  `json:"error,omitempty"`
+}
+
+// CircuitStats là trạng thái circuit breaker bảo vệ một dependency không bắt buộc
+type CircuitStats struct {
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+	Trips               int64      `json:"trips"`   // Số lần chuyển sang open
+	Skipped             int64      `json:"skipped"` // Số lời gọi bị bỏ qua khi open
+}
+
+// HealthReport là kết quả của readiness probe
+type HealthReport struct {
+	Status    string             `json:"status"`
+	Checks    []DependencyStatus `json:"checks"`
+	CheckedAt time.Time          `json:"checked_at"`
+}
+
+// HealthDetails bổ sung thông tin vận hành cho trang health của admin
+type HealthDetails struct {
+	HealthReport
+	StartedAt  time.Time      `json:"started_at"`
+	Uptime     string         `json:"uptime"`
+	GoVersion  string         `json:"go_version"`
+	Goroutines int            `json:"goroutines"`
+	Details    map[string]any `json:"details"` // Theo tên: thống kê pool, phiên bản schema, ...
+}
+
+// --- INTERFACES (PORTS) ---
+
+// HealthCheck kiểm tra một dependency, lỗi nghĩa là instance chưa sẵn sàng nhận traffic
+type HealthCheck interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+// OptionalHealthCheck đánh dấu dependency mà app vẫn phục vụ được khi nó lỗi (chạy ở chế độ degraded)
+type OptionalHealthCheck interface {
+	HealthCheck
+	Optional() bool
+}
+
+// CircuitBreaker cho biết trạng thái của circuit breaker, dùng cho health và metric
+type CircuitBreaker interface {
+	Stats() CircuitStats
+}
+
+// HealthDetailer cung cấp thông tin chi tiết (pool stats, schema, ...) cho trang health của admin
+type HealthDetailer interface {
+	Name() string
+	Details(ctx context.Context) (any, error)
+}
+
+type HealthUseCase interface {
+	// Readiness kiểm tra song song mọi dependency, trạng thái là degraded khi chỉ dependency không bắt buộc lỗi
+	// và draining khi đang shutdown
+	Readiness(ctx context.Context) *HealthReport
+	Details(ctx context.Context) *HealthDetails
+	// SetDraining đánh dấu instance đang shutdown để load balancer ngừng gửi traffic
+	SetDraining()
+}
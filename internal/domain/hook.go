@@ -0,0 +1,42 @@
+package domain
+
+import "context"
+
+// --- ENUMS & CONSTANTS ---
+
+// Loại thao tác ghi được thông báo tới ContentHook
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionRestore = "restore" // Khôi phục từ thùng rác
+	ActionPurge   = "purge"   // Xóa vĩnh viễn khỏi database
+)
+
+// --- INTERFACES (PORTS) ---
+
+// ContentHook được UseCase gọi sau mỗi thao tác ghi thành công lên Post/Category.
+// before là nil khi tạo mới, after là nil khi xóa. Hook không được làm hỏng thao tác ghi
+// nên chỉ log lỗi thay vì trả về.
+type ContentHook interface {
+	PostChanged(ctx context.Context, action string, before, after *Post)
+	CategoryChanged(ctx context.Context, action string, before, after *Category)
+}
+
+// PostChange và CategoryChange mô tả một bản ghi thay đổi trong thao tác hàng loạt
+type PostChange struct {
+	Before *Post
+	After  *Post
+}
+
+type CategoryChange struct {
+	Before *Category
+	After  *Category
+}
+
+// BatchContentHook là phần mở rộng tùy chọn của ContentHook. Hook implement interface này
+// nhận cả lô thay đổi trong một lần gọi (để xóa cache một lần), các hook khác được gọi lần lượt từng bản ghi.
+type BatchContentHook interface {
+	PostsChanged(ctx context.Context, action string, changes []PostChange)
+	CategoriesChanged(ctx context.Context, action string, changes []CategoryChange)
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// --- ENTITIES ---
+
+// CacheKeyInfo mô tả một khóa cache, TTL -1 nghĩa là không hết hạn
+type CacheKeyInfo struct {
+	Key  string        `json:"key"`
+	TTL  time.Duration `json:"ttl_ns"`
+	Size int64         `json:"size_bytes"`
+}
+
+// CacheFamilyStats là kết quả kiểm tra một nhóm khóa cache, Keys chỉ chứa tối đa limit khóa mẫu
+type CacheFamilyStats struct {
+	Family  string         `json:"family"`
+	Pattern string         `json:"pattern"`
+	Count   int64          `json:"count"`
+	Keys    []CacheKeyInfo `json:"keys"`
+}
+
+// ConsistencyIssue là một điểm dữ liệu không nhất quán được phát hiện khi kiểm tra
+type ConsistencyIssue struct {
+	Check    string `json:"check"`  // Tên phép kiểm tra, ví dụ post_category_missing
+	Entity   string `json:"entity"` // post | category
+	EntityID int64  `json:"entity_id"`
+	Detail   string `json:"detail"`
+}
+
+// ConsistencyReport tổng hợp kết quả kiểm tra, OK khi không có issue nào
+type ConsistencyReport struct {
+	OK     bool               `json:"ok"`
+	Checks []string           `json:"checks"`
+	Issues []ConsistencyIssue `json:"issues"`
+}
+
+// --- INTERFACES (PORTS) ---
+
+// CacheInspector duyệt và xóa khóa cache theo pattern (glob của Redis), dùng SCAN nên không chặn Redis
+type CacheInspector interface {
+	Inspect(ctx context.Context, pattern string, limit int) (count int64, keys []CacheKeyInfo, err error)
+	DeletePattern(ctx context.Context, pattern string) (int64, error)
+}
+
+type MaintenanceRepository interface {
+	// RebuildSearchIndex xây lại full-text index của bài viết
+	RebuildSearchIndex(ctx context.Context) error
+	// CheckConsistency chạy các phép kiểm tra dữ liệu, trả về tên các phép đã chạy và các issue tìm thấy
+	CheckConsistency(ctx context.Context) ([]string, []ConsistencyIssue, error)
+}
+
+// MaintenanceUseCase gom các thao tác vận hành dùng cho admin CLI
+type MaintenanceUseCase interface {
+	// CacheFamilies trả về tên các nhóm khóa cache được hỗ trợ
+	CacheFamilies() []string
+	InspectCache(ctx context.Context, family string, limit int) (*CacheFamilyStats, error)
+	FlushCache(ctx context.Context, family string) (int64, error)
+	RebuildSearch(ctx context.Context) error
+	CheckConsistency(ctx context.Context) (*ConsistencyReport, error)
+}
@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// --- ENUMS & CONSTANTS ---
+const (
+	MediaVariantThumb  = "thumb"
+	MediaVariantSmall  = "small"
+	MediaVariantMedium = "medium"
+)
+
+// --- ENTITIES ---
+
+// Media đại diện cho một tệp ảnh đã upload lên thư viện
+type Media struct {
+	ID         int64          `json:"id"`
+	FileName   string         `json:"file_name"`
+	MimeType   string         `json:"mime_type"`
+	Size       int64          `json:"size"`
+	Width      int            `json:"width"`
+	Height     int            `json:"height"`
+	StorageKey string         `json:"-"`
+	URL        string         `json:"url"`
+	Variants   []MediaVariant `json:"variants"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+// MediaVariant là một phiên bản đã resize của ảnh gốc (thumb, small, medium)
+type MediaVariant struct {
+	Name       string `json:"name"`
+	StorageKey string `json:"-"`
+	Width      int    `json:"width"`
+	Height     int    `json:"height"`
+	Size       int64  `json:"size"`
+	URL        string `json:"url"`
+}
+
+// MediaRefs là các bài viết và danh mục đã được gỡ tham chiếu tới media bị xóa
+type MediaRefs struct {
+	PostIDs         []int64
+	PostCategoryIDs []int64 // Danh mục của các bài viết trên (không trùng lặp), dùng để xóa cache feed
+	CategoryIDs     []int64
+}
+
+// --- INTERFACES (PORTS) ---
+
+// MediaStorage là nơi lưu trữ nội dung tệp (local filesystem, S3, ...)
+type MediaStorage interface {
+	// Save ghi nội dung tệp theo key
+	Save(ctx context.Context, key string, r io.Reader) error
+	// Delete xóa tệp theo key
+	Delete(ctx context.Context, key string) error
+	// URL trả về đường dẫn public của tệp
+	URL(key string) string
+}
+
+// MediaRepository lưu metadata của media trong bảng `media`
+type MediaRepository interface {
+	Fetch(ctx context.Context, limit int64, offset int64) ([]Media, error)
+	GetByID(ctx context.Context, id int64) (*Media, error)
+	// GetByIDs lấy nhiều media cùng lúc, id không tồn tại sẽ bị bỏ qua
+	GetByIDs(ctx context.Context, ids []int64) ([]Media, error)
+	Store(ctx context.Context, m *Media) error
+	// Delete xóa metadata và gỡ media_id/thumbnail của bài viết, danh mục đang dùng media trong cùng transaction
+	Delete(ctx context.Context, id int64) (*MediaRefs, error)
+}
+
+type MediaUseCase interface {
+	// Upload kiểm tra MIME, giới hạn dung lượng, lưu tệp gốc và sinh các biến thể
+	Upload(ctx context.Context, fileName string, r io.Reader) (*Media, error)
+	Fetch(ctx context.Context, page int64, pageSize int64) ([]Media, error)
+	GetByID(ctx context.Context, id int64) (*Media, error)
+	Delete(ctx context.Context, id int64) error
+	// Resolve lấy media theo danh sách id kèm URL, dùng để gắn vào Post/Category
+	Resolve(ctx context.Context, ids []int64) (map[int64]*Media, error)
+}
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"io"
 	"time"
 )
 
@@ -14,18 +15,40 @@ const (
 	StatusDeleted   = "Deleted" // Key cho tính năng Soft Delete
 )
 
+// Định dạng của Post.Content, quyết định cách render sang HTML
+const (
+	ContentFormatMarkdown  = "markdown"
+	ContentFormatHTML      = "html"
+	ContentFormatPlaintext = "plaintext"
+)
+
 // --- ENTITIES ---
 
 // Post đại diện cho bài viết trong hệ thống
 type Post struct {
-	ID          int64     `json:"id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Content     string    `json:"content"`
-	Thumbnail   string    `json:"thumbnail"`
-	Status      string    `json:"status"`
-	UpdateDate  time.Time `json:"update_date"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID            int64      `json:"id"`
+	Title         string     `json:"title"`
+	Slug          string     `json:"slug"` // Tùy chọn, duy nhất; rỗng được lưu là NULL, khi cập nhật thì rỗng giữ slug cũ
+	Description   string     `json:"description"`
+	Content       string     `json:"content"`
+	ContentFormat string     `json:"content_format"`
+	ContentHTML   string     `json:"content_html,omitempty"` // HTML đã sanitise, chỉ trả về ở GetByID
+	Thumbnail     string     `json:"thumbnail"`
+	MediaID       *int64     `json:"media_id"`
+	Media         *Media     `json:"media,omitempty"` // Được gắn khi đọc, không lưu trong bảng posts
+	CategoryID    *int64     `json:"category_id"`
+	Status        string     `json:"status"`
+	PublishDate   *time.Time `json:"publish_date"` // Do server gán khi bài chuyển sang Published lần đầu
+	UpdateDate    time.Time  `json:"update_date"`
+	CreatedAt     time.Time  `json:"created_at"`
+
+	// Thông tin thùng rác, chỉ có giá trị khi Status = Deleted
+	DeletedAt      *time.Time `json:"deleted_at,omitempty"`
+	PreviousStatus string     `json:"previous_status,omitempty"` // Trạng thái trước khi xóa, dùng khi Restore
+
+	// SourceID định danh bản ghi ở hệ thống nguồn khi import (ví dụ bài viết WordPress), không trả về qua API.
+	// Chỉ được ghi bởi UpsertBatch, rỗng thì giữ giá trị đang có.
+	SourceID string `json:"-"`
 }
 
 // --- INTERFACES (PORTS) ---
@@ -37,14 +60,64 @@ type PostRepository interface {
 	Fetch(ctx context.Context, limit int64, offset int64) ([]Post, error)
 	// GetByID lấy chi tiết một bài viết
 	GetByID(ctx context.Context, id int64) (*Post, error)
+	// GetByIDForUpdate đọc bài viết chưa bị xóa và khóa dòng (SELECT ... FOR UPDATE) tới hết transaction trong ctx
+	GetByIDForUpdate(ctx context.Context, id int64) (*Post, error)
 	// Store tạo mới một bài viết
 	Store(ctx context.Context, p *Post) error
 	// Update cập nhật thông tin bài viết
 	Update(ctx context.Context, p *Post) error
-	// Delete thực hiện xóa mềm (Soft Delete)
+	// Delete thực hiện xóa mềm (Soft Delete), lưu lại trạng thái cũ để Restore
 	Delete(ctx context.Context, id int64) error
+	// FetchTrash lấy danh sách bài viết đã xóa mềm, mới xóa nhất trước
+	FetchTrash(ctx context.Context, limit int64, offset int64) ([]Post, error)
+	// GetTrashedByID lấy một bài viết đang nằm trong thùng rác
+	GetTrashedByID(ctx context.Context, id int64) (*Post, error)
+	// FetchTrashedBefore lấy các bài đã xóa mềm trước thời điểm cutoff (dùng cho retention job)
+	FetchTrashedBefore(ctx context.Context, cutoff time.Time, limit int64) ([]Post, error)
+	// Restore đưa bài viết về trạng thái trước khi xóa
+	Restore(ctx context.Context, id int64) error
+	// Purge xóa vĩnh viễn một bài viết đang nằm trong thùng rác
+	Purge(ctx context.Context, id int64) error
 	// Search tìm kiếm bài viết theo từ khóa với phân trang
 	Search(ctx context.Context, keyword string, limit int64, offset int64) ([]Post, error)
+	// FetchPublished lấy các bài Published mới nhất theo thời điểm xuất bản (categoryID = 0: mọi danh mục)
+	FetchPublished(ctx context.Context, categoryID int64, limit int64) ([]Post, error)
+	// SitemapPages đếm các trang sitemap (chia theo khoảng id) có bài Published
+	SitemapPages(ctx context.Context, pageSize int64) ([]SitemapPage, error)
+	// SitemapEntries lấy id và update_date của các bài Published thuộc một trang sitemap
+	SitemapEntries(ctx context.Context, page int64, pageSize int64) ([]SitemapEntry, error)
+	// CountByStatus đếm bài viết theo trạng thái (kể cả trong thùng rác), dùng cho metric nội dung
+	CountByStatus(ctx context.Context) (map[string]int64, error)
+
+	// --- Batch API: mỗi hàm chạy trong một transaction, lỗi ở bất kỳ bản ghi nào sẽ rollback cả lô ---
+
+	// GetByIDs lấy các bài viết chưa bị xóa theo danh sách id (bỏ qua id không tồn tại)
+	GetByIDs(ctx context.Context, ids []int64) ([]Post, error)
+	// GetByIDsForUpdate giống GetByIDs và khóa các dòng (SELECT ... FOR UPDATE) tới hết transaction trong ctx
+	GetByIDsForUpdate(ctx context.Context, ids []int64) ([]Post, error)
+	// GetTrashedByIDs lấy các bài viết đang nằm trong thùng rác theo danh sách id
+	GetTrashedByIDs(ctx context.Context, ids []int64) ([]Post, error)
+	// StoreBatch tạo mới nhiều bài viết và gán ID cho từng phần tử
+	StoreBatch(ctx context.Context, posts []*Post) error
+	// UpdateStatusBatch chỉ ghi status, publish_date và update_date của nhiều bài viết,
+	// trả về ErrBatchConflict nếu có bài đã bị xóa giữa chừng
+	UpdateStatusBatch(ctx context.Context, posts []*Post) error
+	// DeleteBatch xóa mềm nhiều bài viết
+	DeleteBatch(ctx context.Context, ids []int64) error
+	// RestoreBatch khôi phục nhiều bài viết từ thùng rác
+	RestoreBatch(ctx context.Context, ids []int64) error
+
+	// --- Export/Import ---
+
+	// Export duyệt toàn bộ bài viết theo id bằng một cursor, gọi fn cho từng bài mà không nạp hết vào bộ nhớ
+	Export(ctx context.Context, includeDeleted bool, fn func(p *Post) error) error
+	// FetchByKeys lấy các bài viết (kể cả đã xóa mềm) có id thuộc ids hoặc slug thuộc slugs
+	FetchByKeys(ctx context.Context, ids []int64, slugs []string) ([]Post, error)
+	// FetchIDsBySource trả về id bài viết (kể cả đã xóa mềm) theo SourceID
+	FetchIDsBySource(ctx context.Context, sourceIDs []string) (map[string]int64, error)
+	// UpsertBatch ghi nhiều bài viết trong một transaction: ID khác 0 thì chèn với đúng ID đó
+	// hoặc ghi đè bản ghi đang có, ID bằng 0 thì chèn mới và gán ID
+	UpsertBatch(ctx context.Context, posts []*Post) error
 }
 
 // PostUseCase định nghĩa các logic nghiệp vụ (Input Port)
@@ -56,4 +129,28 @@ type PostUseCase interface {
 	Update(ctx context.Context, p *Post) error
 	Delete(ctx context.Context, id int64) error
 	Search(ctx context.Context, keyword string, page int64, pageSize int64) ([]Post, error)
+	FetchTrash(ctx context.Context, page int64, pageSize int64) ([]Post, error)
+	Restore(ctx context.Context, id int64) error
+	Purge(ctx context.Context, id int64) error
+	// PurgeExpired xóa vĩnh viễn các bài nằm trong thùng rác lâu hơn olderThan, trả về số bài đã xóa
+	PurgeExpired(ctx context.Context, olderThan time.Duration) (int, error)
+
+	// Thao tác hàng loạt: tối đa BatchMaxItems phần tử, báo kết quả theo từng phần tử
+	StoreBatch(ctx context.Context, posts []Post) (*BatchResult, error)
+	UpdateStatusBatch(ctx context.Context, ids []int64, status string) (*BatchResult, error)
+	DeleteBatch(ctx context.Context, ids []int64) (*BatchResult, error)
+	RestoreBatch(ctx context.Context, ids []int64) (*BatchResult, error)
+
+	// Export ghi toàn bộ bài viết ra w theo định dạng TransferFormat*
+	Export(ctx context.Context, w io.Writer, format string, includeDeleted bool) error
+	// Import đọc bài viết từ r và upsert theo id hoặc slug, dryRun chỉ kiểm tra mà không ghi
+	Import(ctx context.Context, r io.Reader, format string, dryRun bool) (*ImportReport, error)
+	// ImportPosts upsert danh sách bài viết đã đọc sẵn (dùng cho các importer khác như WXR),
+	// Row trong báo cáo là vị trí trong posts tính từ 1
+	ImportPosts(ctx context.Context, posts []Post, dryRun bool) (*ImportReport, error)
+}
+
+// ContentRenderer chuyển Post.Content sang HTML đã qua sanitiser allow-list
+type ContentRenderer interface {
+	Render(format string, content string) (string, error)
 }
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// --- ENTITIES ---
+
+// ReplicaStatus là trạng thái một MySQL replica trong vòng chia tải đọc
+type ReplicaStatus struct {
+	Name       string     `json:"name"`
+	Healthy    bool       `json:"healthy"`              // false: bị gỡ khỏi vòng, đọc chuyển sang replica khác hoặc primary
+	LagSeconds *float64   `json:"lag_seconds"`          // nil khi replication dừng hoặc chưa đo được
+	CheckedAt  *time.Time `json:"checked_at,omitempty"` // Lần kiểm tra độ trễ gần nhất
+	Error      string     `json:"error,omitempty"`      // Lý do bị gỡ khỏi vòng
+}
+
+// --- CONTEXT ---
+
+type readYourWritesKey struct{}
+
+// WithReadYourWrites gắn vào context cờ đọc từ primary. Cờ bật ngay khi primary = true, hoặc khi
+// repository ghi vào primary bằng context này (MarkWritten) để các lần đọc sau thấy được dữ liệu vừa ghi
+// thay vì đọc từ replica có thể chưa nhận thay đổi.
+func WithReadYourWrites(ctx context.Context, primary bool) context.Context {
+	flag := new(atomic.Bool)
+	flag.Store(primary)
+	return context.WithValue(ctx, readYourWritesKey{}, flag)
+}
+
+// MarkWritten bật cờ đọc từ primary của context, không làm gì khi context không mang cờ
+func MarkWritten(ctx context.Context) {
+	if flag, ok := ctx.Value(readYourWritesKey{}).(*atomic.Bool); ok {
+		flag.Store(true)
+	}
+}
+
+// ReadFromPrimary cho biết các lần đọc bằng context này phải đi tới primary
+func ReadFromPrimary(ctx context.Context) bool {
+	flag, ok := ctx.Value(readYourWritesKey{}).(*atomic.Bool)
+	return ok && flag.Load()
+}
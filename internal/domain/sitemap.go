@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// --- ENUMS & CONSTANTS ---
+const (
+	SitemapKindPosts      = "posts"
+	SitemapKindCategories = "categories"
+
+	// SitemapMaxURLs là giới hạn số URL trong một sitemap theo giao thức sitemaps.org
+	SitemapMaxURLs = 50000
+)
+
+// --- ENTITIES ---
+
+// SitemapPage là một sitemap con, trang n chứa các id trong khoảng ((n-1)*size, n*size]
+type SitemapPage struct {
+	Number  int64
+	LastMod time.Time
+}
+
+// SitemapEntry là một URL trong sitemap con
+type SitemapEntry struct {
+	ID      int64
+	LastMod time.Time
+}
+
+// --- INTERFACES (PORTS) ---
+
+// SitemapUseCase sinh sitemap index và các sitemap con, đồng thời là ContentHook
+// để chỉ sinh lại trang bị ảnh hưởng khi Post/Category thay đổi.
+type SitemapUseCase interface {
+	ContentHook
+	BatchContentHook
+	Index(ctx context.Context) ([]byte, error)
+	Page(ctx context.Context, kind string, page int64) ([]byte, error)
+}
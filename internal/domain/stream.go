@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// --- ENTITIES ---
+
+// ChangeNotification là thông báo gọn về một thay đổi của Post/Category, được đẩy qua SSE.
+// ID là id của domain event trong outbox nên tăng dần và dùng làm SSE id để resume.
+type ChangeNotification struct {
+	ID         int64     `json:"id"`
+	Type       string    `json:"type"`   // Loại domain event (PostCreated, CategoryChanged, ...)
+	Entity     string    `json:"entity"` // AggregatePost | AggregateCategory
+	EntityID   int64     `json:"entity_id"`
+	Action     string    `json:"action"`
+	Status     string    `json:"status"` // Trạng thái sau thay đổi, StatusDeleted khi vào thùng rác hoặc bị xóa vĩnh viễn
+	Title      string    `json:"title,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// ChangeFilter lọc thông báo theo entity và trạng thái, danh sách rỗng nghĩa là không lọc
+type ChangeFilter struct {
+	Entities []string
+	Statuses []string
+}
+
+// Match cho biết thông báo có thỏa bộ lọc không (so sánh trạng thái không phân biệt hoa thường)
+func (f ChangeFilter) Match(n ChangeNotification) bool {
+	if len(f.Entities) > 0 && !containsFold(f.Entities, n.Entity) {
+		return false
+	}
+	if len(f.Statuses) > 0 && !containsFold(f.Statuses, n.Status) {
+		return false
+	}
+	return true
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// --- INTERFACES (PORTS) ---
+
+// ChangeBroker phát thông báo tới mọi instance và giữ một buffer ngắn các thông báo gần nhất
+type ChangeBroker interface {
+	// Publish ghi vào buffer rồi phát tới mọi instance đang Subscribe
+	Publish(ctx context.Context, notifications []ChangeNotification) error
+	// Subscribe nhận thông báo từ mọi instance, channel bị đóng khi ctx bị hủy hoặc mất kết nối
+	Subscribe(ctx context.Context) (<-chan ChangeNotification, error)
+	// Since trả về các thông báo còn trong buffer có id lớn hơn lastID, theo thứ tự id
+	Since(ctx context.Context, lastID int64) ([]ChangeNotification, error)
+}
+
+// StreamUseCase là EventSink của outbox relay và phân phối thông báo tới các client SSE của instance này
+type StreamUseCase interface {
+	EventSink
+	// Subscribe đăng ký một client. backlog là các thông báo sau lastEventID còn trong buffer (rỗng khi lastEventID = 0),
+	// live nhận thông báo mới và bị đóng khi ctx bị hủy hoặc client đọc quá chậm.
+	// live có thể chứa lại thông báo đã có trong backlog, client bỏ qua theo id.
+	Subscribe(ctx context.Context, filter ChangeFilter, lastEventID int64) (backlog []ChangeNotification, live <-chan ChangeNotification, err error)
+	// Run nhận thông báo từ broker và phân phối tới các client tới khi ctx bị hủy
+	Run(ctx context.Context)
+	// Close ngắt mọi client và từ chối đăng ký mới, dùng khi server shutdown vì kết nối SSE không bao giờ rảnh
+	Close()
+}
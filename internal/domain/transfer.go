@@ -0,0 +1,29 @@
+package domain
+
+// --- ENUMS & CONSTANTS ---
+
+// Định dạng file dùng cho export/import bài viết
+const (
+	TransferFormatJSONL = "jsonl" // Mỗi dòng là một Post dạng JSON
+	TransferFormatCSV   = "csv"   // Dòng đầu là header, thứ tự cột không bắt buộc khi import
+)
+
+// --- ENTITIES ---
+
+// ImportRowError mô tả một dòng không hợp lệ, Row tính từ 1 (không kể header CSV)
+type ImportRowError struct {
+	Row   int    `json:"row"`
+	ID    int64  `json:"id,omitempty"`
+	Slug  string `json:"slug,omitempty"`
+	Error string `json:"error"`
+}
+
+// ImportReport tổng hợp kết quả import. Khi DryRun = true, Created/Updated là số dòng sẽ được ghi.
+type ImportReport struct {
+	DryRun  bool             `json:"dry_run"`
+	Total   int              `json:"total"`
+	Created int              `json:"created"`
+	Updated int              `json:"updated"`
+	Failed  int              `json:"failed"`
+	Errors  []ImportRowError `json:"errors"`
+}
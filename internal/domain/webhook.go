@@ -0,0 +1,172 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"net/netip"
+	"time"
+)
+
+// --- ENUMS & CONSTANTS ---
+
+// Trạng thái của một lần giao webhook
+const (
+	WebhookDeliveryPending   = "pending"   // Chờ gửi hoặc chờ thử lại
+	WebhookDeliverySucceeded = "succeeded" // Receiver trả về 2xx
+	WebhookDeliveryFailed    = "failed"    // Hết số lần thử hoặc webhook đã bị tắt
+)
+
+// WebhookEventPing là event thử do endpoint test gửi, không đi qua outbox
+const WebhookEventPing = "Ping"
+
+// Header của request webhook. Chữ ký là HMAC-SHA256 (hex) của "<timestamp>.<body>" với secret của webhook,
+// receiver nên từ chối request có timestamp lệch quá vài phút để chống replay.
+const (
+	WebhookHeaderID        = "X-Webhook-Delivery"
+	WebhookHeaderEvent     = "X-Webhook-Event"
+	WebhookHeaderTimestamp = "X-Webhook-Timestamp"
+	WebhookHeaderSignature = "X-Webhook-Signature" // "sha256=<hex>"
+)
+
+// --- ENTITIES ---
+
+// Webhook là một đăng ký nhận domain event. Events rỗng nghĩa là nhận mọi loại event.
+// Secret chỉ được trả về khi tạo mới hoặc khi đổi secret.
+type Webhook struct {
+	ID           int64      `json:"id"`
+	URL          string     `json:"url"`
+	Events       []string   `json:"events"`
+	Secret       string     `json:"secret,omitempty"`
+	Active       bool       `json:"active"`
+	FailureCount int        `json:"failure_count"` // Số lần gửi thất bại liên tiếp
+	DisabledAt   *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// Accepts cho biết webhook có đăng ký loại event này không
+func (w *Webhook) Accepts(eventType string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// nonPublicPrefixes là các dải địa chỉ đặc biệt ngoài những dải netip đã phân loại (private, loopback, link-local, ...)
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "this network"
+	netip.MustParsePrefix("100.64.0.0/10"),   // CGNAT
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),    // TEST-NET-1
+	netip.MustParsePrefix("198.18.0.0/15"),   // benchmark
+	netip.MustParsePrefix("198.51.100.0/24"), // TEST-NET-2
+	netip.MustParsePrefix("203.0.113.0/24"),  // TEST-NET-3
+	netip.MustParsePrefix("240.0.0.0/4"),     // reserved, gồm cả broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64, trỏ tới địa chỉ IPv4 bất kỳ
+	netip.MustParsePrefix("64:ff9b:1::/48"),  // NAT64 nội bộ
+	netip.MustParsePrefix("2001:db8::/32"),   // documentation
+	netip.MustParsePrefix("2002::/16"),       // 6to4, nhúng địa chỉ IPv4 bất kỳ
+}
+
+// IsPublicWebhookAddr cho biết webhook có được gửi tới địa chỉ này không: chỉ địa chỉ unicast công khai,
+// loại trừ loopback, mạng riêng, link-local (gồm metadata 169.254.169.254 của cloud) và các dải đặc biệt
+func IsPublicWebhookAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || !addr.IsGlobalUnicast() || addr.IsPrivate() || addr.IsLoopback() ||
+		addr.IsLinkLocalUnicast() || addr.IsUnspecified() {
+		return false
+	}
+	for _, p := range nonPublicPrefixes {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// WebhookDelivery là một bản ghi trong delivery log, Payload là body được gửi (giữ nguyên khi replay)
+type WebhookDelivery struct {
+	ID            int64           `json:"id"`
+	WebhookID     int64           `json:"webhook_id"`
+	EventID       int64           `json:"event_id"` // id của event trong outbox, 0 với Ping
+	EventType     string          `json:"event_type"`
+	Payload       json.RawMessage `json:"payload"`
+	Status        string          `json:"status"`
+	Attempts      int             `json:"attempts"`
+	ResponseCode  *int            `json:"response_code"`
+	ResponseBody  string          `json:"response_body"` // Đã cắt ngắn
+	Error         string          `json:"error"`
+	DurationMs    int64           `json:"duration_ms"`
+	ReplayOf      *int64          `json:"replay_of,omitempty"`
+	NextAttemptAt *time.Time      `json:"next_attempt_at,omitempty"`
+	LastAttemptAt *time.Time      `json:"last_attempt_at,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// WebhookDeliveryFilter lọc delivery log, trường rỗng nghĩa là không lọc
+type WebhookDeliveryFilter struct {
+	WebhookID int64
+	Status    string
+}
+
+// WebhookResponse là kết quả một lần gửi HTTP, Err khác nil khi không nhận được response
+type WebhookResponse struct {
+	StatusCode int
+	Body       string
+	Duration   time.Duration
+	Err        error
+}
+
+// --- INTERFACES (PORTS) ---
+
+type WebhookRepository interface {
+	Fetch(ctx context.Context, limit int64, offset int64) ([]Webhook, error)
+	GetByID(ctx context.Context, id int64) (*Webhook, error)
+	// FetchActive trả về mọi webhook đang bật, dùng khi phân phối event
+	FetchActive(ctx context.Context) ([]Webhook, error)
+	Store(ctx context.Context, w *Webhook) error
+	Update(ctx context.Context, w *Webhook) error
+	Delete(ctx context.Context, id int64) error
+	// RecordAttempt cập nhật số lần thất bại liên tiếp, tắt webhook khi đạt disableAfter (0 = không tắt).
+	// Trả về true nếu lần gọi này làm webhook bị tắt.
+	RecordAttempt(ctx context.Context, id int64, success bool, disableAfter int) (bool, error)
+
+	// StoreDeliveries bỏ qua delivery trùng (cùng webhook và event) để relay giao lại không tạo bản ghi mới
+	StoreDeliveries(ctx context.Context, deliveries []WebhookDelivery) error
+	GetDelivery(ctx context.Context, id int64) (*WebhookDelivery, error)
+	FetchDeliveries(ctx context.Context, filter WebhookDeliveryFilter, limit int64, offset int64) ([]WebhookDelivery, error)
+	// ClaimDueDeliveries lấy các delivery pending đã tới hạn và dời next_attempt_at thêm lease,
+	// instance khác sẽ không lấy trùng trong thời gian lease
+	ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, d *WebhookDelivery) error
+}
+
+// WebhookSender gửi request HTTP POST tới receiver
+type WebhookSender interface {
+	Send(ctx context.Context, url string, headers map[string]string, body []byte) WebhookResponse
+}
+
+// WebhookUseCase quản lý đăng ký webhook và là EventSink của outbox relay:
+// event được ghi thành delivery, việc gửi HTTP do DeliverDue thực hiện.
+type WebhookUseCase interface {
+	EventSink
+	Fetch(ctx context.Context, page int64, pageSize int64) ([]Webhook, error)
+	GetByID(ctx context.Context, id int64) (*Webhook, error)
+	Store(ctx context.Context, w *Webhook) error
+	Update(ctx context.Context, w *Webhook) error
+	Delete(ctx context.Context, id int64) error
+	// Test gửi ngay một event Ping và trả về delivery đã ghi log
+	Test(ctx context.Context, id int64) (*WebhookDelivery, error)
+
+	FetchDeliveries(ctx context.Context, filter WebhookDeliveryFilter, page int64, pageSize int64) ([]WebhookDelivery, error)
+	GetDelivery(ctx context.Context, id int64) (*WebhookDelivery, error)
+	// Replay tạo delivery mới với cùng payload, được gửi ở lượt DeliverDue kế tiếp
+	Replay(ctx context.Context, id int64) (*WebhookDelivery, error)
+	// DeliverDue gửi tối đa limit delivery đã tới hạn, trả về số delivery đã xử lý
+	DeliverDue(ctx context.Context, limit int) (int, error)
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestIsPublicWebhookAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"8.8.8.8", true},
+		{"2606:4700:4700::1111", true},
+		{"127.0.0.1", false},
+		{"127.10.0.5", false},
+		{"::1", false},
+		{"10.0.0.1", false},
+		{"172.16.5.4", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false}, // metadata của cloud
+		{"fe80::1", false},
+		{"fd00:ec2::254", false}, // metadata IPv6 của AWS (ULA)
+		{"0.0.0.0", false},
+		{"::", false},
+		{"100.64.0.1", false},
+		{"198.18.0.1", false},
+		{"224.0.0.1", false},
+		{"255.255.255.255", false},
+		{"::ffff:127.0.0.1", false}, // IPv4-mapped
+		{"::ffff:169.254.169.254", false},
+		{"64:ff9b::a9fe:a9fe", false}, // NAT64 của 169.254.169.254
+		{"2002:a9fe:a9fe::1", false},  // 6to4 của 169.254.169.254
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			if got := IsPublicWebhookAddr(netip.MustParseAddr(tt.addr)); got != tt.want {
+				t.Errorf("IsPublicWebhookAddr(%s) = %v, want %v", tt.addr, got, tt.want)
+			}
+		})
+	}
+}
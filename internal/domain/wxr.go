@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"context"
+	"io"
+)
+
+// --- ENUMS & CONSTANTS ---
+
+// Loại bản ghi trong file WXR (WordPress eXtended RSS) được báo cáo trong WXRImportSummary
+const (
+	WXRKindCategory = "category"
+	WXRKindPost     = "post"
+	WXRKindPage     = "page"
+	WXRKindOther    = "other" // attachment, nav_menu_item, revision, ... không được import
+)
+
+// WXRPagesCategory là danh mục gom các trang (page) của WordPress, được tạo khi cần.
+// Trang được import như bài viết thuộc danh mục này để vẫn phân biệt được với bài viết thường.
+const WXRPagesCategory = "Pages"
+
+// --- ENTITIES ---
+
+// WXRImportCounts đếm kết quả theo từng loại, Unchanged là bản ghi đã có sẵn và không cần ghi lại
+type WXRImportCounts struct {
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+	Skipped   int `json:"skipped"`
+}
+
+// WXRSkippedItem là một bản ghi WordPress không được import cùng lý do
+type WXRSkippedItem struct {
+	Kind     string `json:"kind"`
+	SourceID int64  `json:"source_id"` // term_id hoặc post_id bên WordPress
+	Title    string `json:"title"`
+	Reason   string `json:"reason"`
+}
+
+// WXRImportSummary tổng hợp một lần import WXR
+type WXRImportSummary struct {
+	Categories WXRImportCounts  `json:"categories"`
+	Posts      WXRImportCounts  `json:"posts"`
+	Pages      WXRImportCounts  `json:"pages"`
+	Other      WXRImportCounts  `json:"other"`
+	Skipped    []WXRSkippedItem `json:"skipped"`
+}
+
+// --- INTERFACES (PORTS) ---
+
+// WXRImportUseCase import bài viết, trang và danh mục từ file export của WordPress.
+// Chạy lại cùng một file không tạo bản ghi trùng: bài viết khớp theo post_id của WordPress (lưu trong SourceID),
+// danh mục khớp theo tên. Bài viết có slug trùng bài đang có mà không do import này tạo thì bị bỏ qua.
+// Trang (page) được import như bài viết thuộc danh mục WXRPagesCategory.
+type WXRImportUseCase interface {
+	Import(ctx context.Context, r io.Reader) (*WXRImportSummary, error)
+}
@@ -0,0 +1,87 @@
+package localfs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"Test2/internal/domain"
+)
+
+// localMediaStorage lưu tệp media trên filesystem của máy chủ,
+// các tệp được phục vụ tĩnh qua baseURL (xem r.Static trong main.go)
+type localMediaStorage struct {
+	baseDir string
+	baseURL string
+}
+
+func NewLocalMediaStorage(baseDir string, baseURL string) (domain.MediaStorage, error) {
+	if err := os.MkdirAll(baseDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create media directory %s: %w", baseDir, err)
+	}
+	return &localMediaStorage{
+		baseDir: baseDir,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}, nil
+}
+
+// resolve chuyển key sang đường dẫn tuyệt đối và chặn path traversal ("../")
+func (s *localMediaStorage) resolve(key string) (string, error) {
+	cleaned := path.Clean("/" + key)
+	if cleaned == "/" || strings.Contains(key, "..") {
+		return "", fmt.Errorf("invalid media key %q", key)
+	}
+	return filepath.Join(s.baseDir, filepath.FromSlash(cleaned)), nil
+}
+
+func (s *localMediaStorage) Save(ctx context.Context, key string, r io.Reader) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	target, err := s.resolve(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+
+	// Ghi ra tệp tạm rồi rename để không bao giờ phục vụ một tệp ghi dở
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), target)
+}
+
+func (s *localMediaStorage) Delete(ctx context.Context, key string) error {
+	target, err := s.resolve(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *localMediaStorage) URL(key string) string {
+	return s.baseURL + "/" + strings.TrimPrefix(key, "/")
+}
@@ -0,0 +1,121 @@
+package mysql
+
+import (
+	"Test2/internal/domain"
+	"context"
+	"encoding/json"
+	"strings"
+)
+
+const auditColumns = `id, actor, request_id, ip, entity_type, entity_id, action, before_data, after_data, created_at`
+
+func NewMysqlAuditRepository(db *Cluster) domain.AuditRepository {
+	return &mysqlAuditRepo{db}
+}
+
+// mysqlAuditRepo chỉ có INSERT và SELECT, bảng audit_log còn được trigger chặn UPDATE/DELETE
+type mysqlAuditRepo struct {
+	db *Cluster
+}
+
+// nullJSON lưu snapshot rỗng thành NULL
+func nullJSON(v json.RawMessage) any {
+	if len(v) == 0 {
+		return nil
+	}
+	return []byte(v)
+}
+
+func (m *mysqlAuditRepo) Store(ctx context.Context, entries []domain.AuditEntry) error {
+	defer observeQuery("audit", "Store")()
+	if len(entries) == 0 {
+		return nil
+	}
+
+	values := strings.TrimSuffix(strings.Repeat("(?, ?, ?, ?, ?, ?, ?, ?, ?), ", len(entries)), ", ")
+	query := `INSERT INTO audit_log (actor, request_id, ip, entity_type, entity_id, action, before_data, after_data, created_at)
+				VALUES ` + values
+
+	args := make([]any, 0, len(entries)*9)
+	for _, e := range entries {
+		args = append(args, e.Actor, e.RequestID, e.IP, e.EntityType, e.EntityID, e.Action, nullJSON(e.Before), nullJSON(e.After), e.CreatedAt)
+	}
+
+	res, err := conn(ctx, m.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	// Với INSERT nhiều dòng, LastInsertId là id của dòng đầu tiên và các id liên tiếp nhau
+	firstID, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	for i := range entries {
+		entries[i].ID = firstID + int64(i)
+	}
+	return nil
+}
+
+func (m *mysqlAuditRepo) Fetch(ctx context.Context, filter domain.AuditFilter, limit int64, offset int64) ([]domain.AuditEntry, error) {
+	defer observeQuery("audit", "Fetch")()
+	conds := make([]string, 0, 5)
+	args := make([]any, 0, 7)
+
+	if filter.EntityType != "" {
+		conds = append(conds, `entity_type = ?`)
+		args = append(args, filter.EntityType)
+	}
+	if filter.EntityID != 0 {
+		conds = append(conds, `entity_id = ?`)
+		args = append(args, filter.EntityID)
+	}
+	if filter.Actor != "" {
+		conds = append(conds, `actor = ?`)
+		args = append(args, filter.Actor)
+	}
+	if filter.From != nil {
+		conds = append(conds, `created_at >= ?`)
+		args = append(args, *filter.From)
+	}
+	if filter.To != nil {
+		conds = append(conds, `created_at < ?`)
+		args = append(args, *filter.To)
+	}
+
+	where := ""
+	if len(conds) > 0 {
+		where = `WHERE ` + strings.Join(conds, " AND ")
+	}
+
+	query := `SELECT ` + auditColumns + `
+				FROM audit_log
+				` + where + `
+				ORDER BY created_at DESC, id DESC
+				LIMIT ? OFFSET ?`
+	args = append(args, limit, offset)
+
+	rows, err := readConn(ctx, m.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]domain.AuditEntry, 0, limit)
+	for rows.Next() {
+		e := domain.AuditEntry{}
+		var before, after []byte
+		err := rows.Scan(&e.ID, &e.Actor, &e.RequestID, &e.IP, &e.EntityType, &e.EntityID, &e.Action, &before, &after, &e.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if len(before) > 0 {
+			e.Before = before
+		}
+		if len(after) > 0 {
+			e.After = after
+		}
+		result = append(result, e)
+	}
+	return result, rows.Err()
+}
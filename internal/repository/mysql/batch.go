@@ -0,0 +1,287 @@
+package mysql
+
+import (
+	"Test2/internal/domain"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Batch API cho posts và categories. Mỗi hàm dùng một transaction và một prepared statement,
+// bản ghi không còn khớp điều kiện (bị sửa/xóa giữa lúc UseCase kiểm tra và lúc ghi)
+// trả về ErrBatchConflict để rollback cả lô.
+
+// execEach chạy stmt cho từng bộ tham số, yêu cầu mỗi lần ảnh hưởng đúng một bản ghi
+func execEach(ctx context.Context, tx *sql.Tx, query string, ids []int64, args func(i int) []any) error {
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for i, id := range ids {
+		res, err := stmt.ExecContext(ctx, args(i)...)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err == nil && n == 0 {
+			return fmt.Errorf("%w: %d", domain.ErrBatchConflict, id)
+		}
+	}
+	return nil
+}
+
+// --- POST ---
+
+func (m *mysqlPostRepo) GetByIDs(ctx context.Context, ids []int64) ([]domain.Post, error) {
+	defer observeQuery("post", "GetByIDs")()
+	if len(ids) == 0 {
+		return []domain.Post{}, nil
+	}
+
+	placeholders, args := inPlaceholders(ids)
+	query := `SELECT ` + postColumns + `
+			  FROM posts
+			  WHERE id IN (` + placeholders + `)
+			  AND status != ?`
+
+	return m.fetch(ctx, query, int64(len(ids)), append(args, domain.StatusDeleted)...)
+}
+
+// GetByIDsForUpdate khóa theo thứ tự id để các lô chồng nhau không deadlock
+func (m *mysqlPostRepo) GetByIDsForUpdate(ctx context.Context, ids []int64) ([]domain.Post, error) {
+	defer observeQuery("post", "GetByIDsForUpdate")()
+	if len(ids) == 0 {
+		return []domain.Post{}, nil
+	}
+
+	placeholders, args := inPlaceholders(ids)
+	query := `SELECT ` + postColumns + `
+			  FROM posts
+			  WHERE id IN (` + placeholders + `)
+			  AND status != ?
+			  ORDER BY id
+			  FOR UPDATE`
+
+	return m.fetch(ctx, query, int64(len(ids)), append(args, domain.StatusDeleted)...)
+}
+
+func (m *mysqlPostRepo) GetTrashedByIDs(ctx context.Context, ids []int64) ([]domain.Post, error) {
+	defer observeQuery("post", "GetTrashedByIDs")()
+	if len(ids) == 0 {
+		return []domain.Post{}, nil
+	}
+
+	placeholders, args := inPlaceholders(ids)
+	query := `SELECT ` + postColumns + `
+			  FROM posts
+			  WHERE id IN (` + placeholders + `)
+			  AND status = ?`
+
+	return m.fetch(ctx, query, int64(len(ids)), append(args, domain.StatusDeleted)...)
+}
+
+func (m *mysqlPostRepo) StoreBatch(ctx context.Context, posts []*domain.Post) error {
+	defer observeQuery("post", "StoreBatch")()
+	query := `INSERT INTO posts (title, slug, description, content, content_format, thumbnail, media_id, category_id, status, publish_date, update_date, created_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	return withTx(ctx, m.db, func(tx *sql.Tx) error {
+		stmt, err := tx.PrepareContext(ctx, query)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		for _, p := range posts {
+			res, err := stmt.ExecContext(ctx, p.Title, nullIfEmpty(p.Slug), p.Description, p.Content, p.ContentFormat, p.Thumbnail, p.MediaID, p.CategoryID, p.Status, p.PublishDate, p.UpdateDate, p.CreatedAt)
+			if err != nil {
+				return translatePostErr(err)
+			}
+			if p.ID, err = res.LastInsertId(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (m *mysqlPostRepo) UpdateStatusBatch(ctx context.Context, posts []*domain.Post) error {
+	defer observeQuery("post", "UpdateStatusBatch")()
+	// Chỉ ghi các cột thao tác đổi trạng thái chạm tới, không ghi đè thay đổi khác trên bài viết
+	query := `UPDATE posts SET
+				status = ?,
+				publish_date = ?,
+				update_date = ?
+				WHERE id = ?
+				AND status != ?`
+
+	ids := make([]int64, len(posts))
+	for i, p := range posts {
+		ids[i] = p.ID
+	}
+
+	return withTx(ctx, m.db, func(tx *sql.Tx) error {
+		return execEach(ctx, tx, query, ids, func(i int) []any {
+			p := posts[i]
+			return []any{p.Status, p.PublishDate, p.UpdateDate, p.ID, domain.StatusDeleted}
+		})
+	})
+}
+
+func (m *mysqlPostRepo) DeleteBatch(ctx context.Context, ids []int64) error {
+	defer observeQuery("post", "DeleteBatch")()
+	query := `UPDATE posts SET
+				previous_status = status,
+				status = ?,
+				deleted_at = ?
+				WHERE id = ?
+				AND status != ?`
+
+	now := time.Now()
+	return withTx(ctx, m.db, func(tx *sql.Tx) error {
+		return execEach(ctx, tx, query, ids, func(i int) []any {
+			return []any{domain.StatusDeleted, now, ids[i], domain.StatusDeleted}
+		})
+	})
+}
+
+func (m *mysqlPostRepo) RestoreBatch(ctx context.Context, ids []int64) error {
+	defer observeQuery("post", "RestoreBatch")()
+	query := `UPDATE posts SET
+				status = COALESCE(previous_status, ?),
+				previous_status = NULL,
+				deleted_at = NULL
+				WHERE id = ?
+				AND status = ?`
+
+	return withTx(ctx, m.db, func(tx *sql.Tx) error {
+		return execEach(ctx, tx, query, ids, func(i int) []any {
+			return []any{domain.StatusDraft, ids[i], domain.StatusDeleted}
+		})
+	})
+}
+
+// --- CATEGORY ---
+
+func (m *mysqlCateRepo) GetByIDs(ctx context.Context, ids []int64) ([]domain.Category, error) {
+	defer observeQuery("category", "GetByIDs")()
+	if len(ids) == 0 {
+		return []domain.Category{}, nil
+	}
+
+	placeholders, args := inPlaceholders(ids)
+	query := `SELECT ` + categoryColumns + `
+				FROM categories
+				WHERE id IN (` + placeholders + `)
+				AND status != ?`
+
+	return m.fetch(ctx, query, int64(len(ids)), append(args, domain.CategoryStatusInactive)...)
+}
+
+func (m *mysqlCateRepo) GetTrashedByIDs(ctx context.Context, ids []int64) ([]domain.Category, error) {
+	defer observeQuery("category", "GetTrashedByIDs")()
+	if len(ids) == 0 {
+		return []domain.Category{}, nil
+	}
+
+	placeholders, args := inPlaceholders(ids)
+	query := `SELECT ` + categoryColumns + `
+				FROM categories
+				WHERE id IN (` + placeholders + `)
+				AND status = ?
+				AND deleted_at IS NOT NULL`
+
+	return m.fetch(ctx, query, int64(len(ids)), append(args, domain.CategoryStatusInactive)...)
+}
+
+func (m *mysqlCateRepo) StoreBatch(ctx context.Context, categories []*domain.Category) error {
+	defer observeQuery("category", "StoreBatch")()
+	query := `INSERT INTO categories (title , description, thumbnail, media_id, parent_id, position, status, updated_at, created_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	return withTx(ctx, m.db, func(tx *sql.Tx) error {
+		stmt, err := tx.PrepareContext(ctx, query)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		for _, c := range categories {
+			res, err := stmt.ExecContext(ctx, c.Title, c.Description, c.Thumbnail, c.MediaID, c.ParentID, c.Position, c.Status, c.UpdatedAt, c.CreatedAt)
+			if err != nil {
+				return err
+			}
+			if c.ID, err = res.LastInsertId(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (m *mysqlCateRepo) DeleteBatch(ctx context.Context, ids []int64, reparent bool) error {
+	defer observeQuery("category", "DeleteBatch")()
+	deleteQuery := `UPDATE categories SET
+				previous_status = status,
+				status = ?,
+				deleted_at = ?
+				WHERE id = ?
+				AND status != ?`
+
+	now := time.Now()
+	return withTx(ctx, m.db, func(tx *sql.Tx) error {
+		for _, id := range ids {
+			if reparent {
+				// Đọc parent_id trong transaction: danh mục cha có thể vừa bị xóa ở bước trước
+				// và danh mục này đã được chuyển lên cấp trên
+				var parentID sql.NullInt64
+				err := tx.QueryRowContext(ctx, `SELECT parent_id FROM categories WHERE id = ? FOR UPDATE`, id).Scan(&parentID)
+				if err != nil {
+					if err == sql.ErrNoRows {
+						return fmt.Errorf("%w: %d", domain.ErrBatchConflict, id)
+					}
+					return err
+				}
+
+				_, err = tx.ExecContext(ctx, `UPDATE categories SET parent_id = ?, updated_at = ? WHERE parent_id = ?`, nullInt64Ptr(parentID), now, id)
+				if err != nil {
+					return err
+				}
+			}
+
+			res, err := tx.ExecContext(ctx, deleteQuery, domain.CategoryStatusInactive, now, id, domain.CategoryStatusInactive)
+			if err != nil {
+				return err
+			}
+			if n, err := res.RowsAffected(); err == nil && n == 0 {
+				return fmt.Errorf("%w: %d", domain.ErrBatchConflict, id)
+			}
+		}
+		return nil
+	})
+}
+
+func (m *mysqlCateRepo) RestoreBatch(ctx context.Context, categories []*domain.Category) error {
+	defer observeQuery("category", "RestoreBatch")()
+	query := `UPDATE categories SET
+				status = COALESCE(previous_status, ?),
+				previous_status = NULL,
+				deleted_at = NULL,
+				parent_id = ?
+				WHERE id = ?
+				AND status = ?
+				AND deleted_at IS NOT NULL`
+
+	ids := make([]int64, len(categories))
+	for i, c := range categories {
+		ids[i] = c.ID
+	}
+
+	return withTx(ctx, m.db, func(tx *sql.Tx) error {
+		return execEach(ctx, tx, query, ids, func(i int) []any {
+			return []any{domain.CategoryStatusActive, categories[i].ParentID, ids[i], domain.CategoryStatusInactive}
+		})
+	})
+}
@@ -5,23 +5,62 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
 )
 
-func NewMysqlCateRepository(db *sql.DB) domain.CategoryRepository {
+// categoryColumns là danh sách cột dùng chung cho mọi câu SELECT, thứ tự phải khớp với scanCategory
+const categoryColumns = `id, title, description, thumbnail, media_id, parent_id, position, status, updated_at, created_at, deleted_at, previous_status`
+
+func NewMysqlCateRepository(db *Cluster) domain.CategoryRepository {
 	return &mysqlCateRepo{db}
 }
 
 type mysqlCateRepo struct {
-	db *sql.DB
+	db *Cluster
+}
+
+func scanCategory(s rowScanner, c *domain.Category) error {
+	var mediaID, parentID sql.NullInt64
+	var deletedAt sql.NullTime
+	var previousStatus sql.NullString
+	err := s.Scan(&c.ID, &c.Title, &c.Description, &c.Thumbnail, &mediaID, &parentID, &c.Position, &c.Status, &c.UpdatedAt, &c.CreatedAt, &deletedAt, &previousStatus)
+	if err != nil {
+		return err
+	}
+	c.MediaID = nullInt64Ptr(mediaID)
+	c.ParentID = nullInt64Ptr(parentID)
+	c.DeletedAt = nullTimePtr(deletedAt)
+	c.PreviousStatus = previousStatus.String
+	return nil
 }
 
-func (m *mysqlCateRepo) Fetch(ctx context.Context, limit int64, offset int64) ([]domain.Category, error) {
-	query := `SELECT id, title, description, thumbnail, status, updated_at, created_at
+// categoryOrderBy ánh xạ kiểu sắp xếp sang mệnh đề ORDER BY (whitelist, không nối chuỗi từ client)
+var categoryOrderBy = map[string]string{
+	domain.CategorySortNewest:   "created_at DESC",
+	domain.CategorySortOldest:   "created_at ASC",
+	domain.CategorySortTitle:    "title ASC",
+	domain.CategorySortPosition: "parent_id IS NOT NULL, parent_id, position, title",
+}
+
+func (m *mysqlCateRepo) Fetch(ctx context.Context, limit int64, offset int64, sort string) ([]domain.Category, error) {
+	defer observeQuery("category", "Fetch")()
+	orderBy, ok := categoryOrderBy[sort]
+	if !ok {
+		orderBy = categoryOrderBy[domain.CategorySortNewest]
+	}
+
+	query := `SELECT ` + categoryColumns + `
 				FROM categories
 				WHERE status != ?
-				ORDER BY created_at DESC
+				ORDER BY ` + orderBy + `
 				LIMIT ? OFFSET ?`
-	rows, err := m.db.QueryContext(ctx, query, domain.CategoryStatusInactive, limit, offset)
+
+	return m.fetch(ctx, query, limit, domain.CategoryStatusInactive, limit, offset)
+}
+
+func (m *mysqlCateRepo) fetch(ctx context.Context, query string, limit int64, args ...any) ([]domain.Category, error) {
+	rows, err := readConn(ctx, m.db).QueryContext(ctx, query, args...)
 
 	if err != nil {
 		return nil, err
@@ -33,30 +72,49 @@ func (m *mysqlCateRepo) Fetch(ctx context.Context, limit int64, offset int64) ([
 
 	for rows.Next() {
 		c := domain.Category{}
-		err := rows.Scan(&c.ID, &c.Title, &c.Description, &c.Thumbnail, &c.Status, &c.UpdatedAt, &c.CreatedAt)
-		if err != nil {
+		if err := scanCategory(rows, &c); err != nil {
 			return nil, err
 		}
 
 		result = append(result, c)
 	}
-	return result, nil
+	return result, rows.Err()
 }
 
 func (m *mysqlCateRepo) GetByID(ctx context.Context, id int64) (*domain.Category, error) {
-	query := `SELECT id, title, description, thumbnail, status, updated_at, created_at
+	defer observeQuery("category", "GetByID")()
+	query := `SELECT ` + categoryColumns + `
 				FROM categories
 				WHERE id = ?
 				AND status != ?`
 
-	row := m.db.QueryRowContext(ctx, query, id, domain.CategoryStatusInactive)
+	row := readConn(ctx, m.db).QueryRowContext(ctx, query, id, domain.CategoryStatusInactive)
 
 	c := &domain.Category{}
-	err := row.Scan(&c.ID, &c.Title, &c.Description, &c.Thumbnail, &c.Status, &c.UpdatedAt, &c.CreatedAt)
+	err := scanCategory(row, c)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrCategoryNotFound
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+func (m *mysqlCateRepo) GetByIDForUpdate(ctx context.Context, id int64) (*domain.Category, error) {
+	defer observeQuery("category", "GetByIDForUpdate")()
+	query := `SELECT ` + categoryColumns + `
+				FROM categories
+				WHERE id = ?
+				AND status != ?
+				FOR UPDATE`
 
+	c := &domain.Category{}
+	err := scanCategory(conn(ctx, m.db).QueryRowContext(ctx, query, id, domain.CategoryStatusInactive), c)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("category not found")
+			return nil, domain.ErrCategoryNotFound
 		}
 		return nil, err
 	}
@@ -64,10 +122,11 @@ func (m *mysqlCateRepo) GetByID(ctx context.Context, id int64) (*domain.Category
 }
 
 func (m *mysqlCateRepo) Store(ctx context.Context, c *domain.Category) error {
-	query := `INSERT INTO categories (title , description, thumbnail, status, updated_at, created_at)
-				VALUES (?, ?, ?, ?, ?, ?)`
+	defer observeQuery("category", "Store")()
+	query := `INSERT INTO categories (title , description, thumbnail, media_id, parent_id, position, status, updated_at, created_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
-	res, err := m.db.ExecContext(ctx, query, c.Title, c.Description, c.Thumbnail, c.Status, c.UpdatedAt, c.CreatedAt)
+	res, err := conn(ctx, m.db).ExecContext(ctx, query, c.Title, c.Description, c.Thumbnail, c.MediaID, c.ParentID, c.Position, c.Status, c.UpdatedAt, c.CreatedAt)
 
 	if err != nil {
 		return err
@@ -85,25 +144,205 @@ func (m *mysqlCateRepo) Store(ctx context.Context, c *domain.Category) error {
 }
 
 func (m *mysqlCateRepo) Update(ctx context.Context, c *domain.Category) error {
+	defer observeQuery("category", "Update")()
 	query := `UPDATE categories SET
 				title = ?,
 				description = ?,
 				thumbnail = ?,
+				media_id = ?,
+				parent_id = ?,
+				position = ?,
 				status = ?,
 				updated_at = ?
 				WHERE id = ?`
 
-	_, err := m.db.ExecContext(ctx, query, c.Title, c.Description, c.Thumbnail, c.Status, c.UpdatedAt, c.ID)
+	_, err := conn(ctx, m.db).ExecContext(ctx, query, c.Title, c.Description, c.Thumbnail, c.MediaID, c.ParentID, c.Position, c.Status, c.UpdatedAt, c.ID)
 
 	return err
 }
 
 func (m *mysqlCateRepo) Delete(ctx context.Context, id int64) error {
+	defer observeQuery("category", "Delete")()
+	// previous_status được gán trước status vì MySQL đánh giá SET từ trái sang phải
 	query := `UPDATE categories SET
-				status = ?
-				WHERE id = ?`
+				previous_status = status,
+				status = ?,
+				deleted_at = ?
+				WHERE id = ?
+				AND status != ?`
+
+	_, err := conn(ctx, m.db).ExecContext(ctx, query, domain.CategoryStatusInactive, time.Now(), id, domain.CategoryStatusInactive)
+
+	return err
+}
+
+func (m *mysqlCateRepo) FetchAll(ctx context.Context) ([]domain.Category, error) {
+	defer observeQuery("category", "FetchAll")()
+	query := `SELECT ` + categoryColumns + `
+				FROM categories
+				WHERE status != ?
+				ORDER BY position, title`
+
+	return m.fetch(ctx, query, 0, domain.CategoryStatusInactive)
+}
+
+func (m *mysqlCateRepo) FetchChildren(ctx context.Context, parentID *int64) ([]domain.Category, error) {
+	defer observeQuery("category", "FetchChildren")()
+	// <=> là phép so sánh NULL-safe, parentID nil sẽ khớp các danh mục gốc
+	query := `SELECT ` + categoryColumns + `
+				FROM categories
+				WHERE parent_id <=> ?
+				AND status != ?
+				ORDER BY position, title`
+
+	return m.fetch(ctx, query, 0, parentID, domain.CategoryStatusInactive)
+}
+
+func (m *mysqlCateRepo) NextPosition(ctx context.Context, parentID *int64) (int, error) {
+	defer observeQuery("category", "NextPosition")()
+	query := `SELECT COALESCE(MAX(position), 0) + 1
+				FROM categories
+				WHERE parent_id <=> ?
+				AND status != ?`
+
+	var next int
+	err := conn(ctx, m.db).QueryRowContext(ctx, query, parentID, domain.CategoryStatusInactive).Scan(&next)
+	return next, err
+}
+
+func (m *mysqlCateRepo) Reorder(ctx context.Context, parentID *int64, ids []int64) error {
+	defer observeQuery("category", "Reorder")()
+	query := `UPDATE categories SET
+				position = ?,
+				updated_at = ?
+				WHERE id = ?
+				AND parent_id <=> ?`
+
+	now := time.Now()
+	return withTx(ctx, m.db, func(tx *sql.Tx) error {
+		stmt, err := tx.PrepareContext(ctx, query)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		for i, id := range ids {
+			res, err := stmt.ExecContext(ctx, i+1, now, id, parentID)
+			if err != nil {
+				return err
+			}
+			// Danh mục bị chuyển sang cha khác giữa chừng -> hủy toàn bộ để tránh thứ tự nửa vời
+			if n, err := res.RowsAffected(); err == nil && n == 0 {
+				return fmt.Errorf("%w: category %d is not a child of the given parent", domain.ErrInvalidReorder, id)
+			}
+		}
+		return nil
+	})
+}
+
+func (m *mysqlCateRepo) ReparentChildren(ctx context.Context, parentID int64, newParentID *int64) error {
+	defer observeQuery("category", "ReparentChildren")()
+	query := `UPDATE categories SET
+				parent_id = ?,
+				updated_at = ?
+				WHERE parent_id = ?`
+
+	_, err := conn(ctx, m.db).ExecContext(ctx, query, newParentID, time.Now(), parentID)
+
+	return err
+}
+
+func (m *mysqlCateRepo) FetchTrash(ctx context.Context, limit int64, offset int64) ([]domain.Category, error) {
+	defer observeQuery("category", "FetchTrash")()
+	query := `SELECT ` + categoryColumns + `
+				FROM categories
+				WHERE status = ?
+				AND deleted_at IS NOT NULL
+				ORDER BY deleted_at DESC
+				LIMIT ? OFFSET ?`
+
+	return m.fetch(ctx, query, limit, domain.CategoryStatusInactive, limit, offset)
+}
+
+func (m *mysqlCateRepo) GetTrashedByID(ctx context.Context, id int64) (*domain.Category, error) {
+	defer observeQuery("category", "GetTrashedByID")()
+	query := `SELECT ` + categoryColumns + `
+				FROM categories
+				WHERE id = ?
+				AND status = ?
+				AND deleted_at IS NOT NULL`
+
+	c := &domain.Category{}
+	err := scanCategory(conn(ctx, m.db).QueryRowContext(ctx, query, id, domain.CategoryStatusInactive), c)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("category not found in trash")
+		}
+		return nil, err
+	}
+	return c, nil
+}
 
-	_, err := m.db.ExecContext(ctx, query, domain.CategoryStatusInactive, id)
+func (m *mysqlCateRepo) FetchTrashedBefore(ctx context.Context, cutoff time.Time, limit int64) ([]domain.Category, error) {
+	defer observeQuery("category", "FetchTrashedBefore")()
+	// Danh mục chỉ bị tắt (inactive nhưng chưa xóa) có deleted_at NULL và không bao giờ bị xóa vĩnh viễn
+	query := `SELECT ` + categoryColumns + `
+				FROM categories
+				WHERE status = ?
+				AND deleted_at IS NOT NULL
+				AND deleted_at < ?
+				ORDER BY id
+				LIMIT ?`
+
+	return m.fetch(ctx, query, limit, domain.CategoryStatusInactive, cutoff, limit)
+}
+
+func (m *mysqlCateRepo) Restore(ctx context.Context, id int64) error {
+	defer observeQuery("category", "Restore")()
+	query := `UPDATE categories SET
+				status = COALESCE(previous_status, ?),
+				previous_status = NULL,
+				deleted_at = NULL
+				WHERE id = ?
+				AND status = ?
+				AND deleted_at IS NOT NULL`
+
+	_, err := conn(ctx, m.db).ExecContext(ctx, query, domain.CategoryStatusActive, id, domain.CategoryStatusInactive)
 
 	return err
 }
+
+func (m *mysqlCateRepo) Purge(ctx context.Context, id int64) error {
+	defer observeQuery("category", "Purge")()
+	return withTx(ctx, m.db, func(tx *sql.Tx) error {
+		// Chỉ danh mục trong thùng rác (deleted_at khác NULL) bị xóa, danh mục chỉ bị tắt giữ nguyên cùng bài viết của nó
+		res, err := tx.ExecContext(ctx, `DELETE FROM categories WHERE id = ? AND status = ? AND deleted_at IS NOT NULL`, id, domain.CategoryStatusInactive)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil || n == 0 {
+			return err
+		}
+
+		// Gỡ liên kết để không còn bài viết trỏ tới danh mục không tồn tại
+		_, err = tx.ExecContext(ctx, `UPDATE posts SET category_id = NULL WHERE category_id = ?`, id)
+		return err
+	})
+}
+
+func (m *mysqlCateRepo) FetchByTitles(ctx context.Context, titles []string) ([]domain.Category, error) {
+	defer observeQuery("category", "FetchByTitles")()
+	if len(titles) == 0 {
+		return []domain.Category{}, nil
+	}
+
+	args := make([]any, len(titles))
+	for i, t := range titles {
+		args[i] = t
+	}
+	query := `SELECT ` + categoryColumns + `
+				FROM categories
+				WHERE title IN (` + strings.TrimSuffix(strings.Repeat("?, ", len(titles)), ", ") + `)`
+
+	return m.fetch(ctx, query, int64(len(titles)), args...)
+}
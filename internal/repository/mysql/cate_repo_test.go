@@ -0,0 +1,140 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"Test2/infrastructure/migrate"
+	"Test2/internal/domain"
+	"Test2/migrations"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+)
+
+// testDSNEnv trỏ tới database MySQL dùng riêng cho test (bị ghi dữ liệu), không đặt thì test repository bị bỏ qua
+const testDSNEnv = "TEST_MYSQL_DSN"
+
+// openTestCluster mở database test đã chạy mọi migration
+func openTestCluster(t *testing.T) *Cluster {
+	t.Helper()
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDSNEnv)
+	}
+	cfg, err := mysqldriver.ParseDSN(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.ParseTime = true
+	cfg.MultiStatements = true
+
+	db, err := sql.Open("mysql", cfg.FormatDSN())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := migrate.New(db, migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(context.Background(), 0); err != nil {
+		t.Fatal(err)
+	}
+	return NewCluster(db, time.Second, time.Second)
+}
+
+func TestCategoryTrashExcludesDeactivated(t *testing.T) {
+	cluster := openTestCluster(t)
+	repo := NewMysqlCateRepository(cluster).(*mysqlCateRepo)
+	ctx := context.Background()
+
+	// Danh mục chỉ bị tắt: Inactive nhưng deleted_at NULL, có một bài viết thuộc danh mục
+	title := fmt.Sprintf("deactivated-%d", time.Now().UnixNano())
+	res, err := cluster.primary.ExecContext(ctx,
+		`INSERT INTO categories (title, status) VALUES (?, ?)`, title, domain.CategoryStatusInactive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, _ := res.LastInsertId()
+	res, err = cluster.primary.ExecContext(ctx,
+		`INSERT INTO posts (title, category_id) VALUES (?, ?)`, title, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	postID, _ := res.LastInsertId()
+	t.Cleanup(func() {
+		cluster.primary.Exec(`DELETE FROM posts WHERE id = ?`, postID)
+		cluster.primary.Exec(`DELETE FROM categories WHERE id = ?`, id)
+	})
+
+	tests := []struct {
+		name string
+		run  func() error
+	}{
+		{"GetTrashedByID does not find it", func() error {
+			if _, err := repo.GetTrashedByID(ctx, id); err == nil {
+				return errors.New("found in trash")
+			}
+			return nil
+		}},
+		{"GetTrashedByIDs does not return it", func() error {
+			got, err := repo.GetTrashedByIDs(ctx, []int64{id})
+			if err == nil && len(got) > 0 {
+				err = errors.New("found in trash")
+			}
+			return err
+		}},
+		{"FetchTrash does not list it", func() error {
+			got, err := repo.FetchTrash(ctx, 1000, 0)
+			for _, c := range got {
+				if c.ID == id {
+					return errors.New("listed in trash")
+				}
+			}
+			return err
+		}},
+		{"Restore leaves it inactive", func() error {
+			return repo.Restore(ctx, id)
+		}},
+		{"RestoreBatch reports a conflict", func() error {
+			err := repo.RestoreBatch(ctx, []*domain.Category{{ID: id}})
+			if !errors.Is(err, domain.ErrBatchConflict) {
+				return fmt.Errorf("RestoreBatch() error = %v, want ErrBatchConflict", err)
+			}
+			return nil
+		}},
+		{"Purge keeps it and its posts", func() error {
+			return repo.Purge(ctx, id)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.run(); err != nil {
+				t.Fatal(err)
+			}
+
+			var status string
+			var deletedAt sql.NullTime
+			err := cluster.primary.QueryRowContext(ctx, `SELECT status, deleted_at FROM categories WHERE id = ?`, id).Scan(&status, &deletedAt)
+			if err != nil {
+				t.Fatalf("category is gone: %v", err)
+			}
+			if status != domain.CategoryStatusInactive || deletedAt.Valid {
+				t.Errorf("category = (%s, %v), want still deactivated", status, deletedAt)
+			}
+			var categoryID sql.NullInt64
+			if err := cluster.primary.QueryRowContext(ctx, `SELECT category_id FROM posts WHERE id = ?`, postID).Scan(&categoryID); err != nil {
+				t.Fatal(err)
+			}
+			if categoryID.Int64 != id {
+				t.Errorf("post category_id = %v, want %d", categoryID, id)
+			}
+		})
+	}
+}
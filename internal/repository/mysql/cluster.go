@@ -0,0 +1,239 @@
+package mysql
+
+import (
+	"Test2/internal/domain"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// mysqlErrParse là mã lỗi ER_PARSE_ERROR, MySQL trước 8.0.22 không hiểu SHOW REPLICA STATUS
+const mysqlErrParse = 1064
+
+var (
+	replicaLagGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "cms_db_replica_lag_seconds",
+		Help: "Độ trễ replication đo được của MySQL replica (Seconds_Behind_Source).",
+	}, []string{"replica"})
+	replicaHealthyGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "cms_db_replica_healthy",
+		Help: "1 khi MySQL replica nằm trong vòng chia tải đọc, 0 khi bị gỡ (trễ, replication dừng hoặc không phản hồi).",
+	}, []string{"replica"})
+	readsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cms_db_reads_total",
+		Help: "Số truy vấn đọc theo nơi phục vụ: replica, primary (request đã ghi) hoặc fallback (không còn replica khỏe).",
+	}, []string{"target"})
+)
+
+// Cluster gồm primary nhận mọi câu lệnh ghi và transaction cùng các replica chia tải đọc (round-robin).
+// Replica trễ quá maxLag, dừng replication hoặc không phản hồi bị gỡ khỏi vòng tới lần kiểm tra sau,
+// khi không còn replica nào khỏe thì đọc chuyển về primary. Không có replica thì mọi truy vấn đi tới primary.
+type Cluster struct {
+	primary       *sql.DB
+	replicas      []*replica
+	maxLag        time.Duration
+	checkInterval time.Duration
+	next          atomic.Uint64
+}
+
+type replica struct {
+	name    string
+	db      *sql.DB
+	healthy atomic.Bool
+
+	mu        sync.Mutex
+	lag       *time.Duration
+	checkedAt time.Time
+	err       error
+}
+
+func NewCluster(primary *sql.DB, maxLag, checkInterval time.Duration) *Cluster {
+	return &Cluster{primary: primary, maxLag: maxLag, checkInterval: checkInterval}
+}
+
+// AddReplica thêm replica vào cluster, replica chỉ nhận truy vấn sau lần kiểm tra độ trễ đầu tiên đạt yêu cầu.
+// Chỉ gọi trước khi Cluster được dùng.
+func (c *Cluster) AddReplica(name string, db *sql.DB) {
+	c.replicas = append(c.replicas, &replica{name: name, db: db})
+	replicaHealthyGauge.WithLabelValues(name).Set(0)
+}
+
+func (c *Cluster) HasReplicas() bool {
+	return len(c.replicas) > 0
+}
+
+// reader chọn replica khỏe kế tiếp theo round-robin, nil khi không còn replica nào khỏe
+func (c *Cluster) reader() *replica {
+	n := uint64(len(c.replicas))
+	if n == 0 {
+		return nil
+	}
+	start := c.next.Add(1)
+	for i := range n {
+		if r := c.replicas[(start+i)%n]; r.healthy.Load() {
+			return r
+		}
+	}
+	return nil
+}
+
+// CheckReplicas đo độ trễ của mọi replica song song và cập nhật vòng chia tải đọc
+func (c *Cluster) CheckReplicas(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, r := range c.replicas {
+		wg.Go(func() { c.check(ctx, r) })
+	}
+	wg.Wait()
+}
+
+// Run kiểm tra replica mỗi checkInterval tới khi ctx bị hủy
+func (c *Cluster) Run(ctx context.Context) {
+	if !c.HasReplicas() {
+		return
+	}
+	ticker := time.NewTicker(c.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.CheckReplicas(ctx)
+		}
+	}
+}
+
+func (c *Cluster) check(ctx context.Context, r *replica) {
+	cctx, cancel := context.WithTimeout(ctx, c.checkInterval)
+	defer cancel()
+
+	lag, err := replicationLag(cctx, r.db)
+	if err == nil && lag > c.maxLag {
+		err = fmt.Errorf("replication lag %s exceeds %s", lag, c.maxLag)
+	}
+	if ctx.Err() != nil {
+		// Đang shutdown, giữ nguyên trạng thái
+		return
+	}
+
+	r.mu.Lock()
+	first := r.checkedAt.IsZero()
+	r.lag = nil
+	if err == nil || lag > c.maxLag {
+		r.lag = &lag
+		replicaLagGauge.WithLabelValues(r.name).Set(lag.Seconds())
+	}
+	r.checkedAt = time.Now().UTC()
+	r.err = err
+	r.mu.Unlock()
+
+	healthy := err == nil
+	if r.healthy.Swap(healthy) == healthy {
+		if first && !healthy {
+			slog.Warn("MySQL replica not added to read rotation", "replica", r.name, "error", err)
+		}
+		return
+	}
+	if healthy {
+		replicaHealthyGauge.WithLabelValues(r.name).Set(1)
+		slog.Info("MySQL replica added to read rotation", "replica", r.name, "lag_seconds", lag.Seconds())
+	} else {
+		replicaHealthyGauge.WithLabelValues(r.name).Set(0)
+		slog.Warn("MySQL replica removed from read rotation", "replica", r.name, "error", err)
+	}
+}
+
+// replicationLag đọc Seconds_Behind_Source (Seconds_Behind_Master trước MySQL 8.0.22), lấy kênh trễ nhất
+// khi replica có nhiều nguồn. Tài khoản cần quyền REPLICATION CLIENT.
+func replicationLag(ctx context.Context, db *sql.DB) (time.Duration, error) {
+	rows, err := db.QueryContext(ctx, `SHOW REPLICA STATUS`)
+	var me *mysqldriver.MySQLError
+	if errors.As(err, &me) && me.Number == mysqlErrParse {
+		rows, err = db.QueryContext(ctx, `SHOW SLAVE STATUS`)
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+	lagColumn := -1
+	for i, name := range columns {
+		if name == "Seconds_Behind_Source" || name == "Seconds_Behind_Master" {
+			lagColumn = i
+		}
+	}
+	if lagColumn < 0 {
+		return 0, errors.New("replica status has no Seconds_Behind_Source column")
+	}
+
+	values := make([]sql.NullString, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	var lag time.Duration
+	channels := 0
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return 0, err
+		}
+		channels++
+		// NULL: luồng IO hoặc SQL của replication không chạy
+		if !values[lagColumn].Valid {
+			return 0, errors.New("replication is not running")
+		}
+		seconds, err := strconv.ParseInt(values[lagColumn].String, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("replica status: %w", err)
+		}
+		lag = max(lag, time.Duration(seconds)*time.Second)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if channels == 0 {
+		return 0, errors.New("server is not a replica")
+	}
+	return lag, nil
+}
+
+// Replicas trả về trạng thái các replica theo thứ tự cấu hình
+func (c *Cluster) Replicas() []domain.ReplicaStatus {
+	result := make([]domain.ReplicaStatus, 0, len(c.replicas))
+	for _, r := range c.replicas {
+		status := domain.ReplicaStatus{Name: r.name, Healthy: r.healthy.Load()}
+
+		r.mu.Lock()
+		if r.lag != nil {
+			seconds := r.lag.Seconds()
+			status.LagSeconds = &seconds
+		}
+		if !r.checkedAt.IsZero() {
+			checkedAt := r.checkedAt
+			status.CheckedAt = &checkedAt
+		}
+		if r.err != nil {
+			status.Error = r.err.Error()
+		}
+		r.mu.Unlock()
+
+		result = append(result, status)
+	}
+	return result
+}
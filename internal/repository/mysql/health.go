@@ -0,0 +1,80 @@
+package mysql
+
+import (
+	"Test2/internal/domain"
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// mysqlHealth kiểm tra kết nối MySQL và báo cáo thống kê connection pool
+type mysqlHealth struct {
+	db *sql.DB
+}
+
+// NewMysqlHealthCheck trả về kiểu vừa là HealthCheck vừa là HealthDetailer
+func NewMysqlHealthCheck(db *sql.DB) interface {
+	domain.HealthCheck
+	domain.HealthDetailer
+} {
+	return &mysqlHealth{db}
+}
+
+func (m *mysqlHealth) Name() string {
+	return "mysql"
+}
+
+func (m *mysqlHealth) Check(ctx context.Context) error {
+	return m.db.PingContext(ctx)
+}
+
+func (m *mysqlHealth) Details(ctx context.Context) (any, error) {
+	return m.db.Stats(), nil
+}
+
+// replicaHealth báo cáo vòng chia tải đọc. Replica không bắt buộc: khi không còn replica nào khỏe,
+// đọc chuyển về primary và instance ở trạng thái degraded.
+type replicaHealth struct {
+	cluster *Cluster
+}
+
+// replicaDetails là trạng thái kèm thống kê connection pool của một replica
+type replicaDetails struct {
+	domain.ReplicaStatus
+	Pool sql.DBStats `json:"pool"`
+}
+
+func NewMysqlReplicaHealthCheck(cluster *Cluster) interface {
+	domain.OptionalHealthCheck
+	domain.HealthDetailer
+} {
+	return &replicaHealth{cluster}
+}
+
+func (m *replicaHealth) Name() string {
+	return "mysql_replicas"
+}
+
+func (m *replicaHealth) Optional() bool {
+	return true
+}
+
+// Check dùng kết quả của lần kiểm tra độ trễ gần nhất thay vì ping lại từng replica
+func (m *replicaHealth) Check(ctx context.Context) error {
+	statuses := m.cluster.Replicas()
+	for _, s := range statuses {
+		if s.Healthy {
+			return nil
+		}
+	}
+	return fmt.Errorf("none of %d replicas is in read rotation, reading from primary", len(statuses))
+}
+
+func (m *replicaHealth) Details(ctx context.Context) (any, error) {
+	statuses := m.cluster.Replicas()
+	details := make([]replicaDetails, len(statuses))
+	for i, s := range statuses {
+		details[i] = replicaDetails{ReplicaStatus: s, Pool: m.cluster.replicas[i].db.Stats()}
+	}
+	return details, nil
+}
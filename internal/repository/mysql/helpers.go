@@ -0,0 +1,45 @@
+package mysql
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// mysqlErrDuplicateEntry là mã lỗi ER_DUP_ENTRY khi vi phạm khóa UNIQUE
+const mysqlErrDuplicateEntry = 1062
+
+// nullIfEmpty lưu chuỗi rỗng thành NULL (cột UNIQUE cho phép nhiều NULL nhưng không cho nhiều "")
+func nullIfEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// nullInt64Ptr chuyển cột NULL-able sang *int64 (nil khi NULL)
+func nullInt64Ptr(v sql.NullInt64) *int64 {
+	if !v.Valid {
+		return nil
+	}
+	id := v.Int64
+	return &id
+}
+
+// nullTimePtr chuyển cột DATETIME NULL-able sang *time.Time (nil khi NULL)
+func nullTimePtr(v sql.NullTime) *time.Time {
+	if !v.Valid {
+		return nil
+	}
+	t := v.Time
+	return &t
+}
+
+// inPlaceholders sinh chuỗi "?, ?, ?" cho mệnh đề IN (...) cùng danh sách tham số
+func inPlaceholders(ids []int64) (string, []any) {
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", "), args
+}
@@ -0,0 +1,57 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// loggedConn ghi mỗi câu lệnh ở mức debug kèm nơi chạy (primary, replica hoặc transaction), thời gian và lỗi.
+// request_id được logger mặc định lấy từ ctx.
+type loggedConn struct {
+	dbExecutor
+	target string
+}
+
+// withLogging chỉ bọc executor khi mức debug đang bật, không tốn chi phí ở mức log thường
+func withLogging(ctx context.Context, exec dbExecutor, target string) dbExecutor {
+	if !slog.Default().Enabled(ctx, slog.LevelDebug) {
+		return exec
+	}
+	return loggedConn{exec, target}
+}
+
+func (c loggedConn) log(ctx context.Context, query string, start time.Time, err error) {
+	attrs := []slog.Attr{
+		slog.String("db", c.target),
+		slog.String("query", strings.Join(strings.Fields(query), " ")),
+		slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+	slog.LogAttrs(ctx, slog.LevelDebug, "mysql query", attrs...)
+}
+
+func (c loggedConn) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	start := time.Now()
+	res, err := c.dbExecutor.ExecContext(ctx, query, args...)
+	c.log(ctx, query, start, err)
+	return res, err
+}
+
+func (c loggedConn) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := c.dbExecutor.QueryContext(ctx, query, args...)
+	c.log(ctx, query, start, err)
+	return rows, err
+}
+
+func (c loggedConn) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	start := time.Now()
+	row := c.dbExecutor.QueryRowContext(ctx, query, args...)
+	c.log(ctx, query, start, row.Err())
+	return row
+}
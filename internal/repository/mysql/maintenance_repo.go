@@ -0,0 +1,206 @@
+package mysql
+
+import (
+	"Test2/internal/domain"
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+func NewMysqlMaintenanceRepository(db *Cluster) domain.MaintenanceRepository {
+	return &mysqlMaintenanceRepo{db}
+}
+
+type mysqlMaintenanceRepo struct {
+	db *Cluster
+}
+
+// consistencyCheck là một truy vấn trả về (id của bản ghi lỗi, id được tham chiếu hoặc NULL)
+type consistencyCheck struct {
+	name   string
+	entity string
+	query  string
+	args   []any
+	detail string // Định dạng với id được tham chiếu
+}
+
+var consistencyChecks = []consistencyCheck{
+	{
+		name:   "post_category_missing",
+		entity: domain.AuditEntityPost,
+		query: `SELECT p.id, p.category_id FROM posts p
+				LEFT JOIN categories c ON c.id = p.category_id
+				WHERE p.category_id IS NOT NULL AND c.id IS NULL`,
+		detail: "category %d does not exist",
+	},
+	{
+		name:   "post_in_trashed_category",
+		entity: domain.AuditEntityPost,
+		query: `SELECT p.id, p.category_id FROM posts p
+				JOIN categories c ON c.id = p.category_id
+				WHERE p.status = ? AND c.deleted_at IS NOT NULL`,
+		args:   []any{domain.StatusPublished},
+		detail: "published post belongs to category %d which is in the trash",
+	},
+	{
+		name:   "post_media_missing",
+		entity: domain.AuditEntityPost,
+		query: `SELECT p.id, p.media_id FROM posts p
+				LEFT JOIN media m ON m.id = p.media_id
+				WHERE p.media_id IS NOT NULL AND m.id IS NULL`,
+		detail: "media %d does not exist",
+	},
+	{
+		name:   "post_trash_state",
+		entity: domain.AuditEntityPost,
+		query: `SELECT id, NULL FROM posts
+				WHERE (status = ?) != (deleted_at IS NOT NULL)`,
+		args:   []any{domain.StatusDeleted},
+		detail: "status and deleted_at disagree",
+	},
+	{
+		name:   "category_parent_missing",
+		entity: domain.AuditEntityCategory,
+		query: `SELECT c.id, c.parent_id FROM categories c
+				LEFT JOIN categories p ON p.id = c.parent_id
+				WHERE c.parent_id IS NOT NULL AND p.id IS NULL`,
+		detail: "parent category %d does not exist",
+	},
+	{
+		name:   "category_media_missing",
+		entity: domain.AuditEntityCategory,
+		query: `SELECT c.id, c.media_id FROM categories c
+				LEFT JOIN media m ON m.id = c.media_id
+				WHERE c.media_id IS NOT NULL AND m.id IS NULL`,
+		detail: "media %d does not exist",
+	},
+	{
+		name:   "category_trash_state",
+		entity: domain.AuditEntityCategory,
+		query: `SELECT id, NULL FROM categories
+				WHERE deleted_at IS NOT NULL AND status != ?`,
+		args:   []any{domain.CategoryStatusInactive},
+		detail: "category is in the trash but not inactive",
+	},
+}
+
+// categoryCycleCheck được kiểm tra trong Go vì cần đi theo chuỗi parent_id
+const categoryCycleCheck = "category_cycle"
+
+func (m *mysqlMaintenanceRepo) RebuildSearchIndex(ctx context.Context) error {
+	defer observeQuery("maintenance", "RebuildSearchIndex")()
+	// Với InnoDB, OPTIMIZE TABLE dựng lại bảng (recreate + analyze) kể cả FULLTEXT index idx_fts_search
+	rows, err := m.db.primary.QueryContext(ctx, `OPTIMIZE TABLE posts`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var table, op, msgType, msgText string
+		if err := rows.Scan(&table, &op, &msgType, &msgText); err != nil {
+			return err
+		}
+		if strings.EqualFold(msgType, "error") {
+			return fmt.Errorf("optimize %s: %s", table, msgText)
+		}
+	}
+	return rows.Err()
+}
+
+func (m *mysqlMaintenanceRepo) CheckConsistency(ctx context.Context) ([]string, []domain.ConsistencyIssue, error) {
+	defer observeQuery("maintenance", "CheckConsistency")()
+	checks := make([]string, 0, len(consistencyChecks)+1)
+	issues := make([]domain.ConsistencyIssue, 0)
+
+	for _, check := range consistencyChecks {
+		found, err := m.runCheck(ctx, check)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", check.name, err)
+		}
+		checks = append(checks, check.name)
+		issues = append(issues, found...)
+	}
+
+	cycles, err := m.categoryCycles(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", categoryCycleCheck, err)
+	}
+	checks = append(checks, categoryCycleCheck)
+	issues = append(issues, cycles...)
+
+	return checks, issues, nil
+}
+
+func (m *mysqlMaintenanceRepo) runCheck(ctx context.Context, check consistencyCheck) ([]domain.ConsistencyIssue, error) {
+	rows, err := m.db.primary.QueryContext(ctx, check.query, check.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	issues := make([]domain.ConsistencyIssue, 0)
+	for rows.Next() {
+		var id int64
+		var ref sql.NullInt64
+		if err := rows.Scan(&id, &ref); err != nil {
+			return nil, err
+		}
+		detail := check.detail
+		if ref.Valid {
+			detail = fmt.Sprintf(check.detail, ref.Int64)
+		}
+		issues = append(issues, domain.ConsistencyIssue{Check: check.name, Entity: check.entity, EntityID: id, Detail: detail})
+	}
+	return issues, rows.Err()
+}
+
+// categoryCycles tìm các danh mục nằm trong vòng lặp parent_id (dữ liệu sửa tay có thể tạo ra)
+func (m *mysqlMaintenanceRepo) categoryCycles(ctx context.Context) ([]domain.ConsistencyIssue, error) {
+	rows, err := m.db.primary.QueryContext(ctx, `SELECT id, parent_id FROM categories WHERE parent_id IS NOT NULL`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	parents := make(map[int64]int64)
+	for rows.Next() {
+		var id, parentID int64
+		if err := rows.Scan(&id, &parentID); err != nil {
+			return nil, err
+		}
+		parents[id] = parentID
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	issues := make([]domain.ConsistencyIssue, 0)
+	reported := make(map[int64]bool)
+	for start := range parents {
+		// Đi theo parent tới gốc, gặp lại một nút trên đường đi nghĩa là có vòng lặp
+		seen := map[int64]bool{start: true}
+		for id, ok := parents[start]; ok; id, ok = parents[id] {
+			if id == start {
+				if !reported[start] {
+					reported[start] = true
+					issues = append(issues, domain.ConsistencyIssue{
+						Check:    categoryCycleCheck,
+						Entity:   domain.AuditEntityCategory,
+						EntityID: start,
+						Detail:   "parent chain loops back to this category",
+					})
+				}
+				break
+			}
+			if seen[id] {
+				break // Vòng lặp phía trên, không chứa start
+			}
+			seen[id] = true
+		}
+	}
+	sort.Slice(issues, func(i, j int) bool { return issues[i].EntityID < issues[j].EntityID })
+	return issues, nil
+}
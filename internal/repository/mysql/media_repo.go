@@ -0,0 +1,217 @@
+package mysql
+
+import (
+	"Test2/internal/domain"
+	"context"
+	"database/sql"
+	"encoding/json"
+)
+
+const mediaColumns = `id, file_name, mime_type, size, width, height, storage_key, variants, created_at`
+
+func NewMysqlMediaRepository(db *Cluster) domain.MediaRepository {
+	return &mysqlMediaRepo{db}
+}
+
+type mysqlMediaRepo struct {
+	db *Cluster
+}
+
+// mediaVariantRecord là dạng lưu trong cột JSON `variants` (giữ lại storage key, không lưu URL)
+type mediaVariantRecord struct {
+	Name       string `json:"name"`
+	StorageKey string `json:"storage_key"`
+	Width      int    `json:"width"`
+	Height     int    `json:"height"`
+	Size       int64  `json:"size"`
+}
+
+func scanMedia(s rowScanner, md *domain.Media) error {
+	var variants []byte
+	err := s.Scan(&md.ID, &md.FileName, &md.MimeType, &md.Size, &md.Width, &md.Height, &md.StorageKey, &variants, &md.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	var records []mediaVariantRecord
+	if len(variants) > 0 {
+		if err := json.Unmarshal(variants, &records); err != nil {
+			return err
+		}
+	}
+
+	md.Variants = make([]domain.MediaVariant, 0, len(records))
+	for _, r := range records {
+		md.Variants = append(md.Variants, domain.MediaVariant{
+			Name:       r.Name,
+			StorageKey: r.StorageKey,
+			Width:      r.Width,
+			Height:     r.Height,
+			Size:       r.Size,
+		})
+	}
+	return nil
+}
+
+func (m *mysqlMediaRepo) list(ctx context.Context, query string, capacity int, args ...any) ([]domain.Media, error) {
+	rows, err := readConn(ctx, m.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]domain.Media, 0, capacity)
+	for rows.Next() {
+		md := domain.Media{}
+		if err := scanMedia(rows, &md); err != nil {
+			return nil, err
+		}
+		result = append(result, md)
+	}
+	return result, rows.Err()
+}
+
+func (m *mysqlMediaRepo) Fetch(ctx context.Context, limit int64, offset int64) ([]domain.Media, error) {
+	defer observeQuery("media", "Fetch")()
+	query := `SELECT ` + mediaColumns + `
+				FROM media
+				ORDER BY created_at DESC
+				LIMIT ? OFFSET ?`
+
+	return m.list(ctx, query, int(limit), limit, offset)
+}
+
+func (m *mysqlMediaRepo) GetByID(ctx context.Context, id int64) (*domain.Media, error) {
+	defer observeQuery("media", "GetByID")()
+	query := `SELECT ` + mediaColumns + `
+				FROM media
+				WHERE id = ?`
+
+	md := &domain.Media{}
+	err := scanMedia(readConn(ctx, m.db).QueryRowContext(ctx, query, id), md)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrMediaNotFound
+		}
+		return nil, err
+	}
+	return md, nil
+}
+
+func (m *mysqlMediaRepo) GetByIDs(ctx context.Context, ids []int64) ([]domain.Media, error) {
+	defer observeQuery("media", "GetByIDs")()
+	if len(ids) == 0 {
+		return []domain.Media{}, nil
+	}
+
+	placeholders, args := inPlaceholders(ids)
+	query := `SELECT ` + mediaColumns + `
+				FROM media
+				WHERE id IN (` + placeholders + `)`
+
+	return m.list(ctx, query, len(ids), args...)
+}
+
+func (m *mysqlMediaRepo) Store(ctx context.Context, md *domain.Media) error {
+	defer observeQuery("media", "Store")()
+	records := make([]mediaVariantRecord, 0, len(md.Variants))
+	for _, v := range md.Variants {
+		records = append(records, mediaVariantRecord{
+			Name:       v.Name,
+			StorageKey: v.StorageKey,
+			Width:      v.Width,
+			Height:     v.Height,
+			Size:       v.Size,
+		})
+	}
+
+	variants, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO media (file_name, mime_type, size, width, height, storage_key, variants, created_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	res, err := conn(ctx, m.db).ExecContext(ctx, query, md.FileName, md.MimeType, md.Size, md.Width, md.Height, md.StorageKey, variants, md.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	md.ID = id
+	return nil
+}
+
+// Delete xóa cứng metadata, tệp vật lý do UseCase xóa qua MediaStorage.
+// Bài viết và danh mục đang dùng media được gỡ media_id và thumbnail trong cùng transaction.
+func (m *mysqlMediaRepo) Delete(ctx context.Context, id int64) (*domain.MediaRefs, error) {
+	defer observeQuery("media", "Delete")()
+	refs := &domain.MediaRefs{}
+	err := withTx(ctx, m.db, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, `SELECT id, category_id FROM posts WHERE media_id = ? FOR UPDATE`, id)
+		if err != nil {
+			return err
+		}
+		seen := make(map[int64]bool)
+		for rows.Next() {
+			var (
+				postID     int64
+				categoryID sql.NullInt64
+			)
+			if err := rows.Scan(&postID, &categoryID); err != nil {
+				rows.Close()
+				return err
+			}
+			refs.PostIDs = append(refs.PostIDs, postID)
+			if categoryID.Valid && !seen[categoryID.Int64] {
+				seen[categoryID.Int64] = true
+				refs.PostCategoryIDs = append(refs.PostCategoryIDs, categoryID.Int64)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		if refs.CategoryIDs, err = selectIDs(ctx, tx, `SELECT id FROM categories WHERE media_id = ? FOR UPDATE`, id); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `UPDATE posts SET media_id = NULL, thumbnail = NULL WHERE media_id = ?`, id); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE categories SET media_id = NULL, thumbnail = NULL WHERE media_id = ?`, id); err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `DELETE FROM media WHERE id = ?`, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return refs, nil
+}
+
+// selectIDs đọc cột id đầu tiên của truy vấn
+func selectIDs(ctx context.Context, tx *sql.Tx, query string, args ...any) ([]int64, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,24 @@
+package mysql
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var queryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "cms_db_query_duration_seconds",
+	Help:    "Thời gian chạy một phương thức của MySQL repository (gồm mọi câu lệnh và transaction của phương thức).",
+	Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+}, []string{"repository", "method"})
+
+// observeQuery bắt đầu đo một phương thức repository, dùng: defer observeQuery("post", "Fetch")().
+// Phương thức gọi callback trong lúc duyệt kết quả (Export, Dispatch) không được đo vì thời gian
+// chủ yếu thuộc về callback.
+func observeQuery(repository, method string) func() {
+	start := time.Now()
+	return func() {
+		queryDuration.WithLabelValues(repository, method).Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,126 @@
+package mysql
+
+import (
+	"Test2/internal/domain"
+	"context"
+	"database/sql"
+	"strings"
+)
+
+// outboxRelayLock là named lock (GET_LOCK) bảo đảm chỉ một relay giao event tại một thời điểm,
+// nhờ đó event được giao đúng thứ tự id kể cả khi chạy nhiều instance
+const outboxRelayLock = "outbox_relay"
+
+const outboxColumns = `id, event_type, aggregate_type, aggregate_id, payload, occurred_at`
+
+func NewMysqlOutboxRepository(db *Cluster) domain.OutboxRepository {
+	return &mysqlOutboxRepo{db}
+}
+
+type mysqlOutboxRepo struct {
+	db *Cluster
+}
+
+func (m *mysqlOutboxRepo) Append(ctx context.Context, events []domain.DomainEvent) error {
+	defer observeQuery("outbox", "Append")()
+	if len(events) == 0 {
+		return nil
+	}
+
+	values := strings.TrimSuffix(strings.Repeat("(?, ?, ?, ?, ?), ", len(events)), ", ")
+	query := `INSERT INTO outbox (event_type, aggregate_type, aggregate_id, payload, occurred_at)
+				VALUES ` + values
+
+	args := make([]any, 0, len(events)*5)
+	for _, e := range events {
+		args = append(args, e.Type, e.AggregateType, e.AggregateID, []byte(e.Payload), e.OccurredAt)
+	}
+
+	res, err := conn(ctx, m.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	firstID, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	for i := range events {
+		events[i].ID = firstID + int64(i)
+	}
+	return nil
+}
+
+func (m *mysqlOutboxRepo) Dispatch(ctx context.Context, limit int, publish func(ctx context.Context, events []domain.DomainEvent) (int, error)) (int, error) {
+	// GET_LOCK gắn với connection nên phải giữ một connection riêng trong suốt lần dispatch
+	c, err := m.db.primary.Conn(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer c.Close()
+
+	var locked sql.NullInt64
+	if err := c.QueryRowContext(ctx, `SELECT GET_LOCK(?, 0)`, outboxRelayLock).Scan(&locked); err != nil {
+		return 0, err
+	}
+	if !locked.Valid || locked.Int64 != 1 {
+		return 0, domain.ErrOutboxBusy
+	}
+	// Dùng context riêng để vẫn nhả được khóa khi ctx đã bị hủy
+	defer c.ExecContext(context.WithoutCancel(ctx), `SELECT RELEASE_LOCK(?)`, outboxRelayLock)
+
+	// Đọc không khóa chỉ thấy các transaction đã commit. ID được cấp lúc insert chứ không phải lúc commit,
+	// nên event có ID nhỏ hơn có thể xuất hiện sau khi event ID lớn hơn đã được giao: lô chỉ theo thứ tự id
+	// của những gì đã commit, không bảo đảm thứ tự toàn cục hay theo bản ghi. Relay theo dõi từng ID đã giao
+	// nên event commit muộn vẫn tới mọi sink.
+	query := `SELECT ` + outboxColumns + `
+				FROM outbox
+				ORDER BY id
+				LIMIT ?`
+
+	rows, err := c.QueryContext(ctx, query, limit)
+	if err != nil {
+		return 0, err
+	}
+	events := make([]domain.DomainEvent, 0, limit)
+	for rows.Next() {
+		e := domain.DomainEvent{}
+		var payload []byte
+		if err := rows.Scan(&e.ID, &e.Type, &e.AggregateType, &e.AggregateID, &payload, &e.OccurredAt); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		e.Payload = payload
+		events = append(events, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	delivered, pubErr := publish(ctx, events)
+	delivered = max(0, min(delivered, len(events)))
+
+	// Xóa phần đã giao kể cả khi ctx bị hủy giữa chừng, tránh giao lại không cần thiết
+	wctx := context.WithoutCancel(ctx)
+	if delivered > 0 {
+		ids := make([]int64, delivered)
+		for i := range delivered {
+			ids[i] = events[i].ID
+		}
+		placeholders, args := inPlaceholders(ids)
+		if _, err := c.ExecContext(wctx, `DELETE FROM outbox WHERE id IN (`+placeholders+`)`, args...); err != nil {
+			return 0, err
+		}
+	}
+
+	if pubErr != nil && delivered < len(events) {
+		_, _ = c.ExecContext(wctx, `UPDATE outbox SET attempts = attempts + 1, last_error = ? WHERE id = ?`,
+			pubErr.Error(), events[delivered].ID)
+		return delivered, pubErr
+	}
+	return delivered, nil
+}
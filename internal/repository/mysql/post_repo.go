@@ -4,25 +4,58 @@ import (
 	"Test2/internal/domain"
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
 )
 
-func NewMysqlPostRepository(db *sql.DB) domain.PostRepository {
+// postColumns là danh sách cột dùng chung cho mọi câu SELECT, thứ tự phải khớp với scanPost
+const postColumns = `id, title, slug, description, content, content_format, thumbnail, media_id, category_id, status, publish_date, update_date, created_at, deleted_at, previous_status`
+
+func NewMysqlPostRepository(db *Cluster) domain.PostRepository {
 	return &mysqlPostRepo{db}
 }
 
 type mysqlPostRepo struct {
-	db *sql.DB
+	db *Cluster
 }
 
-func (m *mysqlPostRepo) Fetch(ctx context.Context, limit int64, offset int64) ([]domain.Post, error) {
-	query := `SELECT id, title, description, content, thumbnail, status, update_date, created_at
-			  FROM posts
-			  WHERE status != ?
-			  ORDER BY created_at DESC
-			  LIMIT ? OFFSET ?`
+// rowScanner được implement bởi cả *sql.Row và *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
 
-	rows, err := m.db.QueryContext(ctx, query, domain.StatusDeleted, limit, offset)
+// translatePostErr chuyển lỗi trùng slug sang lỗi nghiệp vụ
+func translatePostErr(err error) error {
+	var me *mysqldriver.MySQLError
+	if errors.As(err, &me) && me.Number == mysqlErrDuplicateEntry && strings.Contains(me.Message, "uq_slug") {
+		return fmt.Errorf("%w: %s", domain.ErrDuplicateSlug, me.Message)
+	}
+	return err
+}
+
+func scanPost(s rowScanner, p *domain.Post) error {
+	var mediaID, categoryID sql.NullInt64
+	var publishDate, deletedAt sql.NullTime
+	var slug, previousStatus sql.NullString
+	err := s.Scan(&p.ID, &p.Title, &slug, &p.Description, &p.Content, &p.ContentFormat, &p.Thumbnail, &mediaID, &categoryID, &p.Status, &publishDate, &p.UpdateDate, &p.CreatedAt, &deletedAt, &previousStatus)
+	if err != nil {
+		return err
+	}
+	p.Slug = slug.String
+	p.MediaID = nullInt64Ptr(mediaID)
+	p.CategoryID = nullInt64Ptr(categoryID)
+	p.PublishDate = nullTimePtr(publishDate)
+	p.DeletedAt = nullTimePtr(deletedAt)
+	p.PreviousStatus = previousStatus.String
+	return nil
+}
+
+func (m *mysqlPostRepo) fetch(ctx context.Context, query string, limit int64, args ...any) ([]domain.Post, error) {
+	rows, err := readConn(ctx, m.db).QueryContext(ctx, query, args...)
 
 	if err != nil {
 		return nil, err
@@ -34,25 +67,36 @@ func (m *mysqlPostRepo) Fetch(ctx context.Context, limit int64, offset int64) ([
 
 	for rows.Next() {
 		p := domain.Post{}
-		err := rows.Scan(&p.ID, &p.Title, &p.Description, &p.Content, &p.Thumbnail, &p.Status, &p.UpdateDate, &p.CreatedAt)
-		if err != nil {
+		if err := scanPost(rows, &p); err != nil {
 			return nil, err
 		}
 		result = append(result, p)
 	}
-	return result, nil
+	return result, rows.Err()
+}
+
+func (m *mysqlPostRepo) Fetch(ctx context.Context, limit int64, offset int64) ([]domain.Post, error) {
+	defer observeQuery("post", "Fetch")()
+	query := `SELECT ` + postColumns + `
+			  FROM posts
+			  WHERE status != ?
+			  ORDER BY created_at DESC
+			  LIMIT ? OFFSET ?`
+
+	return m.fetch(ctx, query, limit, domain.StatusDeleted, limit, offset)
 }
 
 func (m *mysqlPostRepo) GetByID(ctx context.Context, id int64) (*domain.Post, error) {
-	query := `SELECT id, title, description, content, thumbnail, status, update_date, created_at
+	defer observeQuery("post", "GetByID")()
+	query := `SELECT ` + postColumns + `
 				FROM posts
 				WHERE id = ?
 				AND status != ?`
 
-	row := m.db.QueryRowContext(ctx, query, id, domain.StatusDeleted)
+	row := readConn(ctx, m.db).QueryRowContext(ctx, query, id, domain.StatusDeleted)
 
 	p := &domain.Post{}
-	err := row.Scan(&p.ID, &p.Title, &p.Description, &p.Content, &p.Thumbnail, &p.Status, &p.UpdateDate, &p.CreatedAt)
+	err := scanPost(row, p)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -63,14 +107,34 @@ func (m *mysqlPostRepo) GetByID(ctx context.Context, id int64) (*domain.Post, er
 	return p, nil
 }
 
+func (m *mysqlPostRepo) GetByIDForUpdate(ctx context.Context, id int64) (*domain.Post, error) {
+	defer observeQuery("post", "GetByIDForUpdate")()
+	query := `SELECT ` + postColumns + `
+				FROM posts
+				WHERE id = ?
+				AND status != ?
+				FOR UPDATE`
+
+	p := &domain.Post{}
+	err := scanPost(conn(ctx, m.db).QueryRowContext(ctx, query, id, domain.StatusDeleted), p)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("post not found")
+		}
+		return nil, err
+	}
+	return p, nil
+}
+
 func (m *mysqlPostRepo) Store(ctx context.Context, p *domain.Post) error {
-	query := `INSERT INTO posts (title, description, content, thumbnail, status, update_date, created_at)
-				VALUES (?, ?, ?, ?, ?, ?, ?)`
+	defer observeQuery("post", "Store")()
+	query := `INSERT INTO posts (title, slug, description, content, content_format, thumbnail, media_id, category_id, status, publish_date, update_date, created_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
-	res, err := m.db.ExecContext(ctx, query, p.Title, p.Description, p.Content, p.Thumbnail, p.Status, p.UpdateDate, p.CreatedAt)
+	res, err := conn(ctx, m.db).ExecContext(ctx, query, p.Title, nullIfEmpty(p.Slug), p.Description, p.Content, p.ContentFormat, p.Thumbnail, p.MediaID, p.CategoryID, p.Status, p.PublishDate, p.UpdateDate, p.CreatedAt)
 
 	if err != nil {
-		return err
+		return translatePostErr(err)
 	}
 
 	id, err := res.LastInsertId()
@@ -85,56 +149,127 @@ func (m *mysqlPostRepo) Store(ctx context.Context, p *domain.Post) error {
 }
 
 func (m *mysqlPostRepo) Update(ctx context.Context, p *domain.Post) error {
+	defer observeQuery("post", "Update")()
 	query := `UPDATE posts SET 
 				title = ?, 
+				slug = ?,
 				description = ?,
 				content = ?,
+				content_format = ?,
 				thumbnail = ?,
+				media_id = ?,
+				category_id = ?,
 				status = ?, 
+				publish_date = ?,
 				update_date = ? 
 				WHERE id = ?`
 
-	_, err := m.db.ExecContext(ctx, query, p.Title, p.Description, p.Content, p.Thumbnail, p.Status, p.UpdateDate, p.ID)
+	_, err := conn(ctx, m.db).ExecContext(ctx, query, p.Title, nullIfEmpty(p.Slug), p.Description, p.Content, p.ContentFormat, p.Thumbnail, p.MediaID, p.CategoryID, p.Status, p.PublishDate, p.UpdateDate, p.ID)
 
-	return err
+	return translatePostErr(err)
 }
 
 func (m *mysqlPostRepo) Delete(ctx context.Context, id int64) error {
+	defer observeQuery("post", "Delete")()
+	// previous_status được gán trước status vì MySQL đánh giá SET từ trái sang phải
 	query := `UPDATE posts SET
-				status = ?
-				WHERE id = ?`
+				previous_status = status,
+				status = ?,
+				deleted_at = ?
+				WHERE id = ?
+				AND status != ?`
 
-	_, err := m.db.ExecContext(ctx, query, domain.StatusDeleted, id)
+	_, err := conn(ctx, m.db).ExecContext(ctx, query, domain.StatusDeleted, time.Now(), id, domain.StatusDeleted)
 
 	return err
 }
 
-func (m *mysqlPostRepo) Search(ctx context.Context, keyword string, limit int64, offset int64) ([]domain.Post, error) {
-	query := `SELECT id, title, description, content, thumbnail, status, update_date, created_at
+func (m *mysqlPostRepo) FetchTrash(ctx context.Context, limit int64, offset int64) ([]domain.Post, error) {
+	defer observeQuery("post", "FetchTrash")()
+	query := `SELECT ` + postColumns + `
 			  FROM posts
-			  WHERE status != ?
-			  AND MATCH(title, description, content) AGAINST(? IN NATURAL LANGUAGE MODE)
-			  ORDER BY created_at DESC
+			  WHERE status = ?
+			  ORDER BY deleted_at DESC
 			  LIMIT ? OFFSET ?`
 
-	// Bỏ các ký tự "%" do MATCH AGAINST tự động phân tách token
-	rows, err := m.db.QueryContext(ctx, query, domain.StatusDeleted, keyword, limit, offset)
+	return m.fetch(ctx, query, limit, domain.StatusDeleted, limit, offset)
+}
+
+func (m *mysqlPostRepo) GetTrashedByID(ctx context.Context, id int64) (*domain.Post, error) {
+	defer observeQuery("post", "GetTrashedByID")()
+	query := `SELECT ` + postColumns + `
+				FROM posts
+				WHERE id = ?
+				AND status = ?`
 
+	p := &domain.Post{}
+	err := scanPost(conn(ctx, m.db).QueryRowContext(ctx, query, id, domain.StatusDeleted), p)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("post not found in trash")
+		}
 		return nil, err
 	}
+	return p, nil
+}
 
-	defer rows.Close()
+func (m *mysqlPostRepo) FetchTrashedBefore(ctx context.Context, cutoff time.Time, limit int64) ([]domain.Post, error) {
+	defer observeQuery("post", "FetchTrashedBefore")()
+	// Bản ghi xóa trước khi có cột deleted_at thì dùng update_date làm mốc
+	query := `SELECT ` + postColumns + `
+			  FROM posts
+			  WHERE status = ?
+			  AND COALESCE(deleted_at, update_date) < ?
+			  ORDER BY id
+			  LIMIT ?`
 
-	result := make([]domain.Post, 0, int(limit))
+	return m.fetch(ctx, query, limit, domain.StatusDeleted, cutoff, limit)
+}
 
-	for rows.Next() {
-		p := domain.Post{}
-		err := rows.Scan(&p.ID, &p.Title, &p.Description, &p.Content, &p.Thumbnail, &p.Status, &p.UpdateDate, &p.CreatedAt)
-		if err != nil {
-			return nil, err
-		}
-		result = append(result, p)
-	}
-	return result, nil
+func (m *mysqlPostRepo) Restore(ctx context.Context, id int64) error {
+	defer observeQuery("post", "Restore")()
+	query := `UPDATE posts SET
+				status = COALESCE(previous_status, ?),
+				previous_status = NULL,
+				deleted_at = NULL
+				WHERE id = ?
+				AND status = ?`
+
+	_, err := conn(ctx, m.db).ExecContext(ctx, query, domain.StatusDraft, id, domain.StatusDeleted)
+
+	return err
+}
+
+func (m *mysqlPostRepo) Purge(ctx context.Context, id int64) error {
+	defer observeQuery("post", "Purge")()
+	query := `DELETE FROM posts WHERE id = ? AND status = ?`
+
+	_, err := conn(ctx, m.db).ExecContext(ctx, query, id, domain.StatusDeleted)
+
+	return err
+}
+
+func (m *mysqlPostRepo) Search(ctx context.Context, keyword string, limit int64, offset int64) ([]domain.Post, error) {
+	defer observeQuery("post", "Search")()
+	query := `SELECT ` + postColumns + `
+			  FROM posts
+			  WHERE status != ?
+			  AND MATCH(title, description, content) AGAINST(? IN NATURAL LANGUAGE MODE)
+			  ORDER BY created_at DESC
+			  LIMIT ? OFFSET ?`
+
+	// Bỏ các ký tự "%" do MATCH AGAINST tự động phân tách token
+	return m.fetch(ctx, query, limit, domain.StatusDeleted, keyword, limit, offset)
+}
+
+func (m *mysqlPostRepo) FetchPublished(ctx context.Context, categoryID int64, limit int64) ([]domain.Post, error) {
+	defer observeQuery("post", "FetchPublished")()
+	query := `SELECT ` + postColumns + `
+			  FROM posts
+			  WHERE status = ?
+			  AND (? = 0 OR category_id = ?)
+			  ORDER BY COALESCE(publish_date, created_at) DESC
+			  LIMIT ?`
+
+	return m.fetch(ctx, query, limit, domain.StatusPublished, categoryID, categoryID, limit)
 }
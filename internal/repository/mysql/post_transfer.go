@@ -0,0 +1,144 @@
+package mysql
+
+import (
+	"Test2/internal/domain"
+	"context"
+	"database/sql"
+	"strings"
+)
+
+// Export/Import bài viết. Export đọc trực tiếp từ cursor của *sql.Rows (driver đọc dần từng dòng
+// từ kết nối) nên bộ nhớ không tăng theo số bài viết.
+
+func (m *mysqlPostRepo) Export(ctx context.Context, includeDeleted bool, fn func(p *domain.Post) error) error {
+	query := `SELECT ` + postColumns + `
+			  FROM posts
+			  WHERE (? OR status != ?)
+			  ORDER BY id`
+
+	rows, err := conn(ctx, m.db).QueryContext(ctx, query, includeDeleted, domain.StatusDeleted)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		p := domain.Post{}
+		if err := scanPost(rows, &p); err != nil {
+			return err
+		}
+		if err := fn(&p); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (m *mysqlPostRepo) FetchByKeys(ctx context.Context, ids []int64, slugs []string) ([]domain.Post, error) {
+	defer observeQuery("post", "FetchByKeys")()
+	conds := make([]string, 0, 2)
+	args := make([]any, 0, len(ids)+len(slugs))
+
+	if len(ids) > 0 {
+		placeholders, idArgs := inPlaceholders(ids)
+		conds = append(conds, `id IN (`+placeholders+`)`)
+		args = append(args, idArgs...)
+	}
+	if len(slugs) > 0 {
+		conds = append(conds, `slug IN (`+strings.TrimSuffix(strings.Repeat("?, ", len(slugs)), ", ")+`)`)
+		for _, s := range slugs {
+			args = append(args, s)
+		}
+	}
+	if len(conds) == 0 {
+		return []domain.Post{}, nil
+	}
+
+	query := `SELECT ` + postColumns + `
+			  FROM posts
+			  WHERE ` + strings.Join(conds, " OR ")
+
+	return m.fetch(ctx, query, int64(len(ids)+len(slugs)), args...)
+}
+
+func (m *mysqlPostRepo) FetchIDsBySource(ctx context.Context, sourceIDs []string) (map[string]int64, error) {
+	defer observeQuery("post", "FetchIDsBySource")()
+	result := make(map[string]int64, len(sourceIDs))
+	if len(sourceIDs) == 0 {
+		return result, nil
+	}
+
+	args := make([]any, 0, len(sourceIDs))
+	for _, s := range sourceIDs {
+		args = append(args, s)
+	}
+	query := `SELECT id, source_id FROM posts
+			  WHERE source_id IN (` + strings.TrimSuffix(strings.Repeat("?, ", len(sourceIDs)), ", ") + `)`
+
+	rows, err := readConn(ctx, m.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id       int64
+			sourceID string
+		)
+		if err := rows.Scan(&id, &sourceID); err != nil {
+			return nil, err
+		}
+		result[sourceID] = id
+	}
+	return result, rows.Err()
+}
+
+func (m *mysqlPostRepo) UpsertBatch(ctx context.Context, posts []*domain.Post) error {
+	defer observeQuery("post", "UpsertBatch")()
+	// id = NULL sẽ được AUTO_INCREMENT cấp mới, id đã tồn tại thì ghi đè toàn bộ cột (trừ source_id rỗng)
+	query := `INSERT INTO posts (id, title, slug, description, content, content_format, thumbnail, media_id, category_id, status, publish_date, update_date, created_at, deleted_at, previous_status, source_id)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) AS new
+				ON DUPLICATE KEY UPDATE
+				title = new.title,
+				slug = new.slug,
+				description = new.description,
+				content = new.content,
+				content_format = new.content_format,
+				thumbnail = new.thumbnail,
+				media_id = new.media_id,
+				category_id = new.category_id,
+				status = new.status,
+				publish_date = new.publish_date,
+				update_date = new.update_date,
+				created_at = new.created_at,
+				deleted_at = new.deleted_at,
+				previous_status = new.previous_status,
+				source_id = COALESCE(new.source_id, posts.source_id)`
+
+	err := withTx(ctx, m.db, func(tx *sql.Tx) error {
+		stmt, err := tx.PrepareContext(ctx, query)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		for _, p := range posts {
+			var id any
+			if p.ID != 0 {
+				id = p.ID
+			}
+			res, err := stmt.ExecContext(ctx, id, p.Title, nullIfEmpty(p.Slug), p.Description, p.Content, p.ContentFormat, p.Thumbnail, p.MediaID, p.CategoryID, p.Status, p.PublishDate, p.UpdateDate, p.CreatedAt, p.DeletedAt, nullIfEmpty(p.PreviousStatus), nullIfEmpty(p.SourceID))
+			if err != nil {
+				return err
+			}
+			if p.ID == 0 {
+				if p.ID, err = res.LastInsertId(); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	return translatePostErr(err)
+}
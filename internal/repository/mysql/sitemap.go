@@ -0,0 +1,78 @@
+package mysql
+
+import (
+	"Test2/internal/domain"
+	"context"
+)
+
+// Các truy vấn sitemap dùng chung cho posts và categories.
+// Trang n chứa các id trong khoảng ((n-1)*pageSize, n*pageSize] nên một bản ghi
+// luôn nằm cố định ở một trang, cho phép chỉ sinh lại trang bị ảnh hưởng.
+
+func sitemapPages(ctx context.Context, db *Cluster, table, lastModColumn, status string, pageSize int64) ([]domain.SitemapPage, error) {
+	query := `SELECT FLOOR((id - 1) / ?) + 1 AS page, MAX(` + lastModColumn + `)
+				FROM ` + table + `
+				WHERE status = ?
+				GROUP BY page
+				ORDER BY page`
+
+	rows, err := readConn(ctx, db).QueryContext(ctx, query, pageSize, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]domain.SitemapPage, 0)
+	for rows.Next() {
+		p := domain.SitemapPage{}
+		if err := rows.Scan(&p.Number, &p.LastMod); err != nil {
+			return nil, err
+		}
+		result = append(result, p)
+	}
+	return result, rows.Err()
+}
+
+func sitemapEntries(ctx context.Context, db *Cluster, table, lastModColumn, status string, page, pageSize int64) ([]domain.SitemapEntry, error) {
+	query := `SELECT id, ` + lastModColumn + `
+				FROM ` + table + `
+				WHERE status = ?
+				AND id > ? AND id <= ?
+				ORDER BY id`
+
+	rows, err := readConn(ctx, db).QueryContext(ctx, query, status, (page-1)*pageSize, page*pageSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]domain.SitemapEntry, 0)
+	for rows.Next() {
+		e := domain.SitemapEntry{}
+		if err := rows.Scan(&e.ID, &e.LastMod); err != nil {
+			return nil, err
+		}
+		result = append(result, e)
+	}
+	return result, rows.Err()
+}
+
+func (m *mysqlPostRepo) SitemapPages(ctx context.Context, pageSize int64) ([]domain.SitemapPage, error) {
+	defer observeQuery("post", "SitemapPages")()
+	return sitemapPages(ctx, m.db, "posts", "update_date", domain.StatusPublished, pageSize)
+}
+
+func (m *mysqlPostRepo) SitemapEntries(ctx context.Context, page int64, pageSize int64) ([]domain.SitemapEntry, error) {
+	defer observeQuery("post", "SitemapEntries")()
+	return sitemapEntries(ctx, m.db, "posts", "update_date", domain.StatusPublished, page, pageSize)
+}
+
+func (m *mysqlCateRepo) SitemapPages(ctx context.Context, pageSize int64) ([]domain.SitemapPage, error) {
+	defer observeQuery("category", "SitemapPages")()
+	return sitemapPages(ctx, m.db, "categories", "updated_at", domain.CategoryStatusActive, pageSize)
+}
+
+func (m *mysqlCateRepo) SitemapEntries(ctx context.Context, page int64, pageSize int64) ([]domain.SitemapEntry, error) {
+	defer observeQuery("category", "SitemapEntries")()
+	return sitemapEntries(ctx, m.db, "categories", "updated_at", domain.CategoryStatusActive, page, pageSize)
+}
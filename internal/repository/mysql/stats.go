@@ -0,0 +1,37 @@
+package mysql
+
+import (
+	"context"
+)
+
+// countByStatus đếm bản ghi của table theo cột status, chạy trên replica khi có
+func countByStatus(ctx context.Context, c *Cluster, table string) (map[string]int64, error) {
+	rows, err := readConn(ctx, c).QueryContext(ctx, `SELECT COALESCE(status, ''), COUNT(*) FROM `+table+` GROUP BY 1`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int64)
+	for rows.Next() {
+		var (
+			status string
+			count  int64
+		)
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		counts[status] = count
+	}
+	return counts, rows.Err()
+}
+
+func (m *mysqlPostRepo) CountByStatus(ctx context.Context) (map[string]int64, error) {
+	defer observeQuery("post", "CountByStatus")()
+	return countByStatus(ctx, m.db, "posts")
+}
+
+func (m *mysqlCateRepo) CountByStatus(ctx context.Context) (map[string]int64, error) {
+	defer observeQuery("category", "CountByStatus")()
+	return countByStatus(ctx, m.db, "categories")
+}
@@ -0,0 +1,235 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("Test2/internal/repository/mysql")
+
+// Open mở connection pool tới MySQL theo dsn, mỗi câu lệnh (kể cả trong transaction, prepared statement,
+// COMMIT/ROLLBACK) tạo một span con của span đang có trong ctx. Câu lệnh không nằm trong trace đang được
+// ghi (job nền, ping) không tạo span. Tham số của câu lệnh không được ghi vào span.
+func Open(dsn string) (*sql.DB, error) {
+	cfg, err := mysqldriver.ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	connector, err := mysqldriver.NewConnector(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := []attribute.KeyValue{semconv.DBSystemNameMySQL, semconv.DBNamespace(cfg.DBName)}
+	if host, port, err := net.SplitHostPort(cfg.Addr); err == nil {
+		attrs = append(attrs, semconv.ServerAddress(host))
+		if n, err := strconv.Atoi(port); err == nil {
+			attrs = append(attrs, semconv.ServerPort(n))
+		}
+	}
+	return sql.OpenDB(&tracedConnector{Connector: connector, attrs: attrs}), nil
+}
+
+type tracedConnector struct {
+	driver.Connector
+	attrs []attribute.KeyValue
+}
+
+func (c *tracedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &tracedDriverConn{Conn: conn, attrs: c.attrs}, nil
+}
+
+// record tạo span cho câu lệnh đã chạy xong. Span được tạo sau khi câu lệnh trả về (với thời điểm bắt đầu
+// đã đo) vì driver có thể từ chối chạy trực tiếp (driver.ErrSkip) để database/sql chuyển sang prepared statement.
+// Với truy vấn trả về rows, span đo tới khi có kết quả đầu tiên, không gồm thời gian duyệt rows.
+// operation rỗng thì lấy từ khóa đầu tiên của câu lệnh (SELECT, UPDATE, ...).
+func record(ctx context.Context, attrs []attribute.KeyValue, operation, query string, start time.Time, err error) {
+	if errors.Is(err, driver.ErrSkip) || !trace.SpanFromContext(ctx).IsRecording() {
+		return
+	}
+	query = strings.Join(strings.Fields(query), " ")
+	if operation == "" {
+		operation, _, _ = strings.Cut(query, " ")
+		operation = strings.ToUpper(operation)
+	}
+
+	_, span := tracer.Start(ctx, operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(start),
+		trace.WithAttributes(attrs...),
+		trace.WithAttributes(semconv.DBOperationName(operation), semconv.DBQueryText(query)),
+	)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// tracedDriverConn bọc kết nối của driver MySQL, các interface tùy chọn được chuyển tiếp để database/sql
+// vẫn dùng đúng đường đi (context, kiểm tra tham số, reset session) như khi dùng driver trực tiếp
+type tracedDriverConn struct {
+	driver.Conn
+	attrs []attribute.KeyValue
+}
+
+func (c *tracedDriverConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	start := time.Now()
+	var (
+		stmt driver.Stmt
+		err  error
+	)
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = p.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	// Câu lệnh có tham số được prepare trước khi chạy (trừ khi DSN bật interpolateParams), span PREPARE cho thấy chi phí đó
+	record(ctx, c.attrs, "PREPARE", query, start, err)
+	if err != nil {
+		return nil, err
+	}
+	return &tracedStmt{Stmt: stmt, query: query, attrs: c.attrs}, nil
+}
+
+func (c *tracedDriverConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	res, err := execer.ExecContext(ctx, query, args)
+	record(ctx, c.attrs, "", query, start, err)
+	return res, err
+}
+
+func (c *tracedDriverConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	record(ctx, c.attrs, "", query, start, err)
+	return rows, err
+}
+
+func (c *tracedDriverConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	start := time.Now()
+	var (
+		tx  driver.Tx
+		err error
+	)
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		tx, err = b.BeginTx(ctx, opts)
+	} else {
+		tx, err = c.Conn.Begin()
+	}
+	record(ctx, c.attrs, "BEGIN", "BEGIN", start, err)
+	if err != nil {
+		return nil, err
+	}
+	return &tracedTx{Tx: tx, ctx: ctx, attrs: c.attrs}, nil
+}
+
+func (c *tracedDriverConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *tracedDriverConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+func (c *tracedDriverConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *tracedDriverConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+// tracedTx ghi span cho COMMIT/ROLLBACK bằng context lúc mở transaction (driver.Tx không nhận context)
+type tracedTx struct {
+	driver.Tx
+	ctx   context.Context
+	attrs []attribute.KeyValue
+}
+
+func (t *tracedTx) Commit() error {
+	start := time.Now()
+	err := t.Tx.Commit()
+	record(t.ctx, t.attrs, "COMMIT", "COMMIT", start, err)
+	return err
+}
+
+func (t *tracedTx) Rollback() error {
+	start := time.Now()
+	err := t.Tx.Rollback()
+	record(t.ctx, t.attrs, "ROLLBACK", "ROLLBACK", start, err)
+	return err
+}
+
+// tracedStmt ghi span cho mỗi lần chạy prepared statement
+type tracedStmt struct {
+	driver.Stmt
+	query string
+	attrs []attribute.KeyValue
+}
+
+func (s *tracedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := s.Stmt.(driver.StmtExecContext)
+	if !ok {
+		return nil, errors.New("mysql: driver statement does not support ExecContext")
+	}
+	start := time.Now()
+	res, err := execer.ExecContext(ctx, args)
+	record(ctx, s.attrs, "", s.query, start, err)
+	return res, err
+}
+
+func (s *tracedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := s.Stmt.(driver.StmtQueryContext)
+	if !ok {
+		return nil, errors.New("mysql: driver statement does not support QueryContext")
+	}
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, args)
+	record(ctx, s.attrs, "", s.query, start, err)
+	return rows, err
+}
+
+func (s *tracedStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
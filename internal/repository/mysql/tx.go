@@ -0,0 +1,95 @@
+package mysql
+
+import (
+	"Test2/internal/domain"
+	"context"
+	"database/sql"
+)
+
+// txKey là khóa context chứa *sql.Tx do Transactor mở
+type txKey struct{}
+
+// dbExecutor là phần chung của *sql.DB và *sql.Tx mà các repository sử dụng
+type dbExecutor interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// primaryConn là connection pool của primary, câu lệnh ghi đánh dấu ctx để các lần đọc sau
+// của cùng request không đọc từ replica (read-your-writes)
+type primaryConn struct {
+	*sql.DB
+}
+
+func (p primaryConn) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	domain.MarkWritten(ctx)
+	return p.DB.ExecContext(ctx, query, args...)
+}
+
+// conn trả về transaction đang mở trong ctx (nếu có), ngược lại dùng connection pool của primary.
+// Dùng cho câu lệnh ghi và truy vấn đọc cần dữ liệu mới nhất (trước khi ghi, job nền, vận hành).
+func conn(ctx context.Context, c *Cluster) dbExecutor {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return withLogging(ctx, tx, "tx")
+	}
+	return withLogging(ctx, primaryConn{c.primary}, "primary")
+}
+
+// readConn dùng cho truy vấn đọc chịu được độ trễ replication: chọn replica khỏe,
+// trừ khi ctx đang trong transaction hoặc đã ghi vào primary (domain.ReadFromPrimary)
+func readConn(ctx context.Context, c *Cluster) dbExecutor {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return withLogging(ctx, tx, "tx")
+	}
+	if !c.HasReplicas() {
+		return withLogging(ctx, c.primary, "primary")
+	}
+	if domain.ReadFromPrimary(ctx) {
+		readsTotal.WithLabelValues("primary").Inc()
+		return withLogging(ctx, c.primary, "primary")
+	}
+	if r := c.reader(); r != nil {
+		readsTotal.WithLabelValues("replica").Inc()
+		return withLogging(ctx, r.db, "replica "+r.name)
+	}
+	readsTotal.WithLabelValues("fallback").Inc()
+	return withLogging(ctx, c.primary, "primary")
+}
+
+// withTx chạy fn trong một transaction trên primary, commit khi fn trả về nil và rollback khi có lỗi.
+// Nếu ctx đã có transaction (mở bởi Transactor) thì fn chạy trong transaction đó,
+// việc commit/rollback do nơi mở transaction quyết định.
+func withTx(ctx context.Context, c *Cluster, fn func(tx *sql.Tx) error) error {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(tx)
+	}
+
+	domain.MarkWritten(ctx)
+	tx, err := c.primary.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func NewMysqlTransactor(db *Cluster) domain.Transactor {
+	return &mysqlTransactor{db}
+}
+
+type mysqlTransactor struct {
+	db *Cluster
+}
+
+// WithinTx gắn transaction vào context, mọi repository MySQL nhận context này sẽ ghi trong cùng transaction
+func (t *mysqlTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return withTx(ctx, t.db, func(tx *sql.Tx) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
@@ -0,0 +1,360 @@
+package mysql
+
+import (
+	"Test2/internal/domain"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const webhookColumns = `id, url, events, secret, active, failure_count, disabled_at, created_at, updated_at`
+
+const deliveryColumns = `id, webhook_id, event_id, event_type, payload, status, attempts, response_code, response_body, error,
+			duration_ms, replay_of, next_attempt_at, last_attempt_at, created_at`
+
+func NewMysqlWebhookRepository(db *Cluster) domain.WebhookRepository {
+	return &mysqlWebhookRepo{db}
+}
+
+type mysqlWebhookRepo struct {
+	db *Cluster
+}
+
+func scanWebhook(s rowScanner, w *domain.Webhook) error {
+	var events []byte
+	var disabledAt sql.NullTime
+	err := s.Scan(&w.ID, &w.URL, &events, &w.Secret, &w.Active, &w.FailureCount, &disabledAt, &w.CreatedAt, &w.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	w.DisabledAt = nullTimePtr(disabledAt)
+
+	w.Events = []string{}
+	if len(events) > 0 {
+		return json.Unmarshal(events, &w.Events)
+	}
+	return nil
+}
+
+func (m *mysqlWebhookRepo) list(ctx context.Context, query string, args ...any) ([]domain.Webhook, error) {
+	rows, err := conn(ctx, m.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]domain.Webhook, 0)
+	for rows.Next() {
+		w := domain.Webhook{}
+		if err := scanWebhook(rows, &w); err != nil {
+			return nil, err
+		}
+		result = append(result, w)
+	}
+	return result, rows.Err()
+}
+
+func (m *mysqlWebhookRepo) Fetch(ctx context.Context, limit int64, offset int64) ([]domain.Webhook, error) {
+	defer observeQuery("webhook", "Fetch")()
+	query := `SELECT ` + webhookColumns + `
+				FROM webhooks
+				ORDER BY id DESC
+				LIMIT ? OFFSET ?`
+
+	return m.list(ctx, query, limit, offset)
+}
+
+func (m *mysqlWebhookRepo) GetByID(ctx context.Context, id int64) (*domain.Webhook, error) {
+	defer observeQuery("webhook", "GetByID")()
+	query := `SELECT ` + webhookColumns + `
+				FROM webhooks
+				WHERE id = ?`
+
+	w := &domain.Webhook{}
+	if err := scanWebhook(conn(ctx, m.db).QueryRowContext(ctx, query, id), w); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	return w, nil
+}
+
+func (m *mysqlWebhookRepo) FetchActive(ctx context.Context) ([]domain.Webhook, error) {
+	defer observeQuery("webhook", "FetchActive")()
+	query := `SELECT ` + webhookColumns + `
+				FROM webhooks
+				WHERE active = TRUE
+				ORDER BY id`
+
+	return m.list(ctx, query)
+}
+
+func (m *mysqlWebhookRepo) Store(ctx context.Context, w *domain.Webhook) error {
+	defer observeQuery("webhook", "Store")()
+	events, err := json.Marshal(w.Events)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO webhooks (url, events, secret, active, failure_count, disabled_at, created_at, updated_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	res, err := conn(ctx, m.db).ExecContext(ctx, query, w.URL, events, w.Secret, w.Active, w.FailureCount, w.DisabledAt, w.CreatedAt, w.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	w.ID = id
+	return nil
+}
+
+func (m *mysqlWebhookRepo) Update(ctx context.Context, w *domain.Webhook) error {
+	defer observeQuery("webhook", "Update")()
+	events, err := json.Marshal(w.Events)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE webhooks
+				SET url = ?, events = ?, secret = ?, active = ?, failure_count = ?, disabled_at = ?, updated_at = ?
+				WHERE id = ?`
+
+	res, err := conn(ctx, m.db).ExecContext(ctx, query, w.URL, events, w.Secret, w.Active, w.FailureCount, w.DisabledAt, w.UpdatedAt, w.ID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return domain.ErrWebhookNotFound
+	}
+	return nil
+}
+
+// Delete xóa webhook, delivery log bị xóa theo (ON DELETE CASCADE)
+func (m *mysqlWebhookRepo) Delete(ctx context.Context, id int64) error {
+	defer observeQuery("webhook", "Delete")()
+	res, err := conn(ctx, m.db).ExecContext(ctx, `DELETE FROM webhooks WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return domain.ErrWebhookNotFound
+	}
+	return nil
+}
+
+func (m *mysqlWebhookRepo) RecordAttempt(ctx context.Context, id int64, success bool, disableAfter int) (bool, error) {
+	defer observeQuery("webhook", "RecordAttempt")()
+	if success {
+		_, err := conn(ctx, m.db).ExecContext(ctx, `UPDATE webhooks SET failure_count = 0 WHERE id = ? AND failure_count != 0`, id)
+		return false, err
+	}
+
+	// Khóa dòng webhook để hai worker cùng ghi nhận thất bại không vượt ngưỡng hai lần
+	disabled := false
+	err := withTx(ctx, m.db, func(tx *sql.Tx) error {
+		var active bool
+		var failures int
+		err := tx.QueryRowContext(ctx, `SELECT active, failure_count FROM webhooks WHERE id = ? FOR UPDATE`, id).Scan(&active, &failures)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil
+			}
+			return err
+		}
+
+		failures++
+		disabled = active && disableAfter > 0 && failures >= disableAfter
+		if disabled {
+			_, err = tx.ExecContext(ctx, `UPDATE webhooks SET failure_count = ?, active = FALSE, disabled_at = ? WHERE id = ?`, failures, time.Now(), id)
+		} else {
+			_, err = tx.ExecContext(ctx, `UPDATE webhooks SET failure_count = ? WHERE id = ?`, failures, id)
+		}
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+	return disabled, nil
+}
+
+// --- DELIVERIES ---
+
+func scanDelivery(s rowScanner, d *domain.WebhookDelivery) error {
+	var payload []byte
+	var code, replayOf sql.NullInt64
+	var body, errText sql.NullString
+	var next, last sql.NullTime
+	err := s.Scan(&d.ID, &d.WebhookID, &d.EventID, &d.EventType, &payload, &d.Status, &d.Attempts, &code, &body, &errText,
+		&d.DurationMs, &replayOf, &next, &last, &d.CreatedAt)
+	if err != nil {
+		return err
+	}
+	d.Payload = payload
+	if code.Valid {
+		c := int(code.Int64)
+		d.ResponseCode = &c
+	}
+	d.ResponseBody = body.String
+	d.Error = errText.String
+	d.ReplayOf = nullInt64Ptr(replayOf)
+	d.NextAttemptAt = nullTimePtr(next)
+	d.LastAttemptAt = nullTimePtr(last)
+	return nil
+}
+
+func (m *mysqlWebhookRepo) listDeliveries(ctx context.Context, query string, args ...any) ([]domain.WebhookDelivery, error) {
+	rows, err := conn(ctx, m.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]domain.WebhookDelivery, 0)
+	for rows.Next() {
+		d := domain.WebhookDelivery{}
+		if err := scanDelivery(rows, &d); err != nil {
+			return nil, err
+		}
+		result = append(result, d)
+	}
+	return result, rows.Err()
+}
+
+// deliveryDedupeKey trả về khóa chống trùng, chỉ delivery sinh từ outbox mới có (Ping và replay luôn là bản ghi mới)
+func deliveryDedupeKey(d *domain.WebhookDelivery) any {
+	if d.EventID == 0 || d.ReplayOf != nil {
+		return nil
+	}
+	return strconv.FormatInt(d.WebhookID, 10) + ":" + strconv.FormatInt(d.EventID, 10)
+}
+
+func (m *mysqlWebhookRepo) StoreDeliveries(ctx context.Context, deliveries []domain.WebhookDelivery) error {
+	defer observeQuery("webhook", "StoreDeliveries")()
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	// Trùng dedupe_key (relay giao lại cùng event) thì giữ bản ghi cũ
+	values := strings.TrimSuffix(strings.Repeat("(?, ?, ?, ?, ?, ?, ?, ?, ?), ", len(deliveries)), ", ")
+	query := `INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload, status, replay_of, dedupe_key, next_attempt_at, created_at)
+				VALUES ` + values + `
+				ON DUPLICATE KEY UPDATE id = id`
+
+	args := make([]any, 0, len(deliveries)*9)
+	for i := range deliveries {
+		d := &deliveries[i]
+		args = append(args, d.WebhookID, d.EventID, d.EventType, []byte(d.Payload), d.Status, d.ReplayOf, deliveryDedupeKey(d), d.NextAttemptAt, d.CreatedAt)
+	}
+
+	res, err := conn(ctx, m.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	// Ping/replay được chèn từng bản ghi và không thể trùng nên LastInsertId chính là id của nó
+	if len(deliveries) == 1 && deliveryDedupeKey(&deliveries[0]) == nil {
+		id, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+		deliveries[0].ID = id
+	}
+	return nil
+}
+
+func (m *mysqlWebhookRepo) GetDelivery(ctx context.Context, id int64) (*domain.WebhookDelivery, error) {
+	defer observeQuery("webhook", "GetDelivery")()
+	query := `SELECT ` + deliveryColumns + `
+				FROM webhook_deliveries
+				WHERE id = ?`
+
+	d := &domain.WebhookDelivery{}
+	if err := scanDelivery(conn(ctx, m.db).QueryRowContext(ctx, query, id), d); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrWebhookDeliveryNotFound
+		}
+		return nil, err
+	}
+	return d, nil
+}
+
+func (m *mysqlWebhookRepo) FetchDeliveries(ctx context.Context, filter domain.WebhookDeliveryFilter, limit int64, offset int64) ([]domain.WebhookDelivery, error) {
+	defer observeQuery("webhook", "FetchDeliveries")()
+	conds := make([]string, 0, 2)
+	args := make([]any, 0, 4)
+	if filter.WebhookID != 0 {
+		conds = append(conds, `webhook_id = ?`)
+		args = append(args, filter.WebhookID)
+	}
+	if filter.Status != "" {
+		conds = append(conds, `status = ?`)
+		args = append(args, filter.Status)
+	}
+
+	where := ""
+	if len(conds) > 0 {
+		where = `WHERE ` + strings.Join(conds, " AND ")
+	}
+
+	query := `SELECT ` + deliveryColumns + `
+				FROM webhook_deliveries
+				` + where + `
+				ORDER BY id DESC
+				LIMIT ? OFFSET ?`
+	args = append(args, limit, offset)
+
+	return m.listDeliveries(ctx, query, args...)
+}
+
+func (m *mysqlWebhookRepo) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error) {
+	defer observeQuery("webhook", "ClaimDueDeliveries")()
+	var claimed []domain.WebhookDelivery
+	err := withTx(ctx, m.db, func(tx *sql.Tx) error {
+		// SKIP LOCKED: các instance khác bỏ qua những dòng đang được claim thay vì chờ
+		query := `SELECT ` + deliveryColumns + `
+					FROM webhook_deliveries
+					WHERE status = ? AND next_attempt_at <= ?
+					ORDER BY next_attempt_at, id
+					LIMIT ?
+					FOR UPDATE SKIP LOCKED`
+
+		var err error
+		claimed, err = m.listDeliveries(context.WithValue(ctx, txKey{}, tx), query, domain.WebhookDeliveryPending, now, limit)
+		if err != nil || len(claimed) == 0 {
+			return err
+		}
+
+		ids := make([]int64, len(claimed))
+		for i := range claimed {
+			ids[i] = claimed[i].ID
+		}
+		placeholders, idArgs := inPlaceholders(ids)
+		args := append([]any{now.Add(lease)}, idArgs...)
+		_, err = tx.ExecContext(ctx, `UPDATE webhook_deliveries SET next_attempt_at = ? WHERE id IN (`+placeholders+`)`, args...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claimed, nil
+}
+
+func (m *mysqlWebhookRepo) UpdateDelivery(ctx context.Context, d *domain.WebhookDelivery) error {
+	defer observeQuery("webhook", "UpdateDelivery")()
+	query := `UPDATE webhook_deliveries
+				SET status = ?, attempts = ?, response_code = ?, response_body = ?, error = ?, duration_ms = ?,
+				next_attempt_at = ?, last_attempt_at = ?
+				WHERE id = ?`
+
+	_, err := conn(ctx, m.db).ExecContext(ctx, query, d.Status, d.Attempts, d.ResponseCode, d.ResponseBody, d.Error, d.DurationMs,
+		d.NextAttemptAt, d.LastAttemptAt, d.ID)
+	return err
+}
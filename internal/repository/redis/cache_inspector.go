@@ -0,0 +1,94 @@
+package redis
+
+import (
+	"context"
+
+	"Test2/internal/domain"
+	redisclient "github.com/redis/go-redis/v9"
+)
+
+// scanBatch là gợi ý COUNT cho mỗi lần SCAN
+const scanBatch = 1000
+
+type redisCacheInspector struct {
+	client *redisclient.Client
+}
+
+func NewRedisCacheInspector(client *redisclient.Client) domain.CacheInspector {
+	return &redisCacheInspector{client: client}
+}
+
+// scan gọi fn với từng lô khóa khớp pattern tới khi duyệt hết keyspace
+func (r *redisCacheInspector) scan(ctx context.Context, pattern string, fn func(keys []string) error) error {
+	var cursor uint64
+	for {
+		keys, next, err := r.client.Scan(ctx, cursor, pattern, scanBatch).Result()
+		if err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			if err := fn(keys); err != nil {
+				return err
+			}
+		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+func (r *redisCacheInspector) Inspect(ctx context.Context, pattern string, limit int) (int64, []domain.CacheKeyInfo, error) {
+	var count int64
+	sample := make([]string, 0, limit)
+	err := r.scan(ctx, pattern, func(keys []string) error {
+		count += int64(len(keys))
+		for _, k := range keys {
+			if len(sample) < limit {
+				sample = append(sample, k)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	infos := make([]domain.CacheKeyInfo, 0, len(sample))
+	if len(sample) == 0 {
+		return count, infos, nil
+	}
+
+	pipe := r.client.Pipeline()
+	ttls := make([]*redisclient.DurationCmd, len(sample))
+	sizes := make([]*redisclient.IntCmd, len(sample))
+	for i, k := range sample {
+		ttls[i] = pipe.PTTL(ctx, k)
+		sizes[i] = pipe.StrLen(ctx, k)
+	}
+	_, _ = pipe.Exec(ctx)
+
+	for i, k := range sample {
+		// Khóa hết hạn giữa SCAN và PTTL thì bỏ qua
+		if ttls[i].Err() != nil || ttls[i].Val() == -2 {
+			continue
+		}
+		info := domain.CacheKeyInfo{Key: k, TTL: ttls[i].Val(), Size: sizes[i].Val()}
+		if info.TTL < 0 {
+			info.TTL = -1
+		}
+		infos = append(infos, info)
+	}
+	return count, infos, nil
+}
+
+func (r *redisCacheInspector) DeletePattern(ctx context.Context, pattern string) (int64, error) {
+	var deleted int64
+	err := r.scan(ctx, pattern, func(keys []string) error {
+		// UNLINK giải phóng bộ nhớ ở background, không chặn Redis với giá trị lớn
+		n, err := r.client.Unlink(ctx, keys...).Result()
+		deleted += n
+		return err
+	})
+	return deleted, err
+}
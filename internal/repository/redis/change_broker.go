@@ -0,0 +1,116 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strconv"
+
+	"Test2/internal/domain"
+	redisclient "github.com/redis/go-redis/v9"
+)
+
+// redisChangeBroker phát thông báo qua Redis pub/sub và giữ buffer replay trong một sorted set
+// (score là id của thông báo) giới hạn bufferSize phần tử gần nhất
+type redisChangeBroker struct {
+	client     *redisclient.Client
+	channel    string
+	bufferKey  string
+	bufferSize int64
+}
+
+func NewRedisChangeBroker(client *redisclient.Client, channel string, bufferSize int64) domain.ChangeBroker {
+	return &redisChangeBroker{
+		client:     client,
+		channel:    channel,
+		bufferKey:  channel + ":buffer",
+		bufferSize: bufferSize,
+	}
+}
+
+func (b *redisChangeBroker) Publish(ctx context.Context, notifications []domain.ChangeNotification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	messages := make([][]byte, len(notifications))
+	members := make([]redisclient.Z, len(notifications))
+	for i, n := range notifications {
+		data, err := json.Marshal(n)
+		if err != nil {
+			return err
+		}
+		messages[i] = data
+		members[i] = redisclient.Z{Score: float64(n.ID), Member: data}
+	}
+
+	// Ghi buffer trước khi phát để client resume ngay sau đó vẫn thấy thông báo
+	pipe := b.client.TxPipeline()
+	pipe.ZAdd(ctx, b.bufferKey, members...)
+	if b.bufferSize > 0 {
+		pipe.ZRemRangeByRank(ctx, b.bufferKey, 0, -b.bufferSize-1)
+	}
+	for _, m := range messages {
+		pipe.Publish(ctx, b.channel, m)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (b *redisChangeBroker) Subscribe(ctx context.Context) (<-chan domain.ChangeNotification, error) {
+	pubsub := b.client.Subscribe(ctx, b.channel)
+	// Chờ Redis xác nhận để không bỏ lỡ thông báo phát ngay sau khi Subscribe trả về
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return nil, err
+	}
+
+	out := make(chan domain.ChangeNotification, 64)
+	go func() {
+		defer close(out)
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				var n domain.ChangeNotification
+				if err := json.Unmarshal([]byte(msg.Payload), &n); err != nil {
+					slog.WarnContext(ctx, "change broker: skip malformed message", "error", err)
+					continue
+				}
+				select {
+				case out <- n:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+func (b *redisChangeBroker) Since(ctx context.Context, lastID int64) ([]domain.ChangeNotification, error) {
+	values, err := b.client.ZRangeByScore(ctx, b.bufferKey, &redisclient.ZRangeBy{
+		Min: "(" + strconv.FormatInt(lastID, 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	notifications := make([]domain.ChangeNotification, 0, len(values))
+	for _, v := range values {
+		var n domain.ChangeNotification
+		if err := json.Unmarshal([]byte(v), &n); err != nil {
+			continue
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, nil
+}
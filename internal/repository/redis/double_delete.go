@@ -0,0 +1,62 @@
+package redis
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"Test2/internal/domain"
+)
+
+// Khi đọc từ MySQL replica, request khác có thể nạp lại cache từ replica chưa nhận thay đổi vừa ghi
+// ngay sau khi cache bị xóa, dữ liệu cũ đó sẽ nằm trong cache tới hết TTL. Decorator dưới đây xóa
+// lại các khóa sau delay (độ trễ tối đa của replica còn trong vòng đọc) để loại bỏ dữ liệu cũ.
+
+// redeleteTimeout giới hạn thời gian của lần xóa thứ hai
+const redeleteTimeout = 5 * time.Second
+
+// deleteAgain xóa lại keys sau delay, lỗi bị bỏ qua như lần xóa đầu
+func deleteAgain(ctx context.Context, delay time.Duration, del func(ctx context.Context, keys ...string) error, keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+	keys = slices.Clone(keys)
+	ctx = context.WithoutCancel(ctx)
+	time.AfterFunc(delay, func() {
+		c, cancel := context.WithTimeout(ctx, redeleteTimeout)
+		defer cancel()
+		_ = del(c, keys...)
+	})
+}
+
+type doubleDeleteCacheRepo struct {
+	domain.CacheRepository
+	delay time.Duration
+}
+
+// NewDoubleDeleteCacheRepository bọc cache, mỗi lần Delete được lặp lại sau delay
+func NewDoubleDeleteCacheRepository(cache domain.CacheRepository, delay time.Duration) domain.CacheRepository {
+	return &doubleDeleteCacheRepo{CacheRepository: cache, delay: delay}
+}
+
+func (r *doubleDeleteCacheRepo) Delete(ctx context.Context, keys ...string) error {
+	err := r.CacheRepository.Delete(ctx, keys...)
+	deleteAgain(ctx, r.delay, r.CacheRepository.Delete, keys)
+	return err
+}
+
+type doubleDeleteRawCacheRepo struct {
+	domain.RawCacheRepository
+	delay time.Duration
+}
+
+// NewDoubleDeleteRawCacheRepository bọc raw cache (HTML, feed, sitemap, cây danh mục), mỗi lần Delete được lặp lại sau delay
+func NewDoubleDeleteRawCacheRepository(cache domain.RawCacheRepository, delay time.Duration) domain.RawCacheRepository {
+	return &doubleDeleteRawCacheRepo{RawCacheRepository: cache, delay: delay}
+}
+
+func (r *doubleDeleteRawCacheRepo) Delete(ctx context.Context, keys ...string) error {
+	err := r.RawCacheRepository.Delete(ctx, keys...)
+	deleteAgain(ctx, r.delay, r.RawCacheRepository.Delete, keys)
+	return err
+}
@@ -0,0 +1,57 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"Test2/internal/domain"
+	redisclient "github.com/redis/go-redis/v9"
+)
+
+// redisStreamSink giao domain event vào một Redis Stream. Mọi event đi vào cùng một stream
+// theo thứ tự id của outbox, consumer dùng trường event_id để bỏ qua event bị giao lại.
+type redisStreamSink struct {
+	client *redisclient.Client
+	stream string
+	maxLen int64
+}
+
+// NewRedisStreamSink tạo sink ghi vào stream, maxLen > 0 giới hạn gần đúng độ dài stream (XADD MAXLEN ~)
+func NewRedisStreamSink(client *redisclient.Client, stream string, maxLen int64) domain.EventSink {
+	return &redisStreamSink{client: client, stream: stream, maxLen: maxLen}
+}
+
+func (s *redisStreamSink) Name() string {
+	return "redis-stream:" + s.stream
+}
+
+func (s *redisStreamSink) Publish(ctx context.Context, events []domain.DomainEvent) (int, error) {
+	// Pipeline gửi cả lô trong một round trip, Redis thực thi lần lượt nên thứ tự được giữ nguyên
+	pipe := s.client.Pipeline()
+	cmds := make([]*redisclient.StringCmd, len(events))
+	for i, e := range events {
+		cmds[i] = pipe.XAdd(ctx, &redisclient.XAddArgs{
+			Stream: s.stream,
+			MaxLen: s.maxLen,
+			Approx: s.maxLen > 0,
+			Values: map[string]any{
+				"event_id":       strconv.FormatInt(e.ID, 10),
+				"type":           e.Type,
+				"aggregate_type": e.AggregateType,
+				"aggregate_id":   strconv.FormatInt(e.AggregateID, 10),
+				"payload":        string(e.Payload),
+				"occurred_at":    e.OccurredAt.UTC().Format(time.RFC3339Nano),
+			},
+		})
+	}
+	_, _ = pipe.Exec(ctx)
+
+	// Đếm số event liên tiếp từ đầu lô đã được ghi, event sau lỗi đầu tiên sẽ được giao lại
+	for i, cmd := range cmds {
+		if err := cmd.Err(); err != nil {
+			return i, err
+		}
+	}
+	return len(events), nil
+}
@@ -0,0 +1,43 @@
+package redis
+
+import (
+	"context"
+
+	"Test2/internal/domain"
+	redisclient "github.com/redis/go-redis/v9"
+)
+
+// redisHealth kiểm tra kết nối Redis và báo cáo thống kê connection pool cùng trạng thái circuit breaker.
+// Redis chỉ là cache nên lỗi Redis làm instance degraded chứ không mất readiness.
+type redisHealth struct {
+	client  *redisclient.Client
+	breaker domain.CircuitBreaker
+}
+
+// NewRedisHealthCheck trả về kiểu vừa là OptionalHealthCheck vừa là HealthDetailer
+func NewRedisHealthCheck(client *redisclient.Client, breaker domain.CircuitBreaker) interface {
+	domain.OptionalHealthCheck
+	domain.HealthDetailer
+} {
+	return &redisHealth{client: client, breaker: breaker}
+}
+
+func (r *redisHealth) Name() string {
+	return "redis"
+}
+
+func (r *redisHealth) Optional() bool {
+	return true
+}
+
+// Check trả về domain.ErrCacheUnavailable ngay khi breaker đang mở, không chờ timeout
+func (r *redisHealth) Check(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
+}
+
+func (r *redisHealth) Details(ctx context.Context) (any, error) {
+	return map[string]any{
+		"pool":    r.client.PoolStats(),
+		"circuit": r.breaker.Stats(),
+	}, nil
+}
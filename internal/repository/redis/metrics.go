@@ -0,0 +1,93 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"Test2/internal/domain"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	redisclient "github.com/redis/go-redis/v9"
+)
+
+var (
+	cacheLookupsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cms_cache_lookups_total",
+		Help: "Số lần tra cache theo nhóm khóa và kết quả (hit hoặc miss, lỗi và dữ liệu hỏng được tính là miss).",
+	}, []string{"family", "result"})
+	cacheSetsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cms_cache_sets_total",
+		Help: "Số lần ghi cache thành công theo nhóm khóa.",
+	}, []string{"family"})
+	cacheErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cms_cache_errors_total",
+		Help: "Số thao tác cache lỗi theo nhóm khóa và thao tác (get, set, delete, decode). Lệnh bị circuit breaker bỏ qua không được tính.",
+	}, []string{"family", "operation"})
+)
+
+// cacheKeyFamilies ánh xạ tiền tố khóa sang nhãn family, khớp với định dạng khóa dùng trong các UseCase
+var cacheKeyFamilies = []struct {
+	prefix string
+	family string
+}{
+	{"posts:list:", "list"},
+	{"post:detail:", "detail"},
+	{"posts:search:", "search"},
+	{"post:html:", "html"},
+	{"feeds:", "feed"},
+	{"sitemap:", "sitemap"},
+	{"categories:tree", "category_tree"},
+}
+
+// keyFamily trả về nhãn family của khóa, "other" khi không khớp nhóm nào (giữ số nhãn của metric cố định)
+func keyFamily(key string) string {
+	for _, f := range cacheKeyFamilies {
+		if strings.HasPrefix(key, f.prefix) {
+			return f.family
+		}
+	}
+	return "other"
+}
+
+// recordLookup ghi nhận một lần tra cache cho metric và cho bộ đếm của request (access log)
+func recordLookup(ctx context.Context, key string, hit bool) {
+	domain.RecordCacheLookup(ctx, hit)
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	cacheLookupsTotal.WithLabelValues(keyFamily(key), result).Inc()
+}
+
+// recordError đếm lỗi của Redis, cache miss và lệnh bị breaker bỏ qua (đã có metric riêng) không phải lỗi
+func recordError(key, operation string, err error) {
+	if err == nil || errors.Is(err, redisclient.Nil) || errors.Is(err, domain.ErrCacheUnavailable) {
+		return
+	}
+	cacheErrorsTotal.WithLabelValues(keyFamily(key), operation).Inc()
+}
+
+// recordSet ghi nhận kết quả một lần ghi cache
+func recordSet(key string, err error) {
+	if err == nil {
+		cacheSetsTotal.WithLabelValues(keyFamily(key)).Inc()
+		return
+	}
+	recordError(key, "set", err)
+}
+
+// recordDelete đếm lỗi xóa cache một lần cho mỗi nhóm khóa có trong lệnh
+func recordDelete(keys []string, err error) {
+	if err == nil {
+		return
+	}
+	seen := make(map[string]bool)
+	for _, key := range keys {
+		if family := keyFamily(key); !seen[family] {
+			seen[family] = true
+			recordError(key, "delete", err)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package redis
 import (
 	"context"
 	"encoding/json"
+	"log/slog"
 	"time"
 
 	"Test2/internal/domain"
@@ -20,15 +21,21 @@ func NewRedisCacheRepository(client *redisclient.Client) domain.CacheRepository
 func (r *redisCacheRepo) Get(ctx context.Context, key string) ([]domain.Post, bool) {
 	val, err := r.client.Get(ctx, key).Result()
 	if err != nil {
+		recordError(key, "get", err)
+		recordLookup(ctx, key, false)
 		return nil, false // Cache miss hoặc lỗi kết nối
 	}
 
 	var posts []domain.Post
 	err = json.Unmarshal([]byte(val), &posts)
 	if err != nil {
+		slog.WarnContext(ctx, "post cache: malformed entry", "key", key, "error", err)
+		recordError(key, "decode", err)
+		recordLookup(ctx, key, false)
 		return nil, false // Lỗi parse JSON
 	}
 
+	recordLookup(ctx, key, true)
 	return posts, true
 }
 
@@ -37,9 +44,16 @@ func (r *redisCacheRepo) Set(ctx context.Context, key string, value []domain.Pos
 	if err != nil {
 		return err
 	}
-	return r.client.Set(ctx, key, data, ttl).Err()
+	err = r.client.Set(ctx, key, data, ttl).Err()
+	recordSet(key, err)
+	return err
 }
 
-func (r *redisCacheRepo) Delete(ctx context.Context, key string) error {
-	return r.client.Del(ctx, key).Err()
+func (r *redisCacheRepo) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	err := r.client.Del(ctx, keys...).Err()
+	recordDelete(keys, err)
+	return err
 }
@@ -0,0 +1,42 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"Test2/internal/domain"
+	redisclient "github.com/redis/go-redis/v9"
+)
+
+type redisRawCacheRepo struct {
+	client *redisclient.Client
+}
+
+func NewRedisRawCacheRepository(client *redisclient.Client) domain.RawCacheRepository {
+	return &redisRawCacheRepo{client: client}
+}
+
+func (r *redisRawCacheRepo) Get(ctx context.Context, key string) ([]byte, bool) {
+	val, err := r.client.Get(ctx, key).Bytes()
+	recordError(key, "get", err)
+	recordLookup(ctx, key, err == nil)
+	if err != nil {
+		return nil, false // Cache miss hoặc lỗi kết nối
+	}
+	return val, true
+}
+
+func (r *redisRawCacheRepo) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	err := r.client.Set(ctx, key, value, ttl).Err()
+	recordSet(key, err)
+	return err
+}
+
+func (r *redisRawCacheRepo) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	err := r.client.Del(ctx, keys...).Err()
+	recordDelete(keys, err)
+	return err
+}
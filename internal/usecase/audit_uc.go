@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"Test2/internal/domain"
+)
+
+type auditUseCase struct {
+	auditRepo      domain.AuditRepository
+	contextTimeout time.Duration
+}
+
+// NewAuditUseCase khởi tạo audit log, được truyền vào Post/Category UseCase để ghi trong transaction của thao tác ghi
+func NewAuditUseCase(auditRepo domain.AuditRepository, timeout time.Duration) domain.AuditUseCase {
+	return &auditUseCase{
+		auditRepo:      auditRepo,
+		contextTimeout: timeout,
+	}
+}
+
+// newAuditEntry điền actor/request ID/IP từ RequestMeta, thiếu RequestMeta là thao tác nội bộ
+func newAuditEntry(ctx context.Context, entity string, id int64, action string, now time.Time) domain.AuditEntry {
+	entry := domain.AuditEntry{
+		Actor:      domain.AuditActorSystem,
+		EntityType: entity,
+		EntityID:   id,
+		Action:     action,
+		CreatedAt:  now,
+	}
+	if meta, ok := domain.RequestMetaFromContext(ctx); ok {
+		entry.Actor = meta.Actor
+		entry.RequestID = meta.RequestID
+		entry.IP = meta.IP
+	}
+	return entry
+}
+
+// auditSnapshot chuyển bản ghi sang JSON, nil được giữ là nil (cột NULL)
+func auditSnapshot[T any](v *T) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		slog.Error("audit: cannot marshal snapshot", "error", err)
+		return nil
+	}
+	return data
+}
+
+func postSnapshot(p *domain.Post) json.RawMessage {
+	return auditSnapshot(plainPost(p))
+}
+
+func categorySnapshot(c *domain.Category) json.RawMessage {
+	return auditSnapshot(plainCategory(c))
+}
+
+func (au *auditUseCase) postEntry(ctx context.Context, action string, before, after *domain.Post, now time.Time) domain.AuditEntry {
+	id := int64(0)
+	if after != nil {
+		id = after.ID
+	} else if before != nil {
+		id = before.ID
+	}
+	entry := newAuditEntry(ctx, domain.AuditEntityPost, id, action, now)
+	entry.Before = postSnapshot(before)
+	entry.After = postSnapshot(after)
+	return entry
+}
+
+func (au *auditUseCase) categoryEntry(ctx context.Context, action string, before, after *domain.Category, now time.Time) domain.AuditEntry {
+	id := int64(0)
+	if after != nil {
+		id = after.ID
+	} else if before != nil {
+		id = before.ID
+	}
+	entry := newAuditEntry(ctx, domain.AuditEntityCategory, id, action, now)
+	entry.Before = categorySnapshot(before)
+	entry.After = categorySnapshot(after)
+	return entry
+}
+
+func (au *auditUseCase) RecordPosts(ctx context.Context, action string, changes []domain.PostChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	now := time.Now()
+	entries := make([]domain.AuditEntry, 0, len(changes))
+	for _, ch := range changes {
+		entries = append(entries, au.postEntry(ctx, action, ch.Before, ch.After, now))
+	}
+	return au.auditRepo.Store(ctx, entries)
+}
+
+func (au *auditUseCase) RecordCategories(ctx context.Context, action string, changes []domain.CategoryChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	now := time.Now()
+	entries := make([]domain.AuditEntry, 0, len(changes))
+	for _, ch := range changes {
+		entries = append(entries, au.categoryEntry(ctx, action, ch.Before, ch.After, now))
+	}
+	return au.auditRepo.Store(ctx, entries)
+}
+
+func (au *auditUseCase) Fetch(ctx context.Context, filter domain.AuditFilter, page int64, pageSize int64) ([]domain.AuditEntry, error) {
+	c, cancel := context.WithTimeout(ctx, au.contextTimeout)
+	defer cancel()
+
+	switch filter.EntityType {
+	case "", domain.AuditEntityPost, domain.AuditEntityCategory:
+	default:
+		return nil, fmt.Errorf("%w: unknown entity %q", domain.ErrInvalidAuditFilter, filter.EntityType)
+	}
+	if filter.EntityID < 0 {
+		return nil, fmt.Errorf("%w: invalid entity id %d", domain.ErrInvalidAuditFilter, filter.EntityID)
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, fmt.Errorf("%w: from must be before to", domain.ErrInvalidAuditFilter)
+	}
+
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+
+	offset := (page - 1) * pageSize
+	return au.auditRepo.Fetch(c, filter, pageSize, offset)
+}
@@ -0,0 +1,671 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"Test2/internal/domain"
+)
+
+// Thao tác hàng loạt: UseCase kiểm tra từng phần tử trước, phần tử không hợp lệ được báo lỗi
+// riêng và bỏ qua, các phần tử còn lại được ghi qua Batch API của repository trong một transaction.
+// Cache và ContentHook được xử lý một lần cho cả lô sau khi commit.
+
+// --- HELPERS ---
+
+func checkBatchSize(n int) error {
+	if n == 0 {
+		return domain.ErrEmptyBatch
+	}
+	if n > domain.BatchMaxItems {
+		return fmt.Errorf("%w: %d items (max %d)", domain.ErrBatchTooLarge, n, domain.BatchMaxItems)
+	}
+	return nil
+}
+
+func newBatchResult(n int) *domain.BatchResult {
+	res := &domain.BatchResult{Items: make([]domain.BatchItemResult, n)}
+	for i := range res.Items {
+		res.Items[i].Index = i
+	}
+	return res
+}
+
+func batchSucceed(res *domain.BatchResult, index int, id int64) {
+	res.Items[index].ID = id
+	res.Items[index].Status = domain.BatchItemOK
+	res.Succeeded++
+}
+
+func batchFail(res *domain.BatchResult, index int, id int64, err error) {
+	res.Items[index].ID = id
+	res.Items[index].Status = domain.BatchItemFailed
+	res.Items[index].Error = err.Error()
+	res.Failed++
+}
+
+// batchIDs đánh dấu lỗi cho id không hợp lệ hoặc lặp lại, trả về vị trí và giá trị các id cần xử lý
+func batchIDs(res *domain.BatchResult, ids []int64) ([]int, []int64) {
+	seen := make(map[int64]bool, len(ids))
+	indexes := make([]int, 0, len(ids))
+	unique := make([]int64, 0, len(ids))
+	for i, id := range ids {
+		switch {
+		case id <= 0:
+			batchFail(res, i, id, fmt.Errorf("invalid id"))
+		case seen[id]:
+			batchFail(res, i, id, fmt.Errorf("duplicate id %d", id))
+		default:
+			seen[id] = true
+			indexes = append(indexes, i)
+			unique = append(unique, id)
+		}
+	}
+	return indexes, unique
+}
+
+// writablePostStatus là các trạng thái client được phép gán, Deleted chỉ đạt được qua Delete
+func writablePostStatus(status string) bool {
+	switch status {
+	case domain.StatusDraft, domain.StatusPending, domain.StatusPublished:
+		return true
+	}
+	return false
+}
+
+// --- POST ---
+
+// Helper: Gọi ContentHook cho cả lô, hook hỗ trợ BatchContentHook chỉ được gọi một lần
+func (pu *postUseCase) notifyBatch(ctx context.Context, action string, changes []domain.PostChange) {
+	if len(changes) == 0 {
+		return
+	}
+	for _, h := range pu.hooks {
+		if bh, ok := h.(domain.BatchContentHook); ok {
+			bh.PostsChanged(ctx, action, changes)
+			continue
+		}
+		for _, ch := range changes {
+			h.PostChanged(ctx, action, ch.Before, ch.After)
+		}
+	}
+}
+
+// Helper: Xóa cache chi tiết và cache feed của cả lô, mỗi loại cache một lệnh
+func (pu *postUseCase) invalidatePostBatch(ctx context.Context, changes []domain.PostChange) {
+	if len(changes) == 0 {
+		return
+	}
+
+	detailKeys := make([]string, 0, len(changes))
+	seenCategory := map[int64]bool{}
+	categoryIDs := make([]*int64, 0)
+	for _, ch := range changes {
+		for _, p := range []*domain.Post{ch.Before, ch.After} {
+			if p == nil || p.CategoryID == nil || seenCategory[*p.CategoryID] {
+				continue
+			}
+			seenCategory[*p.CategoryID] = true
+			categoryIDs = append(categoryIDs, p.CategoryID)
+		}
+		if ch.Before != nil {
+			detailKeys = append(detailKeys, fmt.Sprintf("post:detail:%d", ch.Before.ID))
+		}
+	}
+
+	pu.invalidatePostListCache(ctx)
+	if len(detailKeys) > 0 {
+		_ = pu.cache.Delete(ctx, detailKeys...)
+	}
+	pu.invalidateFeedCache(ctx, categoryIDs...)
+}
+
+func (pu *postUseCase) StoreBatch(ctx context.Context, posts []domain.Post) (*domain.BatchResult, error) {
+	if err := checkBatchSize(len(posts)); err != nil {
+		return nil, err
+	}
+
+	c, cancel := context.WithTimeout(ctx, pu.contextTimeout)
+	defer cancel()
+
+	res := newBatchResult(len(posts))
+	now := time.Now()
+	indexes := make([]int, 0, len(posts))
+	accepted := make([]*domain.Post, 0, len(posts))
+	for i := range posts {
+		p := &posts[i]
+		p.ID = 0
+		if p.Status != "" && !writablePostStatus(p.Status) {
+			batchFail(res, i, 0, fmt.Errorf("%w: %q", domain.ErrInvalidStatus, p.Status))
+			continue
+		}
+		if err := pu.prepareNewPost(c, p, now); err != nil {
+			batchFail(res, i, 0, err)
+			continue
+		}
+		indexes = append(indexes, i)
+		accepted = append(accepted, p)
+	}
+
+	if len(accepted) == 0 {
+		return res, nil
+	}
+	changes := make([]domain.PostChange, len(accepted))
+	for k, p := range accepted {
+		changes[k] = domain.PostChange{After: p}
+	}
+	err := pu.events.write(c, func(tc context.Context) ([]domain.DomainEvent, error) {
+		if err := pu.postRepo.StoreBatch(tc, accepted); err != nil {
+			return nil, err
+		}
+		return pu.events.posts(tc, domain.ActionCreate, changes)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for k, p := range accepted {
+		batchSucceed(res, indexes[k], p.ID)
+	}
+	pu.invalidatePostBatch(c, changes)
+	pu.notifyBatch(c, domain.ActionCreate, changes)
+	return res, nil
+}
+
+func (pu *postUseCase) UpdateStatusBatch(ctx context.Context, ids []int64, status string) (*domain.BatchResult, error) {
+	if err := checkBatchSize(len(ids)); err != nil {
+		return nil, err
+	}
+	if !writablePostStatus(status) {
+		return nil, fmt.Errorf("%w: %q (expected Draft, Pending or Published)", domain.ErrInvalidStatus, status)
+	}
+
+	c, cancel := context.WithTimeout(ctx, pu.contextTimeout)
+	defer cancel()
+
+	res := newBatchResult(len(ids))
+	indexes, unique := batchIDs(res, ids)
+
+	var changedIndexes []int
+	var changes []domain.PostChange
+	// Đọc và khóa bài viết trong transaction để bản trước thay đổi (audit, outbox) là bản đang được ghi đè
+	err := pu.events.write(c, func(tc context.Context) ([]domain.DomainEvent, error) {
+		existing, err := pu.postRepo.GetByIDsForUpdate(tc, unique)
+		if err != nil {
+			return nil, err
+		}
+		byID := make(map[int64]*domain.Post, len(existing))
+		for i := range existing {
+			byID[existing[i].ID] = &existing[i]
+		}
+
+		now := time.Now()
+		changedIndexes = make([]int, 0, len(indexes))
+		changes = make([]domain.PostChange, 0, len(indexes))
+		updated := make([]*domain.Post, 0, len(indexes))
+		for _, i := range indexes {
+			before, ok := byID[ids[i]]
+			if !ok {
+				batchFail(res, i, ids[i], fmt.Errorf("post not found"))
+				continue
+			}
+			if before.Status == status {
+				batchSucceed(res, i, ids[i])
+				continue
+			}
+
+			after := *before
+			after.Status = status
+			after.UpdateDate = now
+			if status == domain.StatusPublished && after.PublishDate == nil {
+				after.PublishDate = &now
+			}
+			changedIndexes = append(changedIndexes, i)
+			updated = append(updated, &after)
+			changes = append(changes, domain.PostChange{Before: before, After: &after})
+		}
+
+		if len(updated) == 0 {
+			return nil, nil
+		}
+		if err := pu.postRepo.UpdateStatusBatch(tc, updated); err != nil {
+			return nil, err
+		}
+		return pu.events.posts(tc, domain.ActionUpdate, changes)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 {
+		return res, nil
+	}
+
+	for _, i := range changedIndexes {
+		batchSucceed(res, i, ids[i])
+	}
+	pu.invalidatePostBatch(c, changes)
+	pu.notifyBatch(c, domain.ActionUpdate, changes)
+	return res, nil
+}
+
+func (pu *postUseCase) DeleteBatch(ctx context.Context, ids []int64) (*domain.BatchResult, error) {
+	if err := checkBatchSize(len(ids)); err != nil {
+		return nil, err
+	}
+
+	c, cancel := context.WithTimeout(ctx, pu.contextTimeout)
+	defer cancel()
+
+	res := newBatchResult(len(ids))
+	indexes, unique := batchIDs(res, ids)
+
+	var acceptedIndexes []int
+	var changes []domain.PostChange
+	err := pu.events.write(c, func(tc context.Context) ([]domain.DomainEvent, error) {
+		existing, err := pu.postRepo.GetByIDsForUpdate(tc, unique)
+		if err != nil {
+			return nil, err
+		}
+		byID := make(map[int64]*domain.Post, len(existing))
+		for i := range existing {
+			byID[existing[i].ID] = &existing[i]
+		}
+
+		acceptedIndexes = make([]int, 0, len(indexes))
+		accepted := make([]int64, 0, len(indexes))
+		changes = make([]domain.PostChange, 0, len(indexes))
+		for _, i := range indexes {
+			before, ok := byID[ids[i]]
+			if !ok {
+				batchFail(res, i, ids[i], fmt.Errorf("post not found"))
+				continue
+			}
+			acceptedIndexes = append(acceptedIndexes, i)
+			accepted = append(accepted, ids[i])
+			changes = append(changes, domain.PostChange{Before: before})
+		}
+
+		if len(accepted) == 0 {
+			return nil, nil
+		}
+		if err := pu.postRepo.DeleteBatch(tc, accepted); err != nil {
+			return nil, err
+		}
+		return pu.events.posts(tc, domain.ActionDelete, changes)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 {
+		return res, nil
+	}
+
+	for _, i := range acceptedIndexes {
+		batchSucceed(res, i, ids[i])
+	}
+	pu.invalidatePostBatch(c, changes)
+	pu.notifyBatch(c, domain.ActionDelete, changes)
+	return res, nil
+}
+
+func (pu *postUseCase) RestoreBatch(ctx context.Context, ids []int64) (*domain.BatchResult, error) {
+	if err := checkBatchSize(len(ids)); err != nil {
+		return nil, err
+	}
+
+	c, cancel := context.WithTimeout(ctx, pu.contextTimeout)
+	defer cancel()
+
+	res := newBatchResult(len(ids))
+	indexes, unique := batchIDs(res, ids)
+
+	trashed, err := pu.postRepo.GetTrashedByIDs(c, unique)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]*domain.Post, len(trashed))
+	for i := range trashed {
+		byID[trashed[i].ID] = &trashed[i]
+	}
+
+	acceptedIndexes := make([]int, 0, len(indexes))
+	accepted := make([]int64, 0, len(indexes))
+	for _, i := range indexes {
+		if _, ok := byID[ids[i]]; !ok {
+			batchFail(res, i, ids[i], fmt.Errorf("post not found in trash"))
+			continue
+		}
+		acceptedIndexes = append(acceptedIndexes, i)
+		accepted = append(accepted, ids[i])
+	}
+
+	if len(accepted) == 0 {
+		return res, nil
+	}
+	var changes []domain.PostChange
+	err = pu.events.write(c, func(tc context.Context) ([]domain.DomainEvent, error) {
+		if err := pu.postRepo.RestoreBatch(tc, accepted); err != nil {
+			return nil, err
+		}
+
+		restored, err := pu.postRepo.GetByIDs(tc, accepted)
+		if err != nil {
+			return nil, err
+		}
+		changes = make([]domain.PostChange, 0, len(restored))
+		for i := range restored {
+			changes = append(changes, domain.PostChange{Before: byID[restored[i].ID], After: &restored[i]})
+		}
+		return pu.events.posts(tc, domain.ActionRestore, changes)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, i := range acceptedIndexes {
+		batchSucceed(res, i, ids[i])
+	}
+	pu.invalidatePostBatch(c, changes)
+	pu.notifyBatch(c, domain.ActionRestore, changes)
+	return res, nil
+}
+
+// --- CATEGORY ---
+
+// Helper: Xóa cache feed và gọi ContentHook cho cả lô, cache cây danh mục do nơi gọi xóa một lần
+func (cu *cateUseCase) notifyBatch(ctx context.Context, action string, changes []domain.CategoryChange) {
+	if len(changes) == 0 {
+		return
+	}
+	cu.invalidateFeedCache(ctx, changes)
+	for _, h := range cu.hooks {
+		if bh, ok := h.(domain.BatchContentHook); ok {
+			bh.CategoriesChanged(ctx, action, changes)
+			continue
+		}
+		for _, ch := range changes {
+			h.CategoryChanged(ctx, action, ch.Before, ch.After)
+		}
+	}
+}
+
+func (cu *cateUseCase) StoreBatch(ctx context.Context, categories []domain.Category) (*domain.BatchResult, error) {
+	if err := checkBatchSize(len(categories)); err != nil {
+		return nil, err
+	}
+
+	p, cancel := context.WithTimeout(ctx, cu.contextTimeout)
+	defer cancel()
+
+	res := newBatchResult(len(categories))
+	now := time.Now()
+	// position kế tiếp của từng danh mục cha, các danh mục cùng cha trong lô được xếp nối tiếp nhau
+	nextPosition := map[int64]int{}
+	indexes := make([]int, 0, len(categories))
+	accepted := make([]*domain.Category, 0, len(categories))
+	for i := range categories {
+		c := &categories[i]
+		c.ID = 0
+		if c.Status != "" && c.Status != domain.CategoryStatusActive {
+			batchFail(res, i, 0, fmt.Errorf("%w: %q", domain.ErrInvalidStatus, c.Status))
+			continue
+		}
+		if err := cu.prepareNewCategory(p, c, now); err != nil {
+			batchFail(res, i, 0, err)
+			continue
+		}
+
+		var parentKey int64
+		if c.ParentID != nil {
+			parentKey = *c.ParentID
+		}
+		position, ok := nextPosition[parentKey]
+		if !ok {
+			next, err := cu.cateRepo.NextPosition(p, c.ParentID)
+			if err != nil {
+				return nil, err
+			}
+			position = next
+		}
+		c.Position = position
+		nextPosition[parentKey] = position + 1
+
+		indexes = append(indexes, i)
+		accepted = append(accepted, c)
+	}
+
+	if len(accepted) == 0 {
+		return res, nil
+	}
+	changes := make([]domain.CategoryChange, len(accepted))
+	for k, c := range accepted {
+		changes[k] = domain.CategoryChange{After: c}
+	}
+	err := cu.events.write(p, func(tc context.Context) ([]domain.DomainEvent, error) {
+		if err := cu.cateRepo.StoreBatch(tc, accepted); err != nil {
+			return nil, err
+		}
+		return cu.events.categories(tc, domain.ActionCreate, changes)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for k, c := range accepted {
+		batchSucceed(res, indexes[k], c.ID)
+	}
+	cu.invalidateTreeCache(p)
+	cu.notifyBatch(p, domain.ActionCreate, changes)
+	return res, nil
+}
+
+func (cu *cateUseCase) DeleteBatch(ctx context.Context, ids []int64) (*domain.BatchResult, error) {
+	if err := checkBatchSize(len(ids)); err != nil {
+		return nil, err
+	}
+
+	p, cancel := context.WithTimeout(ctx, cu.contextTimeout)
+	defer cancel()
+
+	res := newBatchResult(len(ids))
+	indexes, unique := batchIDs(res, ids)
+
+	existing, err := cu.cateRepo.GetByIDs(p, unique)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]*domain.Category, len(existing))
+	for i := range existing {
+		byID[existing[i].ID] = &existing[i]
+	}
+
+	candidates := make(map[int64]int, len(indexes)) // id -> vị trí trong request
+	children := make(map[int64][]domain.Category, len(indexes))
+	for _, i := range indexes {
+		if _, ok := byID[ids[i]]; !ok {
+			batchFail(res, i, ids[i], domain.ErrCategoryNotFound)
+			continue
+		}
+		kids, err := cu.cateRepo.FetchChildren(p, &ids[i])
+		if err != nil {
+			return nil, err
+		}
+		candidates[ids[i]] = i
+		children[ids[i]] = kids
+	}
+
+	// Chính sách block: danh mục chỉ được xóa khi mọi danh mục con cũng bị xóa trong cùng lô.
+	// Loại một danh mục có thể kéo theo danh mục cha của nó nên lặp tới khi ổn định.
+	if cu.childPolicy == domain.CategoryChildPolicyBlock {
+		for changed := true; changed; {
+			changed = false
+			for id, i := range candidates {
+				remaining := 0
+				for _, kid := range children[id] {
+					if _, ok := candidates[kid.ID]; !ok {
+						remaining++
+					}
+				}
+				if remaining > 0 {
+					batchFail(res, i, id, fmt.Errorf("%w: %d children", domain.ErrCategoryHasChildren, remaining))
+					delete(candidates, id)
+					changed = true
+				}
+			}
+		}
+	}
+
+	accepted := make([]int64, 0, len(candidates))
+	deletes := make([]domain.CategoryChange, 0, len(candidates))
+	for _, i := range indexes {
+		if _, ok := candidates[ids[i]]; ok {
+			accepted = append(accepted, ids[i])
+			deletes = append(deletes, domain.CategoryChange{Before: byID[ids[i]]})
+		}
+	}
+
+	if len(accepted) == 0 {
+		return res, nil
+	}
+	reparent := cu.childPolicy == domain.CategoryChildPolicyReparent
+
+	// Danh mục con còn lại được đưa lên tổ tiên gần nhất không bị xóa trong lô
+	moves := make([]domain.CategoryChange, 0)
+	if reparent {
+		for _, id := range accepted {
+			for _, kid := range children[id] {
+				if _, ok := candidates[kid.ID]; ok {
+					continue
+				}
+				newParent := byID[id].ParentID
+				for newParent != nil {
+					if _, ok := candidates[*newParent]; !ok {
+						break
+					}
+					newParent = byID[*newParent].ParentID
+				}
+				before := kid
+				after := kid
+				after.ParentID = newParent
+				moves = append(moves, domain.CategoryChange{Before: &before, After: &after})
+			}
+		}
+	}
+
+	err = cu.events.write(p, func(tc context.Context) ([]domain.DomainEvent, error) {
+		if err := cu.cateRepo.DeleteBatch(tc, accepted, reparent); err != nil {
+			return nil, err
+		}
+		events, err := cu.events.categories(tc, domain.ActionDelete, deletes)
+		if err != nil {
+			return nil, err
+		}
+		moveEvents, err := cu.events.categories(tc, domain.ActionUpdate, moves)
+		if err != nil {
+			return nil, err
+		}
+		return append(events, moveEvents...), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range accepted {
+		batchSucceed(res, candidates[id], id)
+	}
+	cu.invalidateTreeCache(p)
+	cu.notifyBatch(p, domain.ActionDelete, deletes)
+	cu.notifyBatch(p, domain.ActionUpdate, moves)
+	return res, nil
+}
+
+func (cu *cateUseCase) RestoreBatch(ctx context.Context, ids []int64) (*domain.BatchResult, error) {
+	if err := checkBatchSize(len(ids)); err != nil {
+		return nil, err
+	}
+
+	p, cancel := context.WithTimeout(ctx, cu.contextTimeout)
+	defer cancel()
+
+	res := newBatchResult(len(ids))
+	indexes, unique := batchIDs(res, ids)
+
+	trashed, err := cu.cateRepo.GetTrashedByIDs(p, unique)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]*domain.Category, len(trashed))
+	parentIDs := make([]int64, 0, len(trashed))
+	for i := range trashed {
+		byID[trashed[i].ID] = &trashed[i]
+		if trashed[i].ParentID != nil {
+			parentIDs = append(parentIDs, *trashed[i].ParentID)
+		}
+	}
+
+	activeParents, err := cu.cateRepo.GetByIDs(p, parentIDs)
+	if err != nil {
+		return nil, err
+	}
+	parentOK := make(map[int64]bool, len(activeParents)+len(trashed))
+	for _, parent := range activeParents {
+		parentOK[parent.ID] = true
+	}
+	for _, c := range trashed {
+		// Danh mục cha được khôi phục trong cùng lô vẫn giữ được quan hệ cha con
+		parentOK[c.ID] = true
+	}
+
+	acceptedIndexes := make([]int, 0, len(indexes))
+	accepted := make([]*domain.Category, 0, len(indexes))
+	for _, i := range indexes {
+		before, ok := byID[ids[i]]
+		if !ok {
+			batchFail(res, i, ids[i], fmt.Errorf("category not found in trash"))
+			continue
+		}
+		target := *before
+		// Giống Restore: danh mục cha không còn tồn tại -> khôi phục thành danh mục gốc
+		if target.ParentID != nil && !parentOK[*target.ParentID] {
+			target.ParentID = nil
+		}
+		acceptedIndexes = append(acceptedIndexes, i)
+		accepted = append(accepted, &target)
+	}
+
+	if len(accepted) == 0 {
+		return res, nil
+	}
+	restoredIDs := make([]int64, len(accepted))
+	for k, c := range accepted {
+		restoredIDs[k] = c.ID
+	}
+
+	var changes []domain.CategoryChange
+	err = cu.events.write(p, func(tc context.Context) ([]domain.DomainEvent, error) {
+		if err := cu.cateRepo.RestoreBatch(tc, accepted); err != nil {
+			return nil, err
+		}
+
+		restored, err := cu.cateRepo.GetByIDs(tc, restoredIDs)
+		if err != nil {
+			return nil, err
+		}
+		changes = make([]domain.CategoryChange, 0, len(restored))
+		for i := range restored {
+			changes = append(changes, domain.CategoryChange{Before: byID[restored[i].ID], After: &restored[i]})
+		}
+		return cu.events.categories(tc, domain.ActionRestore, changes)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, i := range acceptedIndexes {
+		batchSucceed(res, i, ids[i])
+	}
+	cu.invalidateTreeCache(p)
+	cu.notifyBatch(p, domain.ActionRestore, changes)
+	return res, nil
+}
@@ -0,0 +1,255 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"Test2/internal/domain"
+)
+
+const categoryTreeCacheKey = "categories:tree"
+
+// Helper: Xóa cache cây danh mục, được gọi sau mọi thao tác ghi
+func (cu *cateUseCase) invalidateTreeCache(ctx context.Context) {
+	_ = cu.rawCache.Delete(ctx, categoryTreeCacheKey)
+}
+
+// Helper: Xóa cache feed của các danh mục bị đổi (tiêu đề, mô tả, trạng thái nằm trong feed của danh mục)
+func (cu *cateUseCase) invalidateFeedCache(ctx context.Context, changes []domain.CategoryChange) {
+	var keys []string
+	seen := map[int64]bool{}
+	for _, ch := range changes {
+		for _, c := range []*domain.Category{ch.Before, ch.After} {
+			if c == nil || seen[c.ID] {
+				continue
+			}
+			seen[c.ID] = true
+			keys = append(keys, feedCacheKeys(c.ID)...)
+		}
+	}
+	if len(keys) > 0 {
+		_ = cu.rawCache.Delete(ctx, keys...)
+	}
+}
+
+func sameParent(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// validateParent kiểm tra danh mục cha tồn tại và không tạo thành chu trình.
+// Đi ngược từ danh mục cha mới lên gốc, nếu gặp lại chính c thì c đang bị đặt dưới con cháu của nó.
+func (cu *cateUseCase) validateParent(ctx context.Context, c *domain.Category) error {
+	if c.ParentID == nil {
+		return nil
+	}
+
+	visited := map[int64]bool{}
+	current := *c.ParentID
+	for {
+		if c.ID != 0 && current == c.ID {
+			return domain.ErrCategoryCycle
+		}
+		if visited[current] {
+			// Dữ liệu cũ đã có chu trình, không cho phép gắn thêm vào nhánh này
+			return domain.ErrCategoryCycle
+		}
+		visited[current] = true
+
+		parent, err := cu.cateRepo.GetByID(ctx, current)
+		if err != nil {
+			if current == *c.ParentID {
+				return fmt.Errorf("%w: %d", domain.ErrParentCategoryNotFound, current)
+			}
+			return err
+		}
+		if parent.ParentID == nil {
+			return nil
+		}
+		current = *parent.ParentID
+	}
+}
+
+// detachChildren áp dụng childPolicy cho các danh mục con trực tiếp của parent,
+// trả về các danh mục con đã được chuyển để nơi gọi thông báo sau khi commit
+func (cu *cateUseCase) detachChildren(ctx context.Context, parent *domain.Category) ([]domain.CategoryChange, error) {
+	children, err := cu.cateRepo.FetchChildren(ctx, &parent.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(children) == 0 {
+		return nil, nil
+	}
+
+	if cu.childPolicy == domain.CategoryChildPolicyBlock {
+		return nil, fmt.Errorf("%w: %d children", domain.ErrCategoryHasChildren, len(children))
+	}
+
+	if err := cu.cateRepo.ReparentChildren(ctx, parent.ID, parent.ParentID); err != nil {
+		return nil, err
+	}
+
+	moved := make([]domain.CategoryChange, 0, len(children))
+	for i := range children {
+		before := children[i]
+		after := children[i]
+		after.ParentID = parent.ParentID
+		moved = append(moved, domain.CategoryChange{Before: &before, After: &after})
+	}
+	return moved, nil
+}
+
+func (cu *cateUseCase) Tree(ctx context.Context) ([]domain.CategoryNode, error) {
+	c, cancel := context.WithTimeout(ctx, cu.contextTimeout)
+	defer cancel()
+
+	if cached, found := cu.rawCache.Get(c, categoryTreeCacheKey); found {
+		var tree []domain.CategoryNode
+		if err := json.Unmarshal(cached, &tree); err == nil {
+			return tree, nil
+		}
+	}
+
+	categories, err := cu.cateRepo.FetchAll(c)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cu.attachMedia(c, categories); err != nil {
+		return nil, err
+	}
+
+	tree := buildCategoryTree(categories)
+
+	if data, err := json.Marshal(tree); err == nil {
+		if err := cu.rawCache.Set(c, categoryTreeCacheKey, data, cu.ttl.Load().CategoryTree); err != nil && !errors.Is(err, domain.ErrCacheUnavailable) {
+			slog.WarnContext(c, "category: failed to cache tree", "error", err)
+		}
+	}
+	return tree, nil
+}
+
+func (cu *cateUseCase) Breadcrumb(ctx context.Context, id int64) ([]domain.Category, error) {
+	tree, err := cu.Tree(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	path := findCategoryPath(tree, id)
+	if path == nil {
+		return nil, domain.ErrCategoryNotFound
+	}
+	return path, nil
+}
+
+// buildCategoryTree dựng cây từ danh sách phẳng (đã sắp xếp), danh mục có cha
+// không còn Active được đưa lên làm gốc để không bị mất khỏi cây
+func buildCategoryTree(categories []domain.Category) []domain.CategoryNode {
+	exists := make(map[int64]bool, len(categories))
+	for _, c := range categories {
+		exists[c.ID] = true
+	}
+
+	children := make(map[int64][]domain.Category)
+	roots := make([]domain.Category, 0)
+	for _, c := range categories {
+		if c.ParentID == nil || !exists[*c.ParentID] || *c.ParentID == c.ID {
+			roots = append(roots, c)
+			continue
+		}
+		children[*c.ParentID] = append(children[*c.ParentID], c)
+	}
+
+	var build func(items []domain.Category, depth int) []domain.CategoryNode
+	build = func(items []domain.Category, depth int) []domain.CategoryNode {
+		nodes := make([]domain.CategoryNode, 0, len(items))
+		for _, c := range items {
+			node := domain.CategoryNode{Category: c, Children: []domain.CategoryNode{}}
+			// Chặn đệ quy vô hạn nếu dữ liệu cũ có chu trình
+			if depth < len(categories) {
+				node.Children = build(children[c.ID], depth+1)
+			}
+			nodes = append(nodes, node)
+		}
+		return nodes
+	}
+
+	return build(roots, 0)
+}
+
+// findCategoryPath trả về đường đi từ gốc tới id (DFS), nil nếu không tìm thấy
+func findCategoryPath(nodes []domain.CategoryNode, id int64) []domain.Category {
+	for _, n := range nodes {
+		if n.ID == id {
+			return []domain.Category{n.Category}
+		}
+		if sub := findCategoryPath(n.Children, id); sub != nil {
+			return append([]domain.Category{n.Category}, sub...)
+		}
+	}
+	return nil
+}
+
+// Reorder yêu cầu ids là hoán vị đầy đủ các danh mục con hiện có của parentID,
+// tránh trường hợp client gửi danh sách cũ và làm lệch thứ tự của các danh mục còn lại
+func (cu *cateUseCase) Reorder(ctx context.Context, parentID *int64, ids []int64) error {
+	c, cancel := context.WithTimeout(ctx, cu.contextTimeout)
+	defer cancel()
+
+	children, err := cu.cateRepo.FetchChildren(c, parentID)
+	if err != nil {
+		return err
+	}
+
+	current := make(map[int64]domain.Category, len(children))
+	for _, child := range children {
+		current[child.ID] = child
+	}
+
+	if len(ids) != len(children) {
+		return fmt.Errorf("%w: expected %d ids, got %d", domain.ErrInvalidReorder, len(children), len(ids))
+	}
+	seen := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		if _, ok := current[id]; !ok {
+			return fmt.Errorf("%w: category %d is not a child of the given parent", domain.ErrInvalidReorder, id)
+		}
+		if seen[id] {
+			return fmt.Errorf("%w: duplicate id %d", domain.ErrInvalidReorder, id)
+		}
+		seen[id] = true
+	}
+
+	changes := make([]domain.CategoryChange, 0, len(ids))
+	for i, id := range ids {
+		before := current[id]
+		if before.Position == i+1 {
+			continue
+		}
+		after := before
+		after.Position = i + 1
+		changes = append(changes, domain.CategoryChange{Before: &before, After: &after})
+	}
+
+	err = cu.events.write(c, func(tc context.Context) ([]domain.DomainEvent, error) {
+		if err := cu.cateRepo.Reorder(tc, parentID, ids); err != nil {
+			return nil, err
+		}
+		return cu.events.categories(tc, domain.ActionUpdate, changes)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, ch := range changes {
+		cu.notify(c, domain.ActionUpdate, ch.Before, ch.After)
+	}
+	// Cây luôn phải được làm mới kể cả khi không danh mục nào đổi vị trí thực sự
+	cu.invalidateTreeCache(c)
+	return nil
+}
@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"Test2/internal/domain"
@@ -9,17 +10,108 @@ import (
 
 type cateUseCase struct {
 	cateRepo       domain.CategoryRepository
+	media          domain.MediaUseCase
+	rawCache       domain.RawCacheRepository
+	childPolicy    string
+	events         eventWriter
+	ttl            *CacheTTLs
 	contextTimeout time.Duration
+	hooks          []domain.ContentHook
 }
 
-func NewCateUseCase(repo domain.CategoryRepository, timeout time.Duration) domain.CategoryUseCase {
+// NewCateUseCase khởi tạo CategoryUseCase, childPolicy quyết định cách xử lý danh mục con
+// khi di chuyển hoặc xóa danh mục cha (domain.CategoryChildPolicyReparent / Block)
+func NewCateUseCase(
+	repo domain.CategoryRepository,
+	media domain.MediaUseCase,
+	rawCache domain.RawCacheRepository,
+	childPolicy string,
+	tx domain.Transactor,
+	outbox domain.OutboxRepository,
+	audit domain.AuditUseCase,
+	ttl *CacheTTLs,
+	timeout time.Duration,
+	hooks ...domain.ContentHook,
+) domain.CategoryUseCase {
+	if childPolicy != domain.CategoryChildPolicyReparent {
+		childPolicy = domain.CategoryChildPolicyBlock
+	}
 	return &cateUseCase{
 		cateRepo:       repo,
+		media:          media,
+		rawCache:       rawCache,
+		childPolicy:    childPolicy,
+		events:         eventWriter{tx: tx, outbox: outbox, audit: audit},
+		ttl:            ttl,
 		contextTimeout: timeout,
+		hooks:          hooks,
+	}
+}
+
+// Helper: Xóa cache cây danh mục, feed của danh mục và thông báo thay đổi cho các ContentHook (sitemap, ...)
+func (cu *cateUseCase) notify(ctx context.Context, action string, before, after *domain.Category) {
+	cu.invalidateTreeCache(ctx)
+	cu.invalidateFeedCache(ctx, []domain.CategoryChange{{Before: before, After: after}})
+	for _, h := range cu.hooks {
+		h.CategoryChanged(ctx, action, before, after)
+	}
+}
+
+// Helper: Gắn media (URL ảnh gốc và các biến thể) cho danh sách danh mục
+func (cu *cateUseCase) attachMedia(ctx context.Context, categories []domain.Category) error {
+	ids := make([]int64, 0, len(categories))
+	for _, c := range categories {
+		if c.MediaID != nil {
+			ids = append(ids, *c.MediaID)
+		}
+	}
+
+	found, err := cu.media.Resolve(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range categories {
+		if categories[i].MediaID != nil {
+			categories[i].Media = found[*categories[i].MediaID]
+		}
+	}
+	return nil
+}
+
+// Helper: Kiểm tra media_id hợp lệ và đồng bộ trường Thumbnail với URL ảnh gốc
+func (cu *cateUseCase) applyMedia(ctx context.Context, c *domain.Category) error {
+	md, err := resolveMediaRef(ctx, cu.media, c.MediaID)
+	if err != nil {
+		return err
+	}
+	if md != nil {
+		c.Media = md
+		c.Thumbnail = md.URL
 	}
+	return nil
 }
 
-func (cu *cateUseCase) Fetch(ctx context.Context, page int64, pageSize int64) ([]domain.Category, error) {
+// Helper: Chuẩn hóa danh mục mới trước khi ghi (trạng thái mặc định, media, danh mục cha, các mốc thời gian)
+func (cu *cateUseCase) prepareNewCategory(ctx context.Context, c *domain.Category, now time.Time) error {
+	if c.Status == "" {
+		c.Status = domain.CategoryStatusActive
+	}
+
+	if err := cu.applyMedia(ctx, c); err != nil {
+		return err
+	}
+
+	if err := cu.validateParent(ctx, c); err != nil {
+		return err
+	}
+
+	c.CreatedAt = now
+	c.UpdatedAt = now
+	return nil
+}
+
+func (cu *cateUseCase) Fetch(ctx context.Context, page int64, pageSize int64, sort string) ([]domain.Category, error) {
 	c, cancel := context.WithTimeout(ctx, cu.contextTimeout)
 	defer cancel()
 
@@ -30,48 +122,169 @@ func (cu *cateUseCase) Fetch(ctx context.Context, page int64, pageSize int64) ([
 		pageSize = 10
 	}
 
+	switch sort {
+	case "":
+		sort = domain.CategorySortNewest
+	case domain.CategorySortNewest, domain.CategorySortOldest, domain.CategorySortTitle, domain.CategorySortPosition:
+	default:
+		return nil, fmt.Errorf("%w: %q", domain.ErrInvalidCategorySort, sort)
+	}
+
 	offset := (page - 1) * pageSize
-	return cu.cateRepo.Fetch(c, pageSize, offset)
+	categories, err := cu.cateRepo.Fetch(c, pageSize, offset, sort)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cu.attachMedia(c, categories); err != nil {
+		return nil, err
+	}
+	return categories, nil
 }
 
 func (cu *cateUseCase) Store(ctx context.Context, c *domain.Category) error {
 	p, cancel := context.WithTimeout(ctx, cu.contextTimeout)
 	defer cancel()
 
-	if c.Status == "" {
-		c.Status = domain.CategoryStatusActive
+	if err := cu.prepareNewCategory(p, c, time.Now()); err != nil {
+		return err
 	}
 
-	now := time.Now()
-	c.CreatedAt = now
-	c.UpdatedAt = now
+	// Danh mục mới luôn được thêm vào cuối danh mục cha
+	position, err := cu.cateRepo.NextPosition(p, c.ParentID)
+	if err != nil {
+		return err
+	}
+	c.Position = position
+
+	err = cu.events.write(p, func(tc context.Context) ([]domain.DomainEvent, error) {
+		if err := cu.cateRepo.Store(tc, c); err != nil {
+			return nil, err
+		}
+		return cu.events.categories(tc, domain.ActionCreate, []domain.CategoryChange{{After: c}})
+	})
+	if err != nil {
+		return err
+	}
 
-	return cu.cateRepo.Store(p, c)
+	cu.notify(p, domain.ActionCreate, nil, c)
+	return nil
 }
 
 func (cu *cateUseCase) Delete(ctx context.Context, id int64) error {
 	p, cancel := context.WithTimeout(ctx, cu.contextTimeout)
 	defer cancel()
 
-	_, err := cu.cateRepo.GetByID(p, id)
+	// Danh mục con được xử lý theo childPolicy trước khi xóa danh mục cha, trong cùng transaction.
+	// Bản ghi trước khi xóa được đọc và khóa trong transaction để không lệch với thao tác ghi đồng thời
+	var existing *domain.Category
+	var moved []domain.CategoryChange
+	err := cu.events.write(p, func(tc context.Context) ([]domain.DomainEvent, error) {
+		var err error
+		if existing, err = cu.cateRepo.GetByIDForUpdate(tc, id); err != nil {
+			return nil, err
+		}
+		if moved, err = cu.detachChildren(tc, existing); err != nil {
+			return nil, err
+		}
+		if err := cu.cateRepo.Delete(tc, id); err != nil {
+			return nil, err
+		}
+		// Danh mục con chỉ bị chuyển sang danh mục cha khác, được ghi nhận là cập nhật
+		events, err := cu.events.categories(tc, domain.ActionUpdate, moved)
+		if err != nil {
+			return nil, err
+		}
+		deleteEvents, err := cu.events.categories(tc, domain.ActionDelete, []domain.CategoryChange{{Before: existing}})
+		if err != nil {
+			return nil, err
+		}
+		return append(events, deleteEvents...), nil
+	})
 	if err != nil {
 		return err
 	}
 
-	return cu.cateRepo.Delete(p, id)
+	for _, ch := range moved {
+		cu.notify(p, domain.ActionUpdate, ch.Before, ch.After)
+	}
+	cu.notify(p, domain.ActionDelete, existing, nil)
+	return nil
 }
 
 func (cu *cateUseCase) GetByID(ctx context.Context, id int64) (*domain.Category, error) {
 	p, cancel := context.WithTimeout(ctx, cu.contextTimeout)
 	defer cancel()
 
-	return cu.cateRepo.GetByID(p, id)
+	c, err := cu.cateRepo.GetByID(p, id)
+	if err != nil {
+		return nil, err
+	}
+
+	categories := []domain.Category{*c}
+	if err := cu.attachMedia(p, categories); err != nil {
+		return nil, err
+	}
+	return &categories[0], nil
 }
 
 func (cu *cateUseCase) Update(ctx context.Context, c *domain.Category) error {
 	p, cancel := context.WithTimeout(ctx, cu.contextTimeout)
 	defer cancel()
 
-	c.UpdatedAt = time.Now()
-	return cu.cateRepo.Update(p, c)
+	switch c.Status {
+	case "", domain.CategoryStatusActive, domain.CategoryStatusInactive:
+	default:
+		return fmt.Errorf("%w: %q (expected Active or Inactive)", domain.ErrInvalidStatus, c.Status)
+	}
+
+	if err := cu.applyMedia(p, c); err != nil {
+		return err
+	}
+
+	// Bản ghi hiện tại (Before của audit/outbox, position, danh mục cha) được đọc và khóa trong transaction
+	// để hai lần sửa đồng thời không cùng dựa trên một bản cũ
+	var existing *domain.Category
+	var moved []domain.CategoryChange
+	err := cu.events.write(p, func(tc context.Context) ([]domain.DomainEvent, error) {
+		var err error
+		if existing, err = cu.cateRepo.GetByIDForUpdate(tc, c.ID); err != nil {
+			return nil, err
+		}
+
+		// PUT không gửi status thì giữ trạng thái hiện có
+		if c.Status == "" {
+			c.Status = existing.Status
+		}
+		// position chỉ thay đổi qua Reorder, hoặc khi chuyển sang danh mục cha khác
+		c.Position = existing.Position
+		c.CreatedAt = existing.CreatedAt
+		c.UpdatedAt = time.Now()
+
+		if !sameParent(existing.ParentID, c.ParentID) {
+			if err := cu.validateParent(tc, c); err != nil {
+				return nil, err
+			}
+			// Di chuyển danh mục: danh mục con ở lại vị trí cũ (reparent) hoặc bị chặn (block)
+			if moved, err = cu.detachChildren(tc, existing); err != nil {
+				return nil, err
+			}
+			if c.Position, err = cu.cateRepo.NextPosition(tc, c.ParentID); err != nil {
+				return nil, err
+			}
+		}
+		if err := cu.cateRepo.Update(tc, c); err != nil {
+			return nil, err
+		}
+		return cu.events.categories(tc, domain.ActionUpdate, append(moved, domain.CategoryChange{Before: existing, After: c}))
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, ch := range moved {
+		cu.notify(p, domain.ActionUpdate, ch.Before, ch.After)
+	}
+	cu.notify(p, domain.ActionUpdate, existing, c)
+	return nil
 }
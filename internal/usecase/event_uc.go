@@ -0,0 +1,162 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"Test2/internal/domain"
+)
+
+// eventWriter ghi thay đổi dữ liệu và domain event vào outbox trong cùng một transaction.
+// ContentHook (cache, sitemap, audit) vẫn được gọi sau khi commit như trước.
+type eventWriter struct {
+	tx     domain.Transactor
+	outbox domain.OutboxRepository
+	audit  domain.AuditUseCase
+}
+
+// write chạy fn trong transaction, event do fn trả về được ghi vào outbox trước khi commit
+func (w eventWriter) write(ctx context.Context, fn func(ctx context.Context) ([]domain.DomainEvent, error)) error {
+	return w.tx.WithinTx(ctx, func(tc context.Context) error {
+		events, err := fn(tc)
+		if err != nil {
+			return err
+		}
+		return w.outbox.Append(tc, events)
+	})
+}
+
+// posts ghi audit log của thay đổi và trả về các event tương ứng. ctx là context fn nhận từ write
+// nên audit log được commit hoặc rollback cùng thay đổi và outbox.
+func (w eventWriter) posts(ctx context.Context, action string, changes []domain.PostChange) ([]domain.DomainEvent, error) {
+	if err := w.audit.RecordPosts(ctx, action, changes); err != nil {
+		return nil, err
+	}
+	return postEvents(action, changes)
+}
+
+// categories tương tự posts cho Category
+func (w eventWriter) categories(ctx context.Context, action string, changes []domain.CategoryChange) ([]domain.DomainEvent, error) {
+	if err := w.audit.RecordCategories(ctx, action, changes); err != nil {
+		return nil, err
+	}
+	return categoryEvents(action, changes)
+}
+
+// plainPost bỏ các trường được gắn khi đọc (media, HTML đã render), chỉ giữ dữ liệu của bảng posts
+func plainPost(p *domain.Post) *domain.Post {
+	if p == nil {
+		return nil
+	}
+	cp := *p
+	cp.Media = nil
+	cp.ContentHTML = ""
+	return &cp
+}
+
+func plainCategory(c *domain.Category) *domain.Category {
+	if c == nil {
+		return nil
+	}
+	cp := *c
+	cp.Media = nil
+	return &cp
+}
+
+func newDomainEvent(eventType, aggregate string, id int64, payload any, now time.Time) (domain.DomainEvent, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return domain.DomainEvent{}, err
+	}
+	return domain.DomainEvent{
+		Type:          eventType,
+		AggregateType: aggregate,
+		AggregateID:   id,
+		Payload:       data,
+		OccurredAt:    now,
+	}, nil
+}
+
+// postEvents chuyển một thay đổi của Post thành các domain event tương ứng
+func postEvents(action string, changes []domain.PostChange) ([]domain.DomainEvent, error) {
+	now := time.Now()
+	events := make([]domain.DomainEvent, 0, len(changes)*2)
+	add := func(eventType string, id int64, payload domain.PostEventPayload) error {
+		e, err := newDomainEvent(eventType, domain.AggregatePost, id, payload, now)
+		if err != nil {
+			return err
+		}
+		events = append(events, e)
+		return nil
+	}
+
+	for _, ch := range changes {
+		before, after := plainPost(ch.Before), plainPost(ch.After)
+
+		switch action {
+		case domain.ActionDelete, domain.ActionPurge:
+			if before == nil {
+				continue
+			}
+			if err := add(domain.EventPostDeleted, before.ID, domain.PostEventPayload{Action: action, Post: before}); err != nil {
+				return nil, err
+			}
+			// Vào thùng rác cũng là chuyển trạng thái (ví dụ gỡ bài Published)
+			if action == domain.ActionDelete && before.Status != domain.StatusDeleted {
+				payload := domain.PostEventPayload{Action: action, FromStatus: before.Status, ToStatus: domain.StatusDeleted, Post: before}
+				if err := add(domain.EventPostStatusChanged, before.ID, payload); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+
+		if after == nil {
+			continue
+		}
+		switch action {
+		case domain.ActionCreate:
+			if err := add(domain.EventPostCreated, after.ID, domain.PostEventPayload{Action: action, Post: after}); err != nil {
+				return nil, err
+			}
+		case domain.ActionUpdate:
+			if err := add(domain.EventPostUpdated, after.ID, domain.PostEventPayload{Action: action, Post: after}); err != nil {
+				return nil, err
+			}
+		}
+
+		fromStatus := ""
+		if before != nil {
+			fromStatus = before.Status
+		}
+		if fromStatus != after.Status {
+			payload := domain.PostEventPayload{Action: action, FromStatus: fromStatus, ToStatus: after.Status, Post: after}
+			if err := add(domain.EventPostStatusChanged, after.ID, payload); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return events, nil
+}
+
+// categoryEvents chuyển thay đổi của Category thành CategoryChanged
+func categoryEvents(action string, changes []domain.CategoryChange) ([]domain.DomainEvent, error) {
+	now := time.Now()
+	events := make([]domain.DomainEvent, 0, len(changes))
+	for _, ch := range changes {
+		c := plainCategory(ch.After)
+		if c == nil {
+			c = plainCategory(ch.Before)
+		}
+		if c == nil {
+			continue
+		}
+		e, err := newDomainEvent(domain.EventCategoryChanged, domain.AggregateCategory, c.ID, domain.CategoryEventPayload{Action: action, Category: c}, now)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
@@ -0,0 +1,220 @@
+package usecase
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"time"
+)
+
+// feedMeta và feedItem là dạng trung gian, độc lập với định dạng RSS/Atom/JSON Feed
+type feedMeta struct {
+	Title       string
+	Description string
+	HomeURL     string
+	SelfURL     string
+	FeedPath    string
+	Updated     time.Time
+}
+
+type feedItem struct {
+	ID          string
+	URL         string
+	Title       string
+	Summary     string
+	ContentHTML string
+	Image       string
+	Published   time.Time
+	Updated     time.Time
+}
+
+// --- RSS 2.0 ---
+
+type rssDocument struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	AtomNS    string     `xml:"xmlns:atom,attr"`
+	ContentNS string     `xml:"xmlns:content,attr"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	AtomLink      rssLink   `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	Description string   `xml:"description"`
+	Content     rssCDATA `xml:"content:encoded"`
+	PubDate     string   `xml:"pubDate"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+type rssCDATA struct {
+	Value string `xml:",cdata"`
+}
+
+func encodeRSS(meta feedMeta, items []feedItem) ([]byte, error) {
+	doc := rssDocument{
+		Version:   "2.0",
+		AtomNS:    "http://www.w3.org/2005/Atom",
+		ContentNS: "http://purl.org/rss/1.0/modules/content/",
+		Channel: rssChannel{
+			Title:         meta.Title,
+			Link:          meta.HomeURL,
+			Description:   meta.Description,
+			LastBuildDate: meta.Updated.UTC().Format(time.RFC1123Z),
+			AtomLink:      rssLink{Href: meta.SelfURL, Rel: "self", Type: "application/rss+xml"},
+			Items:         make([]rssItem, 0, len(items)),
+		},
+	}
+	if doc.Channel.Description == "" {
+		doc.Channel.Description = meta.Title
+	}
+
+	for _, it := range items {
+		doc.Channel.Items = append(doc.Channel.Items, rssItem{
+			Title:       it.Title,
+			Link:        it.URL,
+			GUID:        rssGUID{Value: it.ID, IsPermaLink: true},
+			Description: it.Summary,
+			Content:     rssCDATA{Value: it.ContentHTML},
+			PubDate:     it.Published.UTC().Format(time.RFC1123Z),
+		})
+	}
+
+	return marshalXML(doc)
+}
+
+// --- Atom 1.0 ---
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	ID       string      `xml:"id"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title     string      `xml:"title"`
+	ID        string      `xml:"id"`
+	Link      atomLink    `xml:"link"`
+	Published string      `xml:"published"`
+	Updated   string      `xml:"updated"`
+	Summary   string      `xml:"summary,omitempty"`
+	Content   atomContent `xml:"content"`
+}
+
+type atomContent struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+func encodeAtom(meta feedMeta, items []feedItem) ([]byte, error) {
+	feed := atomFeed{
+		Title:    meta.Title,
+		Subtitle: meta.Description,
+		ID:       meta.SelfURL,
+		Updated:  meta.Updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: meta.HomeURL, Rel: "alternate", Type: "text/html"},
+			{Href: meta.SelfURL, Rel: "self", Type: "application/atom+xml"},
+		},
+		Entries: make([]atomEntry, 0, len(items)),
+	}
+
+	for _, it := range items {
+		feed.Entries = append(feed.Entries, atomEntry{
+			Title:     it.Title,
+			ID:        it.ID,
+			Link:      atomLink{Href: it.URL, Rel: "alternate"},
+			Published: it.Published.UTC().Format(time.RFC3339),
+			Updated:   it.Updated.UTC().Format(time.RFC3339),
+			Summary:   it.Summary,
+			Content:   atomContent{Type: "html", Value: it.ContentHTML},
+		})
+	}
+
+	return marshalXML(feed)
+}
+
+// --- JSON Feed 1.1 ---
+
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Description string         `json:"description,omitempty"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string `json:"id"`
+	URL           string `json:"url"`
+	Title         string `json:"title"`
+	ContentHTML   string `json:"content_html"`
+	Summary       string `json:"summary,omitempty"`
+	Image         string `json:"image,omitempty"`
+	DatePublished string `json:"date_published"`
+	DateModified  string `json:"date_modified"`
+}
+
+func encodeJSONFeed(meta feedMeta, items []feedItem) ([]byte, error) {
+	feed := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       meta.Title,
+		HomePageURL: meta.HomeURL,
+		FeedURL:     meta.SelfURL,
+		Description: meta.Description,
+		Items:       make([]jsonFeedItem, 0, len(items)),
+	}
+
+	for _, it := range items {
+		feed.Items = append(feed.Items, jsonFeedItem{
+			ID:            it.ID,
+			URL:           it.URL,
+			Title:         it.Title,
+			ContentHTML:   it.ContentHTML,
+			Summary:       it.Summary,
+			Image:         it.Image,
+			DatePublished: it.Published.UTC().Format(time.RFC3339),
+			DateModified:  it.Updated.UTC().Format(time.RFC3339),
+		})
+	}
+
+	return json.Marshal(feed)
+}
+
+func marshalXML(v any) ([]byte, error) {
+	body, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
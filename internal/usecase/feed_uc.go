@@ -0,0 +1,188 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"Test2/internal/domain"
+)
+
+var feedFormats = []string{domain.FeedFormatRSS, domain.FeedFormatAtom, domain.FeedFormatJSON}
+
+// FeedConfig chứa các thông tin hiển thị chung của feed
+type FeedConfig struct {
+	BaseURL string        // URL public của site, dùng để sinh link tuyệt đối
+	Title   string        // Tiêu đề feed toàn site
+	Limit   int64         // Số bài tối đa trong một feed
+	TTL     time.Duration // Thời gian cache feed đã render
+}
+
+type feedUseCase struct {
+	postRepo       domain.PostRepository
+	cateRepo       domain.CategoryRepository
+	renderer       domain.ContentRenderer
+	rawCache       domain.RawCacheRepository
+	cfg            FeedConfig
+	contextTimeout time.Duration
+}
+
+func NewFeedUseCase(
+	postRepo domain.PostRepository,
+	cateRepo domain.CategoryRepository,
+	renderer domain.ContentRenderer,
+	rawCache domain.RawCacheRepository,
+	cfg FeedConfig,
+	timeout time.Duration,
+) domain.FeedUseCase {
+	cfg.BaseURL = strings.TrimSuffix(cfg.BaseURL, "/")
+	return &feedUseCase{
+		postRepo:       postRepo,
+		cateRepo:       cateRepo,
+		renderer:       renderer,
+		rawCache:       rawCache,
+		cfg:            cfg,
+		contextTimeout: timeout,
+	}
+}
+
+// feedCacheKeys trả về key cache của mọi định dạng feed cho một danh mục (0 = toàn site)
+func feedCacheKeys(categoryID int64) []string {
+	keys := make([]string, 0, len(feedFormats))
+	for _, format := range feedFormats {
+		keys = append(keys, feedCacheKey(format, categoryID))
+	}
+	return keys
+}
+
+func feedCacheKey(format string, categoryID int64) string {
+	return fmt.Sprintf("feeds:posts:%s:cate:%d", format, categoryID)
+}
+
+func (fu *feedUseCase) GetPostFeed(ctx context.Context, format string, categoryID int64) (*domain.Feed, error) {
+	c, cancel := context.WithTimeout(ctx, fu.contextTimeout)
+	defer cancel()
+
+	if !isFeedFormat(format) {
+		return nil, fmt.Errorf("%w: %q", domain.ErrInvalidFeedFormat, format)
+	}
+
+	cacheKey := feedCacheKey(format, categoryID)
+	if cached, found := fu.rawCache.Get(c, cacheKey); found {
+		feed := &domain.Feed{}
+		if err := json.Unmarshal(cached, feed); err == nil {
+			return feed, nil
+		}
+	}
+
+	meta := feedMeta{
+		Title:    fu.cfg.Title,
+		HomeURL:  fu.cfg.BaseURL + "/",
+		FeedPath: "/feeds/posts",
+	}
+	if categoryID > 0 {
+		cate, err := fu.cateRepo.GetByID(c, categoryID)
+		if err != nil {
+			return nil, err
+		}
+		meta.Title = fmt.Sprintf("%s - %s", fu.cfg.Title, cate.Title)
+		meta.Description = cate.Description
+		meta.HomeURL = fmt.Sprintf("%s/categories/%d", fu.cfg.BaseURL, cate.ID)
+		meta.FeedPath = fmt.Sprintf("/feeds/categories/%d/posts", cate.ID)
+	}
+	meta.SelfURL = fu.cfg.BaseURL + meta.FeedPath + "." + format
+
+	posts, err := fu.postRepo.FetchPublished(c, categoryID, fu.cfg.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]feedItem, 0, len(posts))
+	for _, p := range posts {
+		item, err := fu.toFeedItem(p)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if item.Updated.After(meta.Updated) {
+			meta.Updated = item.Updated
+		}
+	}
+	if meta.Updated.IsZero() {
+		meta.Updated = time.Now()
+	}
+
+	feed := &domain.Feed{LastModified: meta.Updated.UTC().Truncate(time.Second)}
+	switch format {
+	case domain.FeedFormatRSS:
+		feed.ContentType = "application/rss+xml; charset=utf-8"
+		feed.Body, err = encodeRSS(meta, items)
+	case domain.FeedFormatAtom:
+		feed.ContentType = "application/atom+xml; charset=utf-8"
+		feed.Body, err = encodeAtom(meta, items)
+	case domain.FeedFormatJSON:
+		feed.ContentType = "application/feed+json; charset=utf-8"
+		feed.Body, err = encodeJSONFeed(meta, items)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(feed.Body)
+	feed.ETag = `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	if data, err := json.Marshal(feed); err == nil {
+		if err := fu.rawCache.Set(c, cacheKey, data, fu.cfg.TTL); err != nil && !errors.Is(err, domain.ErrCacheUnavailable) {
+			slog.WarnContext(c, "feed: failed to cache", "key", cacheKey, "error", err)
+		}
+	}
+
+	return feed, nil
+}
+
+func (fu *feedUseCase) toFeedItem(p domain.Post) (feedItem, error) {
+	format := p.ContentFormat
+	if format == "" {
+		format = domain.ContentFormatMarkdown
+	}
+
+	html, err := fu.renderer.Render(format, p.Content)
+	if err != nil {
+		return feedItem{}, err
+	}
+
+	published := p.CreatedAt
+	if p.PublishDate != nil {
+		published = *p.PublishDate
+	}
+
+	item := feedItem{
+		ID:          fmt.Sprintf("%s/posts/%d", fu.cfg.BaseURL, p.ID),
+		URL:         fmt.Sprintf("%s/posts/%d", fu.cfg.BaseURL, p.ID),
+		Title:       p.Title,
+		Summary:     p.Description,
+		ContentHTML: html,
+		Image:       p.Thumbnail,
+		Published:   published,
+		Updated:     p.UpdateDate,
+	}
+	if strings.HasPrefix(item.Image, "/") {
+		item.Image = fu.cfg.BaseURL + item.Image
+	}
+	return item, nil
+}
+
+func isFeedFormat(format string) bool {
+	for _, f := range feedFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"Test2/internal/domain"
+)
+
+type healthUseCase struct {
+	checks       []domain.HealthCheck
+	detailers    []domain.HealthDetailer
+	checkTimeout time.Duration
+	startedAt    time.Time
+	draining     atomic.Bool
+}
+
+// NewHealthUseCase: checks quyết định readiness, detailers chỉ xuất hiện ở trang health của admin.
+// checkTimeout giới hạn thời gian của mỗi dependency để probe không bị treo khi dependency treo.
+func NewHealthUseCase(checks []domain.HealthCheck, detailers []domain.HealthDetailer, checkTimeout time.Duration) domain.HealthUseCase {
+	return &healthUseCase{
+		checks:       checks,
+		detailers:    detailers,
+		checkTimeout: checkTimeout,
+		startedAt:    time.Now(),
+	}
+}
+
+func (hu *healthUseCase) SetDraining() {
+	hu.draining.Store(true)
+}
+
+// Readiness kiểm tra song song, instance sẵn sàng khi mọi dependency bắt buộc phản hồi và chưa shutdown
+func (hu *healthUseCase) Readiness(ctx context.Context) *domain.HealthReport {
+	report := &domain.HealthReport{
+		Status:    domain.HealthStatusOK,
+		Checks:    make([]domain.DependencyStatus, len(hu.checks)),
+		CheckedAt: time.Now().UTC(),
+	}
+
+	var wg sync.WaitGroup
+	for i, check := range hu.checks {
+		wg.Go(func() {
+			report.Checks[i] = hu.run(ctx, check)
+		})
+	}
+	wg.Wait()
+
+	for _, s := range report.Checks {
+		switch {
+		case s.Status == domain.DependencyUp:
+		case !s.Optional:
+			report.Status = domain.HealthStatusUnavailable
+		case report.Status == domain.HealthStatusOK:
+			report.Status = domain.HealthStatusDegraded
+		}
+	}
+	// Vẫn kiểm tra dependency khi draining để người vận hành thấy được trạng thái thật
+	if hu.draining.Load() {
+		report.Status = domain.HealthStatusDraining
+	}
+	return report
+}
+
+func (hu *healthUseCase) run(ctx context.Context, check domain.HealthCheck) domain.DependencyStatus {
+	c, cancel := context.WithTimeout(ctx, hu.checkTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Check(c)
+	status := domain.DependencyStatus{
+		Name:      check.Name(),
+		Status:    domain.DependencyUp,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if optional, ok := check.(domain.OptionalHealthCheck); ok {
+		status.Optional = optional.Optional()
+	}
+	if err != nil {
+		status.Status = domain.DependencyDown
+		status.Error = err.Error()
+	}
+	return status
+}
+
+func (hu *healthUseCase) Details(ctx context.Context) *domain.HealthDetails {
+	details := &domain.HealthDetails{
+		HealthReport: *hu.Readiness(ctx),
+		StartedAt:    hu.startedAt.UTC(),
+		Uptime:       time.Since(hu.startedAt).Round(time.Second).String(),
+		GoVersion:    runtime.Version(),
+		Goroutines:   runtime.NumGoroutine(),
+		Details:      make(map[string]any, len(hu.detailers)),
+	}
+
+	for _, d := range hu.detailers {
+		c, cancel := context.WithTimeout(ctx, hu.checkTimeout)
+		v, err := d.Details(c)
+		cancel()
+		if err != nil {
+			details.Details[d.Name()] = map[string]string{"error": err.Error()}
+			continue
+		}
+		details.Details[d.Name()] = v
+	}
+	return details
+}
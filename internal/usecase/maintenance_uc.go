@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"Test2/internal/domain"
+)
+
+// cacheFamilies ánh xạ tên nhóm sang pattern khóa, khớp với định dạng khóa dùng trong các UseCase
+var cacheFamilies = map[string]string{
+	"posts:list":   "posts:list:*",
+	"post:detail":  "post:detail:*",
+	"posts:search": "posts:search:*",
+	"post:html":    "post:html:*",
+	"feeds":        "feeds:*",
+	"sitemap":      "sitemap:*",
+	"categories":   categoryTreeCacheKey,
+}
+
+type maintenanceUseCase struct {
+	repo           domain.MaintenanceRepository
+	cache          domain.CacheInspector
+	contextTimeout time.Duration
+}
+
+// NewMaintenanceUseCase: timeout áp dụng cho các thao tác đọc/xóa cache và kiểm tra dữ liệu,
+// RebuildSearch chỉ dùng ctx của người gọi vì dựng lại index có thể mất nhiều phút
+func NewMaintenanceUseCase(repo domain.MaintenanceRepository, cache domain.CacheInspector, timeout time.Duration) domain.MaintenanceUseCase {
+	return &maintenanceUseCase{
+		repo:           repo,
+		cache:          cache,
+		contextTimeout: timeout,
+	}
+}
+
+func (mu *maintenanceUseCase) CacheFamilies() []string {
+	names := make([]string, 0, len(cacheFamilies))
+	for name := range cacheFamilies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (mu *maintenanceUseCase) pattern(family string) (string, error) {
+	pattern, ok := cacheFamilies[family]
+	if !ok {
+		return "", fmt.Errorf("%w: %q (expected one of %v)", domain.ErrUnknownCacheFamily, family, mu.CacheFamilies())
+	}
+	return pattern, nil
+}
+
+func (mu *maintenanceUseCase) InspectCache(ctx context.Context, family string, limit int) (*domain.CacheFamilyStats, error) {
+	c, cancel := context.WithTimeout(ctx, mu.contextTimeout)
+	defer cancel()
+
+	pattern, err := mu.pattern(family)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+
+	count, keys, err := mu.cache.Inspect(c, pattern, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.CacheFamilyStats{Family: family, Pattern: pattern, Count: count, Keys: keys}, nil
+}
+
+func (mu *maintenanceUseCase) FlushCache(ctx context.Context, family string) (int64, error) {
+	c, cancel := context.WithTimeout(ctx, mu.contextTimeout)
+	defer cancel()
+
+	pattern, err := mu.pattern(family)
+	if err != nil {
+		return 0, err
+	}
+	return mu.cache.DeletePattern(c, pattern)
+}
+
+func (mu *maintenanceUseCase) RebuildSearch(ctx context.Context) error {
+	if err := mu.repo.RebuildSearchIndex(ctx); err != nil {
+		return err
+	}
+
+	// Kết quả tìm kiếm đã cache có thể khác với index mới
+	c, cancel := context.WithTimeout(ctx, mu.contextTimeout)
+	defer cancel()
+	_, err := mu.cache.DeletePattern(c, cacheFamilies["posts:search"])
+	return err
+}
+
+func (mu *maintenanceUseCase) CheckConsistency(ctx context.Context) (*domain.ConsistencyReport, error) {
+	c, cancel := context.WithTimeout(ctx, mu.contextTimeout)
+	defer cancel()
+
+	checks, issues, err := mu.repo.CheckConsistency(c)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.ConsistencyReport{OK: len(issues) == 0, Checks: checks, Issues: issues}, nil
+}
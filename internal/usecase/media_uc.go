@@ -0,0 +1,337 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"log/slog"
+	"net/http"
+	"path"
+	"time"
+
+	_ "image/gif" // Đăng ký decoder để đọc kích thước ảnh GIF
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // Đăng ký decoder để đọc kích thước ảnh WebP
+
+	"Test2/internal/domain"
+)
+
+// mediaVariantSpec mô tả một biến thể cần sinh ra, ảnh được thu nhỏ theo chiều rộng tối đa
+type mediaVariantSpec struct {
+	name     string
+	maxWidth int
+}
+
+var mediaVariantSpecs = []mediaVariantSpec{
+	{name: domain.MediaVariantThumb, maxWidth: 150},
+	{name: domain.MediaVariantSmall, maxWidth: 480},
+	{name: domain.MediaVariantMedium, maxWidth: 1024},
+}
+
+// allowedMediaTypes ánh xạ MIME (đã sniff từ nội dung) sang phần mở rộng tệp
+var allowedMediaTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+type mediaUseCase struct {
+	mediaRepo      domain.MediaRepository
+	storage        domain.MediaStorage
+	cache          domain.CacheRepository
+	rawCache       domain.RawCacheRepository
+	maxSize        int64
+	maxPixels      int64
+	contextTimeout time.Duration
+}
+
+// NewMediaUseCase khởi tạo MediaUseCase, maxSize là dung lượng tối đa (byte) của một tệp upload,
+// maxPixels là số điểm ảnh tối đa (rộng x cao) để chặn ảnh nén nhỏ nhưng giải nén ra hàng GB.
+// cache và rawCache dùng để xóa cache của bài viết, danh mục còn trỏ tới media bị xóa.
+func NewMediaUseCase(
+	repo domain.MediaRepository,
+	storage domain.MediaStorage,
+	cache domain.CacheRepository,
+	rawCache domain.RawCacheRepository,
+	maxSize int64,
+	maxPixels int64,
+	timeout time.Duration,
+) domain.MediaUseCase {
+	return &mediaUseCase{
+		mediaRepo:      repo,
+		storage:        storage,
+		cache:          cache,
+		rawCache:       rawCache,
+		maxSize:        maxSize,
+		maxPixels:      maxPixels,
+		contextTimeout: timeout,
+	}
+}
+
+// Helper: Gắn URL public cho ảnh gốc và các biến thể
+func (mu *mediaUseCase) attachURLs(md *domain.Media) {
+	md.URL = mu.storage.URL(md.StorageKey)
+	for i := range md.Variants {
+		md.Variants[i].URL = mu.storage.URL(md.Variants[i].StorageKey)
+	}
+}
+
+func (mu *mediaUseCase) Upload(ctx context.Context, fileName string, r io.Reader) (*domain.Media, error) {
+	// Đọc dư 1 byte để phát hiện tệp vượt giới hạn mà không cần biết trước Content-Length
+	data, err := io.ReadAll(io.LimitReader(r, mu.maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > mu.maxSize {
+		return nil, domain.ErrMediaTooLarge
+	}
+
+	// Không tin Content-Type do client gửi, sniff MIME từ nội dung thực tế
+	mimeType := http.DetectContentType(data)
+	ext, ok := allowedMediaTypes[mimeType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", domain.ErrUnsupportedMediaType, mimeType)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot decode image: %v", domain.ErrUnsupportedMediaType, err)
+	}
+	// Kích thước khai báo trong header được kiểm tra trước khi giải nén toàn bộ ảnh (decompression bomb)
+	if pixels := int64(cfg.Width) * int64(cfg.Height); pixels > mu.maxPixels {
+		return nil, fmt.Errorf("%w: %dx%d pixels exceeds the limit of %d", domain.ErrMediaTooLarge, cfg.Width, cfg.Height, mu.maxPixels)
+	}
+
+	c, cancel := context.WithTimeout(ctx, mu.contextTimeout)
+	defer cancel()
+
+	prefix, err := newMediaKeyPrefix()
+	if err != nil {
+		return nil, err
+	}
+
+	md := &domain.Media{
+		FileName:   path.Base(fileName),
+		MimeType:   mimeType,
+		Size:       int64(len(data)),
+		Width:      cfg.Width,
+		Height:     cfg.Height,
+		StorageKey: prefix + "/original" + ext,
+		Variants:   []domain.MediaVariant{},
+		CreatedAt:  time.Now(),
+	}
+
+	if err := mu.storage.Save(c, md.StorageKey, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+
+	// Chỉ JPEG/PNG mới sinh biến thể, GIF/WebP được giữ nguyên bản gốc
+	if mimeType == "image/jpeg" || mimeType == "image/png" {
+		variants, err := mu.generateVariants(c, prefix, ext, mimeType, data)
+		if err != nil {
+			mu.cleanup(c, md)
+			return nil, err
+		}
+		md.Variants = variants
+	}
+
+	if err := mu.mediaRepo.Store(c, md); err != nil {
+		mu.cleanup(c, md)
+		return nil, err
+	}
+
+	mu.attachURLs(md)
+	return md, nil
+}
+
+// generateVariants resize ảnh gốc theo mediaVariantSpecs, bỏ qua các biến thể lớn hơn ảnh gốc
+func (mu *mediaUseCase) generateVariants(ctx context.Context, prefix, ext, mimeType string, data []byte) ([]domain.MediaVariant, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot decode image: %v", domain.ErrUnsupportedMediaType, err)
+	}
+
+	bounds := src.Bounds()
+	variants := make([]domain.MediaVariant, 0, len(mediaVariantSpecs))
+
+	for _, spec := range mediaVariantSpecs {
+		if bounds.Dx() <= spec.maxWidth {
+			continue
+		}
+
+		width := spec.maxWidth
+		height := bounds.Dy() * width / bounds.Dx()
+		if height < 1 {
+			height = 1
+		}
+
+		dst := image.NewRGBA(image.Rect(0, 0, width, height))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+
+		var buf bytes.Buffer
+		if mimeType == "image/png" {
+			err = png.Encode(&buf, dst)
+		} else {
+			err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85})
+		}
+		if err != nil {
+			return variants, err
+		}
+
+		v := domain.MediaVariant{
+			Name:       spec.name,
+			StorageKey: prefix + "/" + spec.name + ext,
+			Width:      width,
+			Height:     height,
+			Size:       int64(buf.Len()),
+		}
+		if err := mu.storage.Save(ctx, v.StorageKey, &buf); err != nil {
+			return variants, err
+		}
+		variants = append(variants, v)
+	}
+
+	return variants, nil
+}
+
+// Helper: Xóa các tệp đã ghi khi upload thất bại giữa chừng
+func (mu *mediaUseCase) cleanup(ctx context.Context, md *domain.Media) {
+	keys := []string{md.StorageKey}
+	for _, v := range md.Variants {
+		keys = append(keys, v.StorageKey)
+	}
+	for _, key := range keys {
+		if err := mu.storage.Delete(ctx, key); err != nil {
+			slog.WarnContext(ctx, "media: failed to remove file", "key", key, "error", err)
+		}
+	}
+}
+
+func (mu *mediaUseCase) Fetch(ctx context.Context, page int64, pageSize int64) ([]domain.Media, error) {
+	c, cancel := context.WithTimeout(ctx, mu.contextTimeout)
+	defer cancel()
+
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+
+	offset := (page - 1) * pageSize
+	items, err := mu.mediaRepo.Fetch(c, pageSize, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range items {
+		mu.attachURLs(&items[i])
+	}
+	return items, nil
+}
+
+func (mu *mediaUseCase) GetByID(ctx context.Context, id int64) (*domain.Media, error) {
+	c, cancel := context.WithTimeout(ctx, mu.contextTimeout)
+	defer cancel()
+
+	md, err := mu.mediaRepo.GetByID(c, id)
+	if err != nil {
+		return nil, err
+	}
+
+	mu.attachURLs(md)
+	return md, nil
+}
+
+func (mu *mediaUseCase) Delete(ctx context.Context, id int64) error {
+	c, cancel := context.WithTimeout(ctx, mu.contextTimeout)
+	defer cancel()
+
+	md, err := mu.mediaRepo.GetByID(c, id)
+	if err != nil {
+		return err
+	}
+
+	refs, err := mu.mediaRepo.Delete(c, id)
+	if err != nil {
+		return err
+	}
+	mu.invalidateRefs(c, refs)
+
+	// Metadata đã xóa, tệp còn sót lại chỉ gây tốn dung lượng nên chỉ log lỗi
+	mu.cleanup(c, md)
+	return nil
+}
+
+// Helper: Xóa cache chi tiết, feed của các bài viết và cây danh mục vừa được gỡ media
+func (mu *mediaUseCase) invalidateRefs(ctx context.Context, refs *domain.MediaRefs) {
+	if len(refs.PostIDs) > 0 {
+		for _, id := range refs.PostIDs {
+			_ = mu.cache.Delete(ctx, fmt.Sprintf("post:detail:%d", id))
+		}
+		keys := feedCacheKeys(0)
+		for _, id := range refs.PostCategoryIDs {
+			keys = append(keys, feedCacheKeys(id)...)
+		}
+		_ = mu.rawCache.Delete(ctx, keys...)
+	}
+	if len(refs.CategoryIDs) > 0 {
+		_ = mu.rawCache.Delete(ctx, categoryTreeCacheKey)
+	}
+}
+
+func (mu *mediaUseCase) Resolve(ctx context.Context, ids []int64) (map[int64]*domain.Media, error) {
+	result := make(map[int64]*domain.Media, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	c, cancel := context.WithTimeout(ctx, mu.contextTimeout)
+	defer cancel()
+
+	items, err := mu.mediaRepo.GetByIDs(c, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range items {
+		mu.attachURLs(&items[i])
+		result[items[i].ID] = &items[i]
+	}
+	return result, nil
+}
+
+// newMediaKeyPrefix sinh thư mục lưu trữ dạng media/2026/10/<random>
+func newMediaKeyPrefix() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("media/%s/%s", time.Now().Format("2006/01"), hex.EncodeToString(b)), nil
+}
+
+// resolveMediaRef kiểm tra media được Post/Category tham chiếu có tồn tại hay không
+func resolveMediaRef(ctx context.Context, media domain.MediaUseCase, id *int64) (*domain.Media, error) {
+	if id == nil {
+		return nil, nil
+	}
+
+	found, err := media.Resolve(ctx, []int64{*id})
+	if err != nil {
+		return nil, err
+	}
+
+	md, ok := found[*id]
+	if !ok {
+		return nil, domain.ErrMediaNotFound
+	}
+	return md, nil
+}
@@ -0,0 +1,275 @@
+package usecase
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"Test2/internal/domain"
+)
+
+// Bộ mã hóa/giải mã bài viết cho export/import (JSONL và CSV)
+
+// postCSVColumns là thứ tự cột khi export CSV, import nhận các cột theo tên trong header
+var postCSVColumns = []string{
+	"id", "slug", "title", "description", "content", "content_format", "thumbnail", "media_id",
+	"category_id", "status", "publish_date", "update_date", "created_at", "deleted_at", "previous_status",
+}
+
+// postEncoder ghi lần lượt từng bài viết, Flush đẩy phần còn trong buffer ra writer
+type postEncoder interface {
+	Encode(p *domain.Post) error
+	Flush() error
+}
+
+func newPostEncoder(w io.Writer, format string) (postEncoder, error) {
+	switch format {
+	case domain.TransferFormatJSONL:
+		bw := bufio.NewWriter(w)
+		return &jsonlPostEncoder{w: bw, enc: json.NewEncoder(bw)}, nil
+	case domain.TransferFormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(postCSVColumns); err != nil {
+			return nil, err
+		}
+		return &csvPostEncoder{w: cw}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q (expected jsonl or csv)", domain.ErrInvalidTransferFormat, format)
+	}
+}
+
+type jsonlPostEncoder struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func (e *jsonlPostEncoder) Encode(p *domain.Post) error {
+	// json.Encoder tự thêm "\n" sau mỗi object
+	return e.enc.Encode(p)
+}
+
+func (e *jsonlPostEncoder) Flush() error {
+	return e.w.Flush()
+}
+
+type csvPostEncoder struct {
+	w *csv.Writer
+}
+
+func (e *csvPostEncoder) Encode(p *domain.Post) error {
+	return e.w.Write([]string{
+		strconv.FormatInt(p.ID, 10),
+		p.Slug,
+		p.Title,
+		p.Description,
+		p.Content,
+		p.ContentFormat,
+		p.Thumbnail,
+		formatOptionalInt(p.MediaID),
+		formatOptionalInt(p.CategoryID),
+		p.Status,
+		formatOptionalTime(p.PublishDate),
+		p.UpdateDate.Format(time.RFC3339),
+		p.CreatedAt.Format(time.RFC3339),
+		formatOptionalTime(p.DeletedAt),
+		p.PreviousStatus,
+	})
+}
+
+func (e *csvPostEncoder) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func formatOptionalInt(v *int64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatInt(*v, 10)
+}
+
+func formatOptionalTime(v *time.Time) string {
+	if v == nil {
+		return ""
+	}
+	return v.Format(time.RFC3339)
+}
+
+// importRowError là lỗi của riêng một dòng, Import ghi vào báo cáo rồi đọc tiếp dòng sau
+type importRowError struct {
+	row int
+	err error
+}
+
+func (e *importRowError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.row, e.err)
+}
+
+// postDecoder trả về từng bài viết kèm số thứ tự dòng, io.EOF khi hết dữ liệu
+type postDecoder interface {
+	Next() (int, *domain.Post, error)
+}
+
+func newPostDecoder(r io.Reader, format string) (postDecoder, error) {
+	switch format {
+	case domain.TransferFormatJSONL:
+		return &jsonlPostDecoder{r: bufio.NewReader(r)}, nil
+	case domain.TransferFormatCSV:
+		cr := csv.NewReader(r)
+		header, err := cr.Read()
+		if err != nil {
+			if err == io.EOF {
+				return nil, fmt.Errorf("%w: missing csv header", domain.ErrInvalidTransferFormat)
+			}
+			return nil, fmt.Errorf("%w: %v", domain.ErrInvalidTransferFormat, err)
+		}
+		columns := make(map[string]int, len(header))
+		for i, name := range header {
+			columns[strings.ToLower(strings.TrimSpace(name))] = i
+		}
+		if _, ok := columns["title"]; !ok {
+			return nil, fmt.Errorf("%w: csv header must contain a title column", domain.ErrInvalidTransferFormat)
+		}
+		// Số cột của mọi dòng phải bằng header, dòng sai được báo lỗi riêng
+		cr.FieldsPerRecord = len(header)
+		return &csvPostDecoder{r: cr, columns: columns}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q (expected jsonl or csv)", domain.ErrInvalidTransferFormat, format)
+	}
+}
+
+type jsonlPostDecoder struct {
+	r    *bufio.Reader
+	line int
+}
+
+func (d *jsonlPostDecoder) Next() (int, *domain.Post, error) {
+	for {
+		// ReadBytes không giới hạn độ dài dòng như bufio.Scanner (nội dung bài viết có thể rất dài)
+		line, err := d.r.ReadBytes('\n')
+		if len(line) == 0 && err != nil {
+			return 0, nil, err
+		}
+		d.line++
+
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			if err != nil {
+				return 0, nil, err
+			}
+			continue
+		}
+
+		p := &domain.Post{}
+		if jsonErr := json.Unmarshal(line, p); jsonErr != nil {
+			return d.line, nil, &importRowError{row: d.line, err: jsonErr}
+		}
+		return d.line, p, nil
+	}
+}
+
+type csvPostDecoder struct {
+	r       *csv.Reader
+	columns map[string]int
+	row     int
+}
+
+func (d *csvPostDecoder) Next() (int, *domain.Post, error) {
+	record, err := d.r.Read()
+	if err == io.EOF {
+		return 0, nil, err
+	}
+	d.row++
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return d.row, nil, &importRowError{row: d.row, err: err}
+		}
+		return 0, nil, err
+	}
+
+	p, err := d.decode(record)
+	if err != nil {
+		return d.row, nil, &importRowError{row: d.row, err: err}
+	}
+	return d.row, p, nil
+}
+
+func (d *csvPostDecoder) field(record []string, name string) string {
+	if i, ok := d.columns[name]; ok {
+		return record[i]
+	}
+	return ""
+}
+
+func (d *csvPostDecoder) decode(record []string) (*domain.Post, error) {
+	p := &domain.Post{
+		Slug:           d.field(record, "slug"),
+		Title:          d.field(record, "title"),
+		Description:    d.field(record, "description"),
+		Content:        d.field(record, "content"),
+		ContentFormat:  d.field(record, "content_format"),
+		Thumbnail:      d.field(record, "thumbnail"),
+		Status:         d.field(record, "status"),
+		PreviousStatus: d.field(record, "previous_status"),
+	}
+
+	var err error
+	if raw := d.field(record, "id"); raw != "" {
+		if p.ID, err = strconv.ParseInt(raw, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid id %q", raw)
+		}
+	}
+	if p.MediaID, err = parseOptionalInt(d.field(record, "media_id")); err != nil {
+		return nil, fmt.Errorf("invalid media_id: %w", err)
+	}
+	if p.CategoryID, err = parseOptionalInt(d.field(record, "category_id")); err != nil {
+		return nil, fmt.Errorf("invalid category_id: %w", err)
+	}
+	if p.PublishDate, err = parseOptionalTime(d.field(record, "publish_date")); err != nil {
+		return nil, fmt.Errorf("invalid publish_date: %w", err)
+	}
+	if p.DeletedAt, err = parseOptionalTime(d.field(record, "deleted_at")); err != nil {
+		return nil, fmt.Errorf("invalid deleted_at: %w", err)
+	}
+	if t, err := parseOptionalTime(d.field(record, "update_date")); err != nil {
+		return nil, fmt.Errorf("invalid update_date: %w", err)
+	} else if t != nil {
+		p.UpdateDate = *t
+	}
+	if t, err := parseOptionalTime(d.field(record, "created_at")); err != nil {
+		return nil, fmt.Errorf("invalid created_at: %w", err)
+	} else if t != nil {
+		p.CreatedAt = *t
+	}
+	return p, nil
+}
+
+func parseOptionalInt(raw string) (*int64, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	v, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func parseOptionalTime(raw string) (*time.Time, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
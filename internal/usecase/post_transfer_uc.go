@@ -0,0 +1,331 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"Test2/internal/domain"
+)
+
+// importRow là một bài viết đã đọc được từ file import cùng vị trí dòng của nó
+type importRow struct {
+	row  int
+	post *domain.Post
+}
+
+// importState giữ các khóa đã gặp trong file để phát hiện hai dòng cùng trỏ tới một bài viết
+type importState struct {
+	report *domain.ImportReport
+	ids    map[int64]int  // id -> dòng đầu tiên
+	slugs  map[string]int // slug -> dòng đầu tiên
+}
+
+func newImportState(dryRun bool) *importState {
+	return &importState{
+		report: &domain.ImportReport{DryRun: dryRun, Errors: []domain.ImportRowError{}},
+		ids:    map[int64]int{},
+		slugs:  map[string]int{},
+	}
+}
+
+func (s *importState) fail(row int, p *domain.Post, err error) {
+	s.report.Failed++
+	e := domain.ImportRowError{Row: row, Error: err.Error()}
+	if p != nil {
+		e.ID = p.ID
+		e.Slug = p.Slug
+	}
+	s.report.Errors = append(s.report.Errors, e)
+}
+
+// importablePostStatus gồm cả Deleted để file backup khôi phục được thùng rác
+func importablePostStatus(status string) bool {
+	return writablePostStatus(status) || status == domain.StatusDeleted
+}
+
+func (pu *postUseCase) Export(ctx context.Context, w io.Writer, format string, includeDeleted bool) error {
+	// Không áp dụng contextTimeout: thời gian export tỉ lệ với số bài viết,
+	// việc dừng giữa chừng dựa vào context của request (client ngắt kết nối)
+	enc, err := newPostEncoder(w, format)
+	if err != nil {
+		return err
+	}
+
+	if err := pu.postRepo.Export(ctx, includeDeleted, enc.Encode); err != nil {
+		return err
+	}
+	return enc.Flush()
+}
+
+func (pu *postUseCase) Import(ctx context.Context, r io.Reader, format string, dryRun bool) (*domain.ImportReport, error) {
+	dec, err := newPostDecoder(r, format)
+	if err != nil {
+		return nil, err
+	}
+
+	state := newImportState(dryRun)
+	batch := make([]importRow, 0, domain.BatchMaxItems)
+	for {
+		row, p, err := dec.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var rowErr *importRowError
+			if !errors.As(err, &rowErr) {
+				return state.report, err
+			}
+			state.report.Total++
+			state.fail(rowErr.row, nil, rowErr.err)
+			continue
+		}
+
+		state.report.Total++
+		batch = append(batch, importRow{row: row, post: p})
+		if len(batch) == domain.BatchMaxItems {
+			if err := pu.importBatch(ctx, state, batch, dryRun); err != nil {
+				return state.report, err
+			}
+			batch = batch[:0]
+		}
+	}
+
+	if len(batch) > 0 {
+		if err := pu.importBatch(ctx, state, batch, dryRun); err != nil {
+			return state.report, err
+		}
+	}
+	return state.report, nil
+}
+
+func (pu *postUseCase) ImportPosts(ctx context.Context, posts []domain.Post, dryRun bool) (*domain.ImportReport, error) {
+	state := newImportState(dryRun)
+
+	for start := 0; start < len(posts); start += domain.BatchMaxItems {
+		end := min(start+domain.BatchMaxItems, len(posts))
+		batch := make([]importRow, 0, end-start)
+		for i := start; i < end; i++ {
+			batch = append(batch, importRow{row: i + 1, post: &posts[i]})
+		}
+
+		state.report.Total += len(batch)
+		if err := pu.importBatch(ctx, state, batch, dryRun); err != nil {
+			return state.report, err
+		}
+	}
+	return state.report, nil
+}
+
+// importBatch kiểm tra một lô dòng, đối chiếu với bài viết đang có theo id/slug rồi ghi bằng UpsertBatch.
+// Chỉ trả về lỗi khi không thể tiếp tục (mất kết nối, context bị hủy), lỗi dữ liệu được ghi vào báo cáo.
+func (pu *postUseCase) importBatch(ctx context.Context, state *importState, batch []importRow, dryRun bool) error {
+	c, cancel := context.WithTimeout(ctx, pu.contextTimeout)
+	defer cancel()
+
+	// 1. Kiểm tra từng dòng độc lập với database
+	valid := make([]importRow, 0, len(batch))
+	for _, r := range batch {
+		if err := validateImportedPost(r.post); err != nil {
+			state.fail(r.row, r.post, err)
+			continue
+		}
+		valid = append(valid, r)
+	}
+
+	// 2. Nạp các bài viết đang có theo id hoặc slug
+	ids := make([]int64, 0, len(valid))
+	slugs := make([]string, 0, len(valid))
+	mediaIDs := make([]int64, 0, len(valid))
+	for _, r := range valid {
+		if r.post.ID != 0 {
+			ids = append(ids, r.post.ID)
+		}
+		if r.post.Slug != "" {
+			slugs = append(slugs, r.post.Slug)
+		}
+		if r.post.MediaID != nil {
+			mediaIDs = append(mediaIDs, *r.post.MediaID)
+		}
+	}
+
+	existing, err := pu.postRepo.FetchByKeys(c, ids, slugs)
+	if err != nil {
+		return err
+	}
+	byID := make(map[int64]*domain.Post, len(existing))
+	bySlug := make(map[string]*domain.Post, len(existing))
+	for i := range existing {
+		byID[existing[i].ID] = &existing[i]
+		if existing[i].Slug != "" {
+			bySlug[existing[i].Slug] = &existing[i]
+		}
+	}
+
+	media, err := pu.media.Resolve(c, mediaIDs)
+	if err != nil {
+		return err
+	}
+
+	// 3. Xác định bài viết đích của từng dòng
+	now := time.Now()
+	accepted := make([]*domain.Post, 0, len(valid))
+	acceptedRows := make([]int, 0, len(valid))
+	changes := make([]domain.PostChange, 0, len(valid))
+	for _, r := range valid {
+		p := r.post
+
+		var before *domain.Post
+		if p.ID != 0 {
+			before = byID[p.ID]
+			if owner, ok := bySlug[p.Slug]; ok && p.Slug != "" && owner.ID != p.ID {
+				state.fail(r.row, p, fmt.Errorf("%w: %q belongs to post %d", domain.ErrDuplicateSlug, p.Slug, owner.ID))
+				continue
+			}
+		} else if owner, ok := bySlug[p.Slug]; ok && p.Slug != "" {
+			before = owner
+			p.ID = owner.ID
+		}
+
+		if p.ID != 0 {
+			if first, ok := state.ids[p.ID]; ok {
+				state.fail(r.row, p, fmt.Errorf("post %d is already imported by row %d", p.ID, first))
+				continue
+			}
+		}
+		if p.Slug != "" {
+			if first, ok := state.slugs[p.Slug]; ok {
+				state.fail(r.row, p, fmt.Errorf("slug %q is already imported by row %d", p.Slug, first))
+				continue
+			}
+		}
+
+		if p.MediaID != nil {
+			md, ok := media[*p.MediaID]
+			if !ok {
+				state.fail(r.row, p, fmt.Errorf("%w: %d", domain.ErrMediaNotFound, *p.MediaID))
+				continue
+			}
+			p.Thumbnail = md.URL
+		}
+
+		fillImportedPost(p, before, now)
+
+		if p.ID != 0 {
+			state.ids[p.ID] = r.row
+		}
+		if p.Slug != "" {
+			state.slugs[p.Slug] = r.row
+		}
+		accepted = append(accepted, p)
+		acceptedRows = append(acceptedRows, r.row)
+		changes = append(changes, domain.PostChange{Before: before, After: p})
+	}
+
+	if len(accepted) == 0 {
+		return nil
+	}
+
+	created := make([]domain.PostChange, 0, len(changes))
+	updated := make([]domain.PostChange, 0, len(changes))
+	for _, ch := range changes {
+		if ch.Before == nil {
+			created = append(created, ch)
+		} else {
+			updated = append(updated, ch)
+		}
+	}
+
+	// 4. Ghi cả lô trong một transaction, lỗi ở bước này đánh dấu hỏng mọi dòng của lô
+	if !dryRun {
+		err := pu.events.write(c, func(tc context.Context) ([]domain.DomainEvent, error) {
+			if err := pu.postRepo.UpsertBatch(tc, accepted); err != nil {
+				return nil, err
+			}
+			// Bài mới chỉ có id sau khi UpsertBatch chạy xong nên event được tạo tại đây
+			events, err := pu.events.posts(tc, domain.ActionCreate, created)
+			if err != nil {
+				return nil, err
+			}
+			updateEvents, err := pu.events.posts(tc, domain.ActionUpdate, updated)
+			if err != nil {
+				return nil, err
+			}
+			return append(events, updateEvents...), nil
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			for k, p := range accepted {
+				state.fail(acceptedRows[k], p, err)
+			}
+			return nil
+		}
+	}
+
+	state.report.Created += len(created)
+	state.report.Updated += len(updated)
+
+	if !dryRun {
+		pu.invalidatePostBatch(c, changes)
+		pu.notifyBatch(c, domain.ActionCreate, created)
+		pu.notifyBatch(c, domain.ActionUpdate, updated)
+	}
+	return nil
+}
+
+// validateImportedPost kiểm tra các trường không cần truy vấn database
+func validateImportedPost(p *domain.Post) error {
+	if p.ID < 0 {
+		return fmt.Errorf("invalid id %d", p.ID)
+	}
+	if p.Title == "" {
+		return fmt.Errorf("title is required")
+	}
+	if p.Status == "" {
+		p.Status = domain.StatusDraft
+	}
+	if !importablePostStatus(p.Status) {
+		return fmt.Errorf("%w: %q", domain.ErrInvalidStatus, p.Status)
+	}
+	if err := normalizeContentFormat(p); err != nil {
+		return err
+	}
+	return normalizeSlug(p)
+}
+
+// fillImportedPost bổ sung các mốc thời gian và thông tin thùng rác còn thiếu,
+// ưu tiên giá trị trong file rồi tới giá trị của bài viết đang có
+func fillImportedPost(p *domain.Post, before *domain.Post, now time.Time) {
+	p.Media = nil
+	if p.CreatedAt.IsZero() {
+		p.CreatedAt = now
+		if before != nil {
+			p.CreatedAt = before.CreatedAt
+		}
+	}
+	if p.UpdateDate.IsZero() {
+		p.UpdateDate = now
+	}
+	if p.PublishDate == nil && before != nil {
+		p.PublishDate = before.PublishDate
+	}
+	if p.PublishDate == nil && p.Status == domain.StatusPublished {
+		p.PublishDate = &now
+	}
+
+	if p.Status != domain.StatusDeleted {
+		p.DeletedAt = nil
+		p.PreviousStatus = ""
+		return
+	}
+	if p.DeletedAt == nil {
+		p.DeletedAt = &now
+	}
+	if p.PreviousStatus == "" || !writablePostStatus(p.PreviousStatus) {
+		p.PreviousStatus = domain.StatusDraft
+	}
+}
@@ -2,28 +2,111 @@ package usecase
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"Test2/internal/domain"
 )
 
+// CacheTTL là thời gian sống của cache do PostUseCase và CateUseCase ghi, giá trị <= 0 dùng mặc định
+type CacheTTL struct {
+	PostList     time.Duration
+	PostDetail   time.Duration
+	PostSearch   time.Duration
+	PostHTML     time.Duration // HTML đã render, khóa theo hash nội dung nên có thể giữ lâu
+	CategoryTree time.Duration
+}
+
+func (t CacheTTL) withDefaults() CacheTTL {
+	if t.PostList <= 0 {
+		t.PostList = 5 * time.Minute
+	}
+	if t.PostDetail <= 0 {
+		t.PostDetail = 10 * time.Minute
+	}
+	if t.PostSearch <= 0 {
+		t.PostSearch = 3 * time.Minute
+	}
+	if t.PostHTML <= 0 {
+		t.PostHTML = 24 * time.Hour
+	}
+	if t.CategoryTree <= 0 {
+		t.CategoryTree = time.Hour
+	}
+	return t
+}
+
+// CacheTTLs giữ CacheTTL hiện hành, an toàn khi dùng đồng thời và đổi được lúc chạy (reload cấu hình)
+type CacheTTLs struct {
+	v atomic.Pointer[CacheTTL]
+}
+
+func NewCacheTTLs(ttl CacheTTL) *CacheTTLs {
+	t := &CacheTTLs{}
+	t.Store(ttl)
+	return t
+}
+
+func (t *CacheTTLs) Load() CacheTTL {
+	return *t.v.Load()
+}
+
+// Store thay toàn bộ TTL trong một bước, giá trị <= 0 dùng mặc định
+func (t *CacheTTLs) Store(ttl CacheTTL) {
+	ttl = ttl.withDefaults()
+	t.v.Store(&ttl)
+}
+
 type postUseCase struct {
 	postRepo       domain.PostRepository
 	cache          domain.CacheRepository
+	media          domain.MediaUseCase
+	renderer       domain.ContentRenderer
+	rawCache       domain.RawCacheRepository
+	events         eventWriter
+	ttl            *CacheTTLs
 	contextTimeout time.Duration
+	hooks          []domain.ContentHook
 }
 
 // NewPostUseCase khởi tạo PostUseCase với Dependency Injection
 func NewPostUseCase(
 	repo domain.PostRepository,
 	cache domain.CacheRepository,
+	media domain.MediaUseCase,
+	renderer domain.ContentRenderer,
+	rawCache domain.RawCacheRepository,
+	tx domain.Transactor,
+	outbox domain.OutboxRepository,
+	audit domain.AuditUseCase,
+	ttl *CacheTTLs,
 	timeout time.Duration,
+	hooks ...domain.ContentHook,
 ) domain.PostUseCase {
 	return &postUseCase{
 		postRepo:       repo,
 		cache:          cache,
+		media:          media,
+		renderer:       renderer,
+		rawCache:       rawCache,
+		events:         eventWriter{tx: tx, outbox: outbox, audit: audit},
+		ttl:            ttl,
 		contextTimeout: timeout,
+		hooks:          hooks,
+	}
+}
+
+// Helper: Thông báo thay đổi cho các ContentHook (sitemap, ...)
+func (pu *postUseCase) notify(ctx context.Context, action string, before, after *domain.Post) {
+	for _, h := range pu.hooks {
+		h.PostChanged(ctx, action, before, after)
 	}
 }
 
@@ -40,6 +123,132 @@ func (pu *postUseCase) invalidateSinglePostCache(ctx context.Context, id int64)
 	_ = pu.cache.Delete(ctx, cacheKey)
 }
 
+// Helper: Xóa cache feed toàn site và feed của các danh mục bị ảnh hưởng
+func (pu *postUseCase) invalidateFeedCache(ctx context.Context, categoryIDs ...*int64) {
+	keys := feedCacheKeys(0)
+	for _, id := range categoryIDs {
+		if id != nil {
+			keys = append(keys, feedCacheKeys(*id)...)
+		}
+	}
+	_ = pu.rawCache.Delete(ctx, keys...)
+}
+
+// Helper: Gắn media (URL ảnh gốc và các biến thể) cho danh sách bài viết
+func (pu *postUseCase) attachMedia(ctx context.Context, posts []domain.Post) error {
+	ids := make([]int64, 0, len(posts))
+	for _, p := range posts {
+		if p.MediaID != nil {
+			ids = append(ids, *p.MediaID)
+		}
+	}
+
+	found, err := pu.media.Resolve(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range posts {
+		if posts[i].MediaID != nil {
+			posts[i].Media = found[*posts[i].MediaID]
+		}
+	}
+	return nil
+}
+
+// Helper: Kiểm tra media_id hợp lệ và đồng bộ trường Thumbnail với URL ảnh gốc
+func (pu *postUseCase) applyMedia(ctx context.Context, p *domain.Post) error {
+	md, err := resolveMediaRef(ctx, pu.media, p.MediaID)
+	if err != nil {
+		return err
+	}
+	if md != nil {
+		p.Media = md
+		p.Thumbnail = md.URL
+	}
+	return nil
+}
+
+// Helper: Chuẩn hóa content_format, mặc định là Markdown
+func normalizeContentFormat(p *domain.Post) error {
+	switch p.ContentFormat {
+	case "":
+		p.ContentFormat = domain.ContentFormatMarkdown
+	case domain.ContentFormatMarkdown, domain.ContentFormatHTML, domain.ContentFormatPlaintext:
+	default:
+		return fmt.Errorf("%w: %q (expected markdown, html or plaintext)", domain.ErrInvalidContentFormat, p.ContentFormat)
+	}
+	// content_html chỉ do server sinh ra, bỏ qua giá trị client gửi lên
+	p.ContentHTML = ""
+	return nil
+}
+
+// slugPattern: chữ thường, số và dấu gạch ngang đơn giữa các cụm
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+
+// Helper: Chuẩn hóa slug (tùy chọn), slug rỗng được lưu là NULL
+func normalizeSlug(p *domain.Post) error {
+	p.Slug = strings.ToLower(strings.TrimSpace(p.Slug))
+	if p.Slug != "" && (len(p.Slug) > 255 || !slugPattern.MatchString(p.Slug)) {
+		return fmt.Errorf("%w: %q (expected lowercase letters, digits and single hyphens)", domain.ErrInvalidSlug, p.Slug)
+	}
+	return nil
+}
+
+// Helper: Render nội dung sang HTML đã sanitise.
+// Key cache gắn với hash của định dạng và nội dung nên bản render cũ hết hiệu lực ngay khi nội dung đổi,
+// kể cả khi hai lần sửa rơi vào cùng một giây (UpdateDate chỉ lưu tới giây).
+func (pu *postUseCase) renderContent(ctx context.Context, p *domain.Post) error {
+	format := p.ContentFormat
+	if format == "" {
+		format = domain.ContentFormatMarkdown
+	}
+
+	sum := sha256.Sum256([]byte(format + "\x00" + p.Content))
+	cacheKey := fmt.Sprintf("post:html:%d:%s", p.ID, hex.EncodeToString(sum[:16]))
+
+	if cached, found := pu.rawCache.Get(ctx, cacheKey); found {
+		p.ContentHTML = string(cached)
+		return nil
+	}
+
+	rendered, err := pu.renderer.Render(format, p.Content)
+	if err != nil {
+		return err
+	}
+
+	p.ContentHTML = rendered
+	if err := pu.rawCache.Set(ctx, cacheKey, []byte(rendered), pu.ttl.Load().PostHTML); err != nil && !errors.Is(err, domain.ErrCacheUnavailable) {
+		slog.WarnContext(ctx, "post: failed to cache rendered content", "post_id", p.ID, "error", err)
+	}
+	return nil
+}
+
+// Helper: Chuẩn hóa bài viết mới trước khi ghi (trạng thái mặc định, định dạng, media, các mốc thời gian)
+func (pu *postUseCase) prepareNewPost(ctx context.Context, p *domain.Post, now time.Time) error {
+	if p.Status == "" {
+		p.Status = domain.StatusDraft
+	}
+	if err := normalizeContentFormat(p); err != nil {
+		return err
+	}
+	if err := normalizeSlug(p); err != nil {
+		return err
+	}
+
+	if err := pu.applyMedia(ctx, p); err != nil {
+		return err
+	}
+
+	p.CreatedAt = now
+	p.UpdateDate = now
+	p.PublishDate = nil
+	if p.Status == domain.StatusPublished {
+		p.PublishDate = &now
+	}
+	return nil
+}
+
 func (pu *postUseCase) Fetch(ctx context.Context, page int64, pageSize int64) ([]domain.Post, error) {
 	c, cancel := context.WithTimeout(ctx, pu.contextTimeout)
 	defer cancel()
@@ -65,8 +274,11 @@ func (pu *postUseCase) Fetch(ctx context.Context, page int64, pageSize int64) ([
 		return nil, err
 	}
 
-	// Ghi vào Cache với TTL = 5 phút
-	_ = pu.cache.Set(c, cacheKey, posts, 5*time.Minute)
+	if err := pu.attachMedia(c, posts); err != nil {
+		return nil, err
+	}
+
+	_ = pu.cache.Set(c, cacheKey, posts, pu.ttl.Load().PostList)
 
 	return posts, nil
 }
@@ -75,17 +287,21 @@ func (pu *postUseCase) Store(ctx context.Context, p *domain.Post) error {
 	c, cancel := context.WithTimeout(ctx, pu.contextTimeout)
 	defer cancel()
 
-	if p.Status == "" {
-		p.Status = domain.StatusDraft
+	if err := pu.prepareNewPost(c, p, time.Now()); err != nil {
+		return err
 	}
-	now := time.Now()
-	p.CreatedAt = now
-	p.UpdateDate = now
 
-	err := pu.postRepo.Store(c, p)
+	err := pu.events.write(c, func(tc context.Context) ([]domain.DomainEvent, error) {
+		if err := pu.postRepo.Store(tc, p); err != nil {
+			return nil, err
+		}
+		return pu.events.posts(tc, domain.ActionCreate, []domain.PostChange{{After: p}})
+	})
 	if err == nil {
 		// Dữ liệu mới thay đổi danh sách -> Xóa cache danh sách
 		pu.invalidatePostListCache(c)
+		pu.invalidateFeedCache(c, p.CategoryID)
+		pu.notify(c, domain.ActionCreate, nil, p)
 	}
 	return err
 }
@@ -94,15 +310,23 @@ func (pu *postUseCase) Delete(ctx context.Context, id int64) error {
 	c, cancel := context.WithTimeout(ctx, pu.contextTimeout)
 	defer cancel()
 
-	_, err := pu.postRepo.GetByID(c, id)
-	if err != nil {
-		return err
-	}
-
-	err = pu.postRepo.Delete(c, id)
+	// Bản ghi trước khi xóa (Before của audit/outbox) được đọc và khóa trong transaction để không lệch với thao tác ghi đồng thời
+	var existing *domain.Post
+	err := pu.events.write(c, func(tc context.Context) ([]domain.DomainEvent, error) {
+		var err error
+		if existing, err = pu.postRepo.GetByIDForUpdate(tc, id); err != nil {
+			return nil, err
+		}
+		if err := pu.postRepo.Delete(tc, id); err != nil {
+			return nil, err
+		}
+		return pu.events.posts(tc, domain.ActionDelete, []domain.PostChange{{Before: existing}})
+	})
 	if err == nil {
 		pu.invalidatePostListCache(c)
 		pu.invalidateSinglePostCache(c, id)
+		pu.invalidateFeedCache(c, existing.CategoryID)
+		pu.notify(c, domain.ActionDelete, existing, nil)
 	}
 	return err
 }
@@ -115,7 +339,11 @@ func (pu *postUseCase) GetByID(ctx context.Context, id int64) (*domain.Post, err
 
 	// Lấy mảng từ cache, giả sử phần tử đầu tiên là kết quả cần tìm
 	if cachedData, found := pu.cache.Get(c, cacheKey); found && len(cachedData) > 0 {
-		return &cachedData[0], nil
+		post := &cachedData[0]
+		if err := pu.renderContent(c, post); err != nil {
+			return nil, err
+		}
+		return post, nil
 	}
 
 	post, err := pu.postRepo.GetByID(c, id)
@@ -123,8 +351,18 @@ func (pu *postUseCase) GetByID(ctx context.Context, id int64) (*domain.Post, err
 		return nil, err
 	}
 
-	_ = pu.cache.Set(c, cacheKey, []domain.Post{*post}, 10*time.Minute)
+	posts := []domain.Post{*post}
+	if err := pu.attachMedia(c, posts); err != nil {
+		return nil, err
+	}
+
+	_ = pu.cache.Set(c, cacheKey, posts, pu.ttl.Load().PostDetail)
 
+	// Render sau khi ghi cache chi tiết, HTML được cache riêng theo key post:html:<id>:<sha256(format\x00content)>
+	post = &posts[0]
+	if err := pu.renderContent(c, post); err != nil {
+		return nil, err
+	}
 	return post, nil
 }
 
@@ -132,11 +370,49 @@ func (pu *postUseCase) Update(ctx context.Context, p *domain.Post) error {
 	c, cancel := context.WithTimeout(ctx, pu.contextTimeout)
 	defer cancel()
 
-	p.UpdateDate = time.Now()
-	err := pu.postRepo.Update(c, p)
+	if err := normalizeContentFormat(p); err != nil {
+		return err
+	}
+	if err := normalizeSlug(p); err != nil {
+		return err
+	}
+
+	if err := pu.applyMedia(c, p); err != nil {
+		return err
+	}
+
+	// Bản ghi hiện tại (Before của audit/outbox, from_status) được đọc và khóa trong transaction
+	// để hai lần sửa đồng thời không cùng dựa trên một bản cũ
+	var existing *domain.Post
+	err := pu.events.write(c, func(tc context.Context) ([]domain.DomainEvent, error) {
+		var err error
+		if existing, err = pu.postRepo.GetByIDForUpdate(tc, p.ID); err != nil {
+			return nil, err
+		}
+
+		// PUT không gửi slug thì giữ slug hiện có thay vì xóa nó
+		if p.Slug == "" {
+			p.Slug = existing.Slug
+		}
+		// created_at và publish_date do server quản lý, không lấy từ body của client
+		p.CreatedAt = existing.CreatedAt
+		p.PublishDate = existing.PublishDate
+		now := time.Now()
+		p.UpdateDate = now
+		if p.Status == domain.StatusPublished && p.PublishDate == nil {
+			p.PublishDate = &now
+		}
+
+		if err := pu.postRepo.Update(tc, p); err != nil {
+			return nil, err
+		}
+		return pu.events.posts(tc, domain.ActionUpdate, []domain.PostChange{{Before: existing, After: p}})
+	})
 	if err == nil {
 		pu.invalidatePostListCache(c)
 		pu.invalidateSinglePostCache(c, p.ID)
+		pu.invalidateFeedCache(c, existing.CategoryID, p.CategoryID)
+		pu.notify(c, domain.ActionUpdate, existing, p)
 	}
 	return err
 }
@@ -164,7 +440,11 @@ func (pu *postUseCase) Search(ctx context.Context, keyword string, page int64, p
 		return nil, err
 	}
 
-	_ = pu.cache.Set(c, cacheKey, posts, 3*time.Minute)
+	if err := pu.attachMedia(c, posts); err != nil {
+		return nil, err
+	}
+
+	_ = pu.cache.Set(c, cacheKey, posts, pu.ttl.Load().PostSearch)
 
 	return posts, nil
 }
@@ -0,0 +1,230 @@
+package usecase
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"Test2/internal/domain"
+)
+
+const sitemapNS = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// SitemapConfig cấu hình sinh sitemap
+type SitemapConfig struct {
+	BaseURL  string        // URL public của site
+	PageSize int64         // Số URL tối đa trong một sitemap con (<= 50000)
+	TTL      time.Duration // Thời gian cache, các trang bị ảnh hưởng được xóa ngay khi có thay đổi
+}
+
+type sitemapUseCase struct {
+	postRepo       domain.PostRepository
+	cateRepo       domain.CategoryRepository
+	rawCache       domain.RawCacheRepository
+	cfg            SitemapConfig
+	contextTimeout time.Duration
+}
+
+func NewSitemapUseCase(
+	postRepo domain.PostRepository,
+	cateRepo domain.CategoryRepository,
+	rawCache domain.RawCacheRepository,
+	cfg SitemapConfig,
+	timeout time.Duration,
+) domain.SitemapUseCase {
+	cfg.BaseURL = strings.TrimSuffix(cfg.BaseURL, "/")
+	if cfg.PageSize <= 0 || cfg.PageSize > domain.SitemapMaxURLs {
+		cfg.PageSize = domain.SitemapMaxURLs
+	}
+	return &sitemapUseCase{
+		postRepo:       postRepo,
+		cateRepo:       cateRepo,
+		rawCache:       rawCache,
+		cfg:            cfg,
+		contextTimeout: timeout,
+	}
+}
+
+type sitemapIndexXML struct {
+	XMLName  xml.Name          `xml:"sitemapindex"`
+	XMLNS    string            `xml:"xmlns,attr"`
+	Sitemaps []sitemapLocation `xml:"sitemap"`
+}
+
+type urlSetXML struct {
+	XMLName xml.Name          `xml:"urlset"`
+	XMLNS   string            `xml:"xmlns,attr"`
+	URLs    []sitemapLocation `xml:"url"`
+}
+
+type sitemapLocation struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+const sitemapIndexCacheKey = "sitemap:index"
+
+func sitemapPageCacheKey(kind string, page int64) string {
+	return fmt.Sprintf("sitemap:%s:%d", kind, page)
+}
+
+// pageOf trả về số trang sitemap chứa một id
+func (su *sitemapUseCase) pageOf(id int64) int64 {
+	return (id-1)/su.cfg.PageSize + 1
+}
+
+func (su *sitemapUseCase) Index(ctx context.Context) ([]byte, error) {
+	c, cancel := context.WithTimeout(ctx, su.contextTimeout)
+	defer cancel()
+
+	if cached, found := su.rawCache.Get(c, sitemapIndexCacheKey); found {
+		return cached, nil
+	}
+
+	postPages, err := su.postRepo.SitemapPages(c, su.cfg.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	catePages, err := su.cateRepo.SitemapPages(c, su.cfg.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	index := sitemapIndexXML{XMLNS: sitemapNS}
+	for _, group := range []struct {
+		kind  string
+		pages []domain.SitemapPage
+	}{
+		{domain.SitemapKindPosts, postPages},
+		{domain.SitemapKindCategories, catePages},
+	} {
+		for _, p := range group.pages {
+			index.Sitemaps = append(index.Sitemaps, sitemapLocation{
+				Loc:     fmt.Sprintf("%s/sitemaps/%s/%d.xml", su.cfg.BaseURL, group.kind, p.Number),
+				LastMod: p.LastMod.UTC().Format(time.RFC3339),
+			})
+		}
+	}
+
+	body, err := marshalXML(index)
+	if err != nil {
+		return nil, err
+	}
+
+	su.store(c, sitemapIndexCacheKey, body)
+	return body, nil
+}
+
+func (su *sitemapUseCase) Page(ctx context.Context, kind string, page int64) ([]byte, error) {
+	c, cancel := context.WithTimeout(ctx, su.contextTimeout)
+	defer cancel()
+
+	if page <= 0 {
+		return nil, fmt.Errorf("invalid sitemap page %d", page)
+	}
+
+	cacheKey := sitemapPageCacheKey(kind, page)
+	if cached, found := su.rawCache.Get(c, cacheKey); found {
+		return cached, nil
+	}
+
+	var (
+		entries []domain.SitemapEntry
+		err     error
+	)
+
+	switch kind {
+	case domain.SitemapKindPosts:
+		entries, err = su.postRepo.SitemapEntries(c, page, su.cfg.PageSize)
+	case domain.SitemapKindCategories:
+		entries, err = su.cateRepo.SitemapEntries(c, page, su.cfg.PageSize)
+	default:
+		return nil, fmt.Errorf("unknown sitemap kind %q", kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	urlSet := urlSetXML{XMLNS: sitemapNS, URLs: make([]sitemapLocation, 0, len(entries))}
+	for _, e := range entries {
+		urlSet.URLs = append(urlSet.URLs, sitemapLocation{
+			Loc:     fmt.Sprintf("%s/%s/%d", su.cfg.BaseURL, kind, e.ID),
+			LastMod: e.LastMod.UTC().Format(time.RFC3339),
+		})
+	}
+
+	body, err := marshalXML(urlSet)
+	if err != nil {
+		return nil, err
+	}
+
+	su.store(c, cacheKey, body)
+	return body, nil
+}
+
+func (su *sitemapUseCase) store(ctx context.Context, key string, body []byte) {
+	if err := su.rawCache.Set(ctx, key, body, su.cfg.TTL); err != nil && !errors.Is(err, domain.ErrCacheUnavailable) {
+		slog.WarnContext(ctx, "sitemap: failed to cache", "key", key, "error", err)
+	}
+}
+
+// invalidate chỉ xóa index và đúng các trang chứa bản ghi, các trang khác giữ nguyên cache
+func (su *sitemapUseCase) invalidate(ctx context.Context, kind string, ids ...int64) {
+	keys := []string{sitemapIndexCacheKey}
+	seen := map[int64]bool{}
+	for _, id := range ids {
+		page := su.pageOf(id)
+		if !seen[page] {
+			seen[page] = true
+			keys = append(keys, sitemapPageCacheKey(kind, page))
+		}
+	}
+	if err := su.rawCache.Delete(ctx, keys...); err != nil {
+		slog.WarnContext(ctx, "sitemap: failed to invalidate", "keys", keys, "error", err)
+	}
+}
+
+func (su *sitemapUseCase) PostChanged(ctx context.Context, action string, before, after *domain.Post) {
+	if after != nil {
+		su.invalidate(ctx, domain.SitemapKindPosts, after.ID)
+	} else if before != nil {
+		su.invalidate(ctx, domain.SitemapKindPosts, before.ID)
+	}
+}
+
+func (su *sitemapUseCase) CategoryChanged(ctx context.Context, action string, before, after *domain.Category) {
+	if after != nil {
+		su.invalidate(ctx, domain.SitemapKindCategories, after.ID)
+	} else if before != nil {
+		su.invalidate(ctx, domain.SitemapKindCategories, before.ID)
+	}
+}
+
+// PostsChanged/CategoriesChanged (BatchContentHook) xóa cache của cả lô trong một lệnh
+func (su *sitemapUseCase) PostsChanged(ctx context.Context, action string, changes []domain.PostChange) {
+	ids := make([]int64, 0, len(changes))
+	for _, ch := range changes {
+		if ch.After != nil {
+			ids = append(ids, ch.After.ID)
+		} else if ch.Before != nil {
+			ids = append(ids, ch.Before.ID)
+		}
+	}
+	su.invalidate(ctx, domain.SitemapKindPosts, ids...)
+}
+
+func (su *sitemapUseCase) CategoriesChanged(ctx context.Context, action string, changes []domain.CategoryChange) {
+	ids := make([]int64, 0, len(changes))
+	for _, ch := range changes {
+		if ch.After != nil {
+			ids = append(ids, ch.After.ID)
+		} else if ch.Before != nil {
+			ids = append(ids, ch.Before.ID)
+		}
+	}
+	su.invalidate(ctx, domain.SitemapKindCategories, ids...)
+}
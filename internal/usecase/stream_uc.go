@@ -0,0 +1,216 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"Test2/internal/domain"
+)
+
+// streamClientBuffer là số thông báo tối đa chờ gửi cho một client, vượt quá thì client bị ngắt
+// và phải kết nối lại với Last-Event-ID
+const streamClientBuffer = 256
+
+// streamMaxBackoff là khoảng chờ tối đa giữa hai lần kết nối lại broker
+const streamMaxBackoff = 30 * time.Second
+
+type streamSubscriber struct {
+	filter domain.ChangeFilter
+	ch     chan domain.ChangeNotification
+}
+
+type streamUseCase struct {
+	broker         domain.ChangeBroker
+	contextTimeout time.Duration
+
+	mu          sync.Mutex
+	subscribers map[*streamSubscriber]struct{}
+	closed      bool
+}
+
+func NewStreamUseCase(broker domain.ChangeBroker, timeout time.Duration) domain.StreamUseCase {
+	return &streamUseCase{
+		broker:         broker,
+		contextTimeout: timeout,
+		subscribers:    make(map[*streamSubscriber]struct{}),
+	}
+}
+
+// changeNotification rút gọn domain event thành thông báo, false nếu event không có entity đi kèm
+func changeNotification(e domain.DomainEvent) (domain.ChangeNotification, bool) {
+	n := domain.ChangeNotification{
+		ID:         e.ID,
+		Type:       e.Type,
+		Entity:     e.AggregateType,
+		EntityID:   e.AggregateID,
+		OccurredAt: e.OccurredAt,
+	}
+
+	switch e.AggregateType {
+	case domain.AggregatePost:
+		var payload domain.PostEventPayload
+		if err := json.Unmarshal(e.Payload, &payload); err != nil || payload.Post == nil {
+			return n, false
+		}
+		n.Action = payload.Action
+		n.Title = payload.Post.Title
+		switch {
+		case payload.ToStatus != "":
+			n.Status = payload.ToStatus
+		case payload.Action == domain.ActionDelete, payload.Action == domain.ActionPurge:
+			n.Status = domain.StatusDeleted
+		default:
+			n.Status = payload.Post.Status
+		}
+	case domain.AggregateCategory:
+		var payload domain.CategoryEventPayload
+		if err := json.Unmarshal(e.Payload, &payload); err != nil || payload.Category == nil {
+			return n, false
+		}
+		n.Action = payload.Action
+		n.Title = payload.Category.Title
+		if payload.Action == domain.ActionDelete || payload.Action == domain.ActionPurge {
+			n.Status = domain.StatusDeleted
+		} else {
+			n.Status = payload.Category.Status
+		}
+	default:
+		return n, false
+	}
+	return n, true
+}
+
+func (su *streamUseCase) Name() string {
+	return "sse"
+}
+
+// Publish chuyển event thành thông báo và phát qua broker, mọi instance (kể cả instance này) nhận lại qua Run
+func (su *streamUseCase) Publish(ctx context.Context, events []domain.DomainEvent) (int, error) {
+	c, cancel := context.WithTimeout(ctx, su.contextTimeout)
+	defer cancel()
+
+	notifications := make([]domain.ChangeNotification, 0, len(events))
+	for _, e := range events {
+		if n, ok := changeNotification(e); ok {
+			notifications = append(notifications, n)
+		}
+	}
+
+	if err := su.broker.Publish(c, notifications); err != nil {
+		return 0, err
+	}
+	return len(events), nil
+}
+
+func (su *streamUseCase) Subscribe(ctx context.Context, filter domain.ChangeFilter, lastEventID int64) ([]domain.ChangeNotification, <-chan domain.ChangeNotification, error) {
+	// Đăng ký trước khi đọc buffer để không lọt thông báo phát ra giữa hai bước
+	sub := &streamSubscriber{
+		filter: filter,
+		ch:     make(chan domain.ChangeNotification, streamClientBuffer),
+	}
+	su.mu.Lock()
+	if su.closed {
+		su.mu.Unlock()
+		return nil, nil, domain.ErrStreamClosed
+	}
+	su.subscribers[sub] = struct{}{}
+	su.mu.Unlock()
+
+	var backlog []domain.ChangeNotification
+	if lastEventID > 0 {
+		c, cancel := context.WithTimeout(ctx, su.contextTimeout)
+		buffered, err := su.broker.Since(c, lastEventID)
+		cancel()
+		if err != nil {
+			su.remove(sub)
+			return nil, nil, err
+		}
+		for _, n := range buffered {
+			if filter.Match(n) {
+				backlog = append(backlog, n)
+			}
+		}
+	}
+
+	go func() {
+		<-ctx.Done()
+		su.remove(sub)
+	}()
+	return backlog, sub.ch, nil
+}
+
+// remove hủy đăng ký và đóng channel của client, gọi nhiều lần không sao
+func (su *streamUseCase) remove(sub *streamSubscriber) {
+	su.mu.Lock()
+	defer su.mu.Unlock()
+
+	if _, ok := su.subscribers[sub]; ok {
+		delete(su.subscribers, sub)
+		close(sub.ch)
+	}
+}
+
+func (su *streamUseCase) Close() {
+	su.mu.Lock()
+	defer su.mu.Unlock()
+
+	su.closed = true
+	for sub := range su.subscribers {
+		delete(su.subscribers, sub)
+		close(sub.ch)
+	}
+}
+
+func (su *streamUseCase) dispatch(n domain.ChangeNotification) {
+	su.mu.Lock()
+	defer su.mu.Unlock()
+
+	for sub := range su.subscribers {
+		if !sub.filter.Match(n) {
+			continue
+		}
+		select {
+		case sub.ch <- n:
+		default:
+			// Client đọc quá chậm: ngắt để nó resume từ buffer thay vì chặn các client khác
+			delete(su.subscribers, sub)
+			close(sub.ch)
+		}
+	}
+}
+
+func (su *streamUseCase) Run(ctx context.Context) {
+	// Chờ lâu dần khi kết nối lại liên tục thất bại (Redis lỗi) để không dồn log và lệnh vào Redis
+	backoff := time.Second
+	for {
+		notifications, err := su.broker.Subscribe(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			if !errors.Is(err, domain.ErrCacheUnavailable) {
+				slog.WarnContext(ctx, "change stream: subscribe failed", "retry_in", backoff.String(), "error", err)
+			}
+		} else {
+			backoff = time.Second
+			for n := range notifications {
+				su.dispatch(n)
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			slog.InfoContext(ctx, "change stream: subscription closed, reconnecting")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, streamMaxBackoff)
+	}
+}
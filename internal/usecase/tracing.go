@@ -0,0 +1,260 @@
+package usecase
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"Test2/internal/domain"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("Test2/internal/usecase")
+
+// startSpan mở span con cho một phương thức UseCase, span nằm giữa span của request và span của MySQL/Redis
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan ghi lỗi (nếu có) vào span rồi đóng span
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func pageAttrs(page, pageSize int64) []attribute.KeyValue {
+	return []attribute.KeyValue{attribute.Int64("page", page), attribute.Int64("page_size", pageSize)}
+}
+
+type tracedPostUseCase struct {
+	next domain.PostUseCase
+}
+
+// NewTracedPostUseCase bọc PostUseCase, mỗi phương thức chạy trong một span "PostUseCase.<Tên>"
+func NewTracedPostUseCase(next domain.PostUseCase) domain.PostUseCase {
+	return &tracedPostUseCase{next: next}
+}
+
+func (t *tracedPostUseCase) Fetch(ctx context.Context, page int64, pageSize int64) (posts []domain.Post, err error) {
+	ctx, span := startSpan(ctx, "PostUseCase.Fetch", pageAttrs(page, pageSize)...)
+	defer func() { endSpan(span, err) }()
+	return t.next.Fetch(ctx, page, pageSize)
+}
+
+func (t *tracedPostUseCase) GetByID(ctx context.Context, id int64) (p *domain.Post, err error) {
+	ctx, span := startSpan(ctx, "PostUseCase.GetByID", attribute.Int64("post.id", id))
+	defer func() { endSpan(span, err) }()
+	return t.next.GetByID(ctx, id)
+}
+
+func (t *tracedPostUseCase) Store(ctx context.Context, p *domain.Post) (err error) {
+	ctx, span := startSpan(ctx, "PostUseCase.Store")
+	defer func() {
+		span.SetAttributes(attribute.Int64("post.id", p.ID))
+		endSpan(span, err)
+	}()
+	return t.next.Store(ctx, p)
+}
+
+func (t *tracedPostUseCase) Update(ctx context.Context, p *domain.Post) (err error) {
+	ctx, span := startSpan(ctx, "PostUseCase.Update", attribute.Int64("post.id", p.ID))
+	defer func() { endSpan(span, err) }()
+	return t.next.Update(ctx, p)
+}
+
+func (t *tracedPostUseCase) Delete(ctx context.Context, id int64) (err error) {
+	ctx, span := startSpan(ctx, "PostUseCase.Delete", attribute.Int64("post.id", id))
+	defer func() { endSpan(span, err) }()
+	return t.next.Delete(ctx, id)
+}
+
+func (t *tracedPostUseCase) Search(ctx context.Context, keyword string, page int64, pageSize int64) (posts []domain.Post, err error) {
+	ctx, span := startSpan(ctx, "PostUseCase.Search", pageAttrs(page, pageSize)...)
+	defer func() { endSpan(span, err) }()
+	return t.next.Search(ctx, keyword, page, pageSize)
+}
+
+func (t *tracedPostUseCase) FetchTrash(ctx context.Context, page int64, pageSize int64) (posts []domain.Post, err error) {
+	ctx, span := startSpan(ctx, "PostUseCase.FetchTrash", pageAttrs(page, pageSize)...)
+	defer func() { endSpan(span, err) }()
+	return t.next.FetchTrash(ctx, page, pageSize)
+}
+
+func (t *tracedPostUseCase) Restore(ctx context.Context, id int64) (err error) {
+	ctx, span := startSpan(ctx, "PostUseCase.Restore", attribute.Int64("post.id", id))
+	defer func() { endSpan(span, err) }()
+	return t.next.Restore(ctx, id)
+}
+
+func (t *tracedPostUseCase) Purge(ctx context.Context, id int64) (err error) {
+	ctx, span := startSpan(ctx, "PostUseCase.Purge", attribute.Int64("post.id", id))
+	defer func() { endSpan(span, err) }()
+	return t.next.Purge(ctx, id)
+}
+
+func (t *tracedPostUseCase) PurgeExpired(ctx context.Context, olderThan time.Duration) (n int, err error) {
+	ctx, span := startSpan(ctx, "PostUseCase.PurgeExpired", attribute.String("older_than", olderThan.String()))
+	defer func() {
+		span.SetAttributes(attribute.Int("purged", n))
+		endSpan(span, err)
+	}()
+	return t.next.PurgeExpired(ctx, olderThan)
+}
+
+func (t *tracedPostUseCase) StoreBatch(ctx context.Context, posts []domain.Post) (res *domain.BatchResult, err error) {
+	ctx, span := startSpan(ctx, "PostUseCase.StoreBatch", attribute.Int("batch.size", len(posts)))
+	defer func() { endSpan(span, err) }()
+	return t.next.StoreBatch(ctx, posts)
+}
+
+func (t *tracedPostUseCase) UpdateStatusBatch(ctx context.Context, ids []int64, status string) (res *domain.BatchResult, err error) {
+	ctx, span := startSpan(ctx, "PostUseCase.UpdateStatusBatch", attribute.Int("batch.size", len(ids)), attribute.String("post.status", status))
+	defer func() { endSpan(span, err) }()
+	return t.next.UpdateStatusBatch(ctx, ids, status)
+}
+
+func (t *tracedPostUseCase) DeleteBatch(ctx context.Context, ids []int64) (res *domain.BatchResult, err error) {
+	ctx, span := startSpan(ctx, "PostUseCase.DeleteBatch", attribute.Int("batch.size", len(ids)))
+	defer func() { endSpan(span, err) }()
+	return t.next.DeleteBatch(ctx, ids)
+}
+
+func (t *tracedPostUseCase) RestoreBatch(ctx context.Context, ids []int64) (res *domain.BatchResult, err error) {
+	ctx, span := startSpan(ctx, "PostUseCase.RestoreBatch", attribute.Int("batch.size", len(ids)))
+	defer func() { endSpan(span, err) }()
+	return t.next.RestoreBatch(ctx, ids)
+}
+
+func (t *tracedPostUseCase) Export(ctx context.Context, w io.Writer, format string, includeDeleted bool) (err error) {
+	ctx, span := startSpan(ctx, "PostUseCase.Export", attribute.String("format", format), attribute.Bool("include_deleted", includeDeleted))
+	defer func() { endSpan(span, err) }()
+	return t.next.Export(ctx, w, format, includeDeleted)
+}
+
+func (t *tracedPostUseCase) Import(ctx context.Context, r io.Reader, format string, dryRun bool) (report *domain.ImportReport, err error) {
+	ctx, span := startSpan(ctx, "PostUseCase.Import", attribute.String("format", format), attribute.Bool("dry_run", dryRun))
+	defer func() { endSpan(span, err) }()
+	return t.next.Import(ctx, r, format, dryRun)
+}
+
+func (t *tracedPostUseCase) ImportPosts(ctx context.Context, posts []domain.Post, dryRun bool) (report *domain.ImportReport, err error) {
+	ctx, span := startSpan(ctx, "PostUseCase.ImportPosts", attribute.Int("batch.size", len(posts)), attribute.Bool("dry_run", dryRun))
+	defer func() { endSpan(span, err) }()
+	return t.next.ImportPosts(ctx, posts, dryRun)
+}
+
+type tracedCategoryUseCase struct {
+	next domain.CategoryUseCase
+}
+
+// NewTracedCategoryUseCase bọc CategoryUseCase, mỗi phương thức chạy trong một span "CategoryUseCase.<Tên>"
+func NewTracedCategoryUseCase(next domain.CategoryUseCase) domain.CategoryUseCase {
+	return &tracedCategoryUseCase{next: next}
+}
+
+func (t *tracedCategoryUseCase) Fetch(ctx context.Context, page int64, pageSize int64, sort string) (categories []domain.Category, err error) {
+	ctx, span := startSpan(ctx, "CategoryUseCase.Fetch", append(pageAttrs(page, pageSize), attribute.String("sort", sort))...)
+	defer func() { endSpan(span, err) }()
+	return t.next.Fetch(ctx, page, pageSize, sort)
+}
+
+func (t *tracedCategoryUseCase) GetByID(ctx context.Context, id int64) (c *domain.Category, err error) {
+	ctx, span := startSpan(ctx, "CategoryUseCase.GetByID", attribute.Int64("category.id", id))
+	defer func() { endSpan(span, err) }()
+	return t.next.GetByID(ctx, id)
+}
+
+func (t *tracedCategoryUseCase) Store(ctx context.Context, c *domain.Category) (err error) {
+	ctx, span := startSpan(ctx, "CategoryUseCase.Store")
+	defer func() {
+		span.SetAttributes(attribute.Int64("category.id", c.ID))
+		endSpan(span, err)
+	}()
+	return t.next.Store(ctx, c)
+}
+
+func (t *tracedCategoryUseCase) Update(ctx context.Context, c *domain.Category) (err error) {
+	ctx, span := startSpan(ctx, "CategoryUseCase.Update", attribute.Int64("category.id", c.ID))
+	defer func() { endSpan(span, err) }()
+	return t.next.Update(ctx, c)
+}
+
+func (t *tracedCategoryUseCase) Delete(ctx context.Context, id int64) (err error) {
+	ctx, span := startSpan(ctx, "CategoryUseCase.Delete", attribute.Int64("category.id", id))
+	defer func() { endSpan(span, err) }()
+	return t.next.Delete(ctx, id)
+}
+
+func (t *tracedCategoryUseCase) FetchTrash(ctx context.Context, page int64, pageSize int64) (categories []domain.Category, err error) {
+	ctx, span := startSpan(ctx, "CategoryUseCase.FetchTrash", pageAttrs(page, pageSize)...)
+	defer func() { endSpan(span, err) }()
+	return t.next.FetchTrash(ctx, page, pageSize)
+}
+
+func (t *tracedCategoryUseCase) Restore(ctx context.Context, id int64) (err error) {
+	ctx, span := startSpan(ctx, "CategoryUseCase.Restore", attribute.Int64("category.id", id))
+	defer func() { endSpan(span, err) }()
+	return t.next.Restore(ctx, id)
+}
+
+func (t *tracedCategoryUseCase) Purge(ctx context.Context, id int64) (err error) {
+	ctx, span := startSpan(ctx, "CategoryUseCase.Purge", attribute.Int64("category.id", id))
+	defer func() { endSpan(span, err) }()
+	return t.next.Purge(ctx, id)
+}
+
+func (t *tracedCategoryUseCase) PurgeExpired(ctx context.Context, olderThan time.Duration) (n int, err error) {
+	ctx, span := startSpan(ctx, "CategoryUseCase.PurgeExpired", attribute.String("older_than", olderThan.String()))
+	defer func() {
+		span.SetAttributes(attribute.Int("purged", n))
+		endSpan(span, err)
+	}()
+	return t.next.PurgeExpired(ctx, olderThan)
+}
+
+func (t *tracedCategoryUseCase) Tree(ctx context.Context) (nodes []domain.CategoryNode, err error) {
+	ctx, span := startSpan(ctx, "CategoryUseCase.Tree")
+	defer func() { endSpan(span, err) }()
+	return t.next.Tree(ctx)
+}
+
+func (t *tracedCategoryUseCase) Breadcrumb(ctx context.Context, id int64) (path []domain.Category, err error) {
+	ctx, span := startSpan(ctx, "CategoryUseCase.Breadcrumb", attribute.Int64("category.id", id))
+	defer func() { endSpan(span, err) }()
+	return t.next.Breadcrumb(ctx, id)
+}
+
+func (t *tracedCategoryUseCase) Reorder(ctx context.Context, parentID *int64, ids []int64) (err error) {
+	attrs := []attribute.KeyValue{attribute.Int("batch.size", len(ids))}
+	if parentID != nil {
+		attrs = append(attrs, attribute.Int64("category.parent_id", *parentID))
+	}
+	ctx, span := startSpan(ctx, "CategoryUseCase.Reorder", attrs...)
+	defer func() { endSpan(span, err) }()
+	return t.next.Reorder(ctx, parentID, ids)
+}
+
+func (t *tracedCategoryUseCase) StoreBatch(ctx context.Context, categories []domain.Category) (res *domain.BatchResult, err error) {
+	ctx, span := startSpan(ctx, "CategoryUseCase.StoreBatch", attribute.Int("batch.size", len(categories)))
+	defer func() { endSpan(span, err) }()
+	return t.next.StoreBatch(ctx, categories)
+}
+
+func (t *tracedCategoryUseCase) DeleteBatch(ctx context.Context, ids []int64) (res *domain.BatchResult, err error) {
+	ctx, span := startSpan(ctx, "CategoryUseCase.DeleteBatch", attribute.Int("batch.size", len(ids)))
+	defer func() { endSpan(span, err) }()
+	return t.next.DeleteBatch(ctx, ids)
+}
+
+func (t *tracedCategoryUseCase) RestoreBatch(ctx context.Context, ids []int64) (res *domain.BatchResult, err error) {
+	ctx, span := startSpan(ctx, "CategoryUseCase.RestoreBatch", attribute.Int("batch.size", len(ids)))
+	defer func() { endSpan(span, err) }()
+	return t.next.RestoreBatch(ctx, ids)
+}
@@ -0,0 +1,241 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"Test2/internal/domain"
+)
+
+// purgeBatchSize là số bản ghi retention job xử lý trong mỗi lượt truy vấn
+const purgeBatchSize = 100
+
+// --- POST ---
+
+func (pu *postUseCase) FetchTrash(ctx context.Context, page int64, pageSize int64) ([]domain.Post, error) {
+	c, cancel := context.WithTimeout(ctx, pu.contextTimeout)
+	defer cancel()
+
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+
+	offset := (page - 1) * pageSize
+	posts, err := pu.postRepo.FetchTrash(c, pageSize, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := pu.attachMedia(c, posts); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+func (pu *postUseCase) Restore(ctx context.Context, id int64) error {
+	c, cancel := context.WithTimeout(ctx, pu.contextTimeout)
+	defer cancel()
+
+	trashed, err := pu.postRepo.GetTrashedByID(c, id)
+	if err != nil {
+		return err
+	}
+
+	var restored *domain.Post
+	err = pu.events.write(c, func(tc context.Context) ([]domain.DomainEvent, error) {
+		if err := pu.postRepo.Restore(tc, id); err != nil {
+			return nil, err
+		}
+		var err error
+		if restored, err = pu.postRepo.GetByID(tc, id); err != nil {
+			return nil, err
+		}
+		return pu.events.posts(tc, domain.ActionRestore, []domain.PostChange{{Before: trashed, After: restored}})
+	})
+	if err != nil {
+		return err
+	}
+
+	// Bài được khôi phục có thể đã Published -> xuất hiện lại trong danh sách và feed
+	pu.invalidatePostListCache(c)
+	pu.invalidateSinglePostCache(c, id)
+	pu.invalidateFeedCache(c, restored.CategoryID)
+	pu.notify(c, domain.ActionRestore, trashed, restored)
+	return nil
+}
+
+func (pu *postUseCase) Purge(ctx context.Context, id int64) error {
+	c, cancel := context.WithTimeout(ctx, pu.contextTimeout)
+	defer cancel()
+
+	trashed, err := pu.postRepo.GetTrashedByID(c, id)
+	if err != nil {
+		return err
+	}
+
+	return pu.purge(c, trashed)
+}
+
+func (pu *postUseCase) purge(ctx context.Context, trashed *domain.Post) error {
+	err := pu.events.write(ctx, func(tc context.Context) ([]domain.DomainEvent, error) {
+		if err := pu.postRepo.Purge(tc, trashed.ID); err != nil {
+			return nil, err
+		}
+		return pu.events.posts(tc, domain.ActionPurge, []domain.PostChange{{Before: trashed}})
+	})
+	if err != nil {
+		return err
+	}
+
+	pu.invalidateSinglePostCache(ctx, trashed.ID)
+	pu.notify(ctx, domain.ActionPurge, trashed, nil)
+	return nil
+}
+
+func (pu *postUseCase) PurgeExpired(ctx context.Context, olderThan time.Duration) (int, error) {
+	cutoff := time.Now().Add(-olderThan)
+	purged := 0
+
+	for {
+		c, cancel := context.WithTimeout(ctx, pu.contextTimeout)
+		batch, err := pu.postRepo.FetchTrashedBefore(c, cutoff, purgeBatchSize)
+		if err != nil {
+			cancel()
+			return purged, err
+		}
+
+		for i := range batch {
+			if err := pu.purge(c, &batch[i]); err != nil {
+				cancel()
+				return purged, err
+			}
+			purged++
+		}
+		cancel()
+
+		if len(batch) < purgeBatchSize {
+			return purged, nil
+		}
+	}
+}
+
+// --- CATEGORY ---
+
+func (cu *cateUseCase) FetchTrash(ctx context.Context, page int64, pageSize int64) ([]domain.Category, error) {
+	c, cancel := context.WithTimeout(ctx, cu.contextTimeout)
+	defer cancel()
+
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+
+	offset := (page - 1) * pageSize
+	categories, err := cu.cateRepo.FetchTrash(c, pageSize, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cu.attachMedia(c, categories); err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+func (cu *cateUseCase) Restore(ctx context.Context, id int64) error {
+	c, cancel := context.WithTimeout(ctx, cu.contextTimeout)
+	defer cancel()
+
+	trashed, err := cu.cateRepo.GetTrashedByID(c, id)
+	if err != nil {
+		return err
+	}
+
+	var restored *domain.Category
+	err = cu.events.write(c, func(tc context.Context) ([]domain.DomainEvent, error) {
+		if err := cu.cateRepo.Restore(tc, id); err != nil {
+			return nil, err
+		}
+		var err error
+		if restored, err = cu.cateRepo.GetByID(tc, id); err != nil {
+			return nil, err
+		}
+
+		// Danh mục cha đã bị xóa trong lúc danh mục này nằm trong thùng rác -> khôi phục thành danh mục gốc
+		if restored.ParentID != nil {
+			if _, err := cu.cateRepo.GetByID(tc, *restored.ParentID); err != nil {
+				restored.ParentID = nil
+				restored.UpdatedAt = time.Now()
+				if err := cu.cateRepo.Update(tc, restored); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return cu.events.categories(tc, domain.ActionRestore, []domain.CategoryChange{{Before: trashed, After: restored}})
+	})
+	if err != nil {
+		return err
+	}
+
+	cu.notify(c, domain.ActionRestore, trashed, restored)
+	return nil
+}
+
+func (cu *cateUseCase) Purge(ctx context.Context, id int64) error {
+	c, cancel := context.WithTimeout(ctx, cu.contextTimeout)
+	defer cancel()
+
+	trashed, err := cu.cateRepo.GetTrashedByID(c, id)
+	if err != nil {
+		return err
+	}
+
+	return cu.purge(c, trashed)
+}
+
+func (cu *cateUseCase) purge(ctx context.Context, trashed *domain.Category) error {
+	err := cu.events.write(ctx, func(tc context.Context) ([]domain.DomainEvent, error) {
+		if err := cu.cateRepo.Purge(tc, trashed.ID); err != nil {
+			return nil, err
+		}
+		return cu.events.categories(tc, domain.ActionPurge, []domain.CategoryChange{{Before: trashed}})
+	})
+	if err != nil {
+		return err
+	}
+
+	cu.notify(ctx, domain.ActionPurge, trashed, nil)
+	return nil
+}
+
+func (cu *cateUseCase) PurgeExpired(ctx context.Context, olderThan time.Duration) (int, error) {
+	cutoff := time.Now().Add(-olderThan)
+	purged := 0
+
+	for {
+		c, cancel := context.WithTimeout(ctx, cu.contextTimeout)
+		batch, err := cu.cateRepo.FetchTrashedBefore(c, cutoff, purgeBatchSize)
+		if err != nil {
+			cancel()
+			return purged, err
+		}
+
+		for i := range batch {
+			if err := cu.purge(c, &batch[i]); err != nil {
+				cancel()
+				return purged, err
+			}
+			purged++
+		}
+		cancel()
+
+		if len(batch) < purgeBatchSize {
+			return purged, nil
+		}
+	}
+}
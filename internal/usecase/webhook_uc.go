@@ -0,0 +1,527 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"Test2/internal/domain"
+)
+
+// WebhookConfig cấu hình việc gửi webhook
+type WebhookConfig struct {
+	MaxAttempts  int           // Số lần gửi tối đa của một delivery trước khi chuyển sang failed
+	RetryBase    time.Duration // Khoảng chờ sau lần thất bại đầu tiên, nhân đôi sau mỗi lần
+	RetryMax     time.Duration // Khoảng chờ tối đa giữa hai lần thử
+	DisableAfter int           // Tắt webhook sau số lần thất bại liên tiếp này (0 = không tắt)
+	Lease        time.Duration // Thời gian một delivery được giữ bởi worker đang gửi
+	Concurrency  int           // Số request gửi song song trong một lượt
+
+	// AllowPrivateNetworks nhận URL trỏ tới localhost/IP nội bộ, chỉ dùng khi phát triển.
+	// Sender vẫn là nơi chặn chính (kiểm tra IP sau khi phân giải DNS), kiểm tra ở đây chỉ báo lỗi sớm.
+	AllowPrivateNetworks bool
+}
+
+// webhookDeliveryChunk là số delivery tối đa trong một lần ghi
+const webhookDeliveryChunk = 500
+
+// webhookEvents là các loại event có thể đăng ký
+var webhookEvents = map[string]bool{
+	domain.EventPostCreated:       true,
+	domain.EventPostUpdated:       true,
+	domain.EventPostStatusChanged: true,
+	domain.EventPostDeleted:       true,
+	domain.EventCategoryChanged:   true,
+}
+
+// webhookEnvelope là body JSON được gửi tới receiver
+type webhookEnvelope struct {
+	EventID       int64           `json:"event_id,omitempty"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type,omitempty"`
+	AggregateID   int64           `json:"aggregate_id,omitempty"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Data          json.RawMessage `json:"data"`
+}
+
+type webhookUseCase struct {
+	repo           domain.WebhookRepository
+	sender         domain.WebhookSender
+	cfg            WebhookConfig
+	contextTimeout time.Duration
+}
+
+func NewWebhookUseCase(repo domain.WebhookRepository, sender domain.WebhookSender, cfg WebhookConfig, timeout time.Duration) domain.WebhookUseCase {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 8
+	}
+	if cfg.RetryBase <= 0 {
+		cfg.RetryBase = 30 * time.Second
+	}
+	if cfg.RetryMax < cfg.RetryBase {
+		cfg.RetryMax = 6 * time.Hour
+	}
+	if cfg.Lease <= 0 {
+		cfg.Lease = 5 * time.Minute
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 4
+	}
+	return &webhookUseCase{
+		repo:           repo,
+		sender:         sender,
+		cfg:            cfg,
+		contextTimeout: timeout,
+	}
+}
+
+// signWebhook trả về chữ ký "sha256=<hex>" của "<timestamp>.<body>"
+func signWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func newWebhookSecret() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return "whsec_" + hex.EncodeToString(b)
+}
+
+// retryDelay là khoảng chờ sau lần thất bại thứ attempts (1, 2, ...)
+func (wu *webhookUseCase) retryDelay(attempts int) time.Duration {
+	delay := wu.cfg.RetryBase
+	for i := 1; i < attempts && delay < wu.cfg.RetryMax; i++ {
+		delay *= 2
+	}
+	return min(delay, wu.cfg.RetryMax)
+}
+
+// normalizeWebhook kiểm tra URL và danh sách event, loại bỏ event trùng.
+// Khi allowPrivate = false, URL có host là localhost hoặc IP không công khai bị từ chối.
+func normalizeWebhook(w *domain.Webhook, allowPrivate bool) error {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("%w: %q (expected an absolute http or https url)", domain.ErrInvalidWebhookURL, w.URL)
+	}
+	if u.User != nil {
+		return fmt.Errorf("%w: %q (credentials in the url are not allowed)", domain.ErrInvalidWebhookURL, w.URL)
+	}
+	if !allowPrivate {
+		host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+		if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+			return fmt.Errorf("%w: %q (host is not public)", domain.ErrInvalidWebhookURL, w.URL)
+		}
+		if addr, err := netip.ParseAddr(host); err == nil && !domain.IsPublicWebhookAddr(addr) {
+			return fmt.Errorf("%w: %q (address is not public)", domain.ErrInvalidWebhookURL, w.URL)
+		}
+	}
+
+	events := make([]string, 0, len(w.Events))
+	seen := make(map[string]bool, len(w.Events))
+	for _, e := range w.Events {
+		if !webhookEvents[e] {
+			return fmt.Errorf("%w: %q", domain.ErrInvalidWebhookEvent, e)
+		}
+		if !seen[e] {
+			seen[e] = true
+			events = append(events, e)
+		}
+	}
+	w.Events = events
+	return nil
+}
+
+func (wu *webhookUseCase) Fetch(ctx context.Context, page int64, pageSize int64) ([]domain.Webhook, error) {
+	c, cancel := context.WithTimeout(ctx, wu.contextTimeout)
+	defer cancel()
+
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+
+	hooks, err := wu.repo.Fetch(c, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+	for i := range hooks {
+		hooks[i].Secret = ""
+	}
+	return hooks, nil
+}
+
+func (wu *webhookUseCase) GetByID(ctx context.Context, id int64) (*domain.Webhook, error) {
+	c, cancel := context.WithTimeout(ctx, wu.contextTimeout)
+	defer cancel()
+
+	w, err := wu.repo.GetByID(c, id)
+	if err != nil {
+		return nil, err
+	}
+	w.Secret = ""
+	return w, nil
+}
+
+func (wu *webhookUseCase) Store(ctx context.Context, w *domain.Webhook) error {
+	c, cancel := context.WithTimeout(ctx, wu.contextTimeout)
+	defer cancel()
+
+	if err := normalizeWebhook(w, wu.cfg.AllowPrivateNetworks); err != nil {
+		return err
+	}
+	if w.Secret == "" {
+		w.Secret = newWebhookSecret()
+	}
+
+	now := time.Now()
+	w.ID = 0
+	w.Active = true
+	w.FailureCount = 0
+	w.DisabledAt = nil
+	w.CreatedAt = now
+	w.UpdatedAt = now
+	// Secret được giữ trong w để trả về cho client đúng một lần
+	return wu.repo.Store(c, w)
+}
+
+func (wu *webhookUseCase) Update(ctx context.Context, w *domain.Webhook) error {
+	c, cancel := context.WithTimeout(ctx, wu.contextTimeout)
+	defer cancel()
+
+	if err := normalizeWebhook(w, wu.cfg.AllowPrivateNetworks); err != nil {
+		return err
+	}
+
+	existing, err := wu.repo.GetByID(c, w.ID)
+	if err != nil {
+		return err
+	}
+
+	// Secret rỗng nghĩa là giữ nguyên, secret mới được trả về cho client
+	rotated := w.Secret != ""
+	if !rotated {
+		w.Secret = existing.Secret
+	}
+
+	w.FailureCount = existing.FailureCount
+	w.DisabledAt = existing.DisabledAt
+	if w.Active && !existing.Active {
+		// Bật lại sau khi bị tắt: bắt đầu đếm thất bại từ đầu
+		w.FailureCount = 0
+		w.DisabledAt = nil
+	}
+	w.CreatedAt = existing.CreatedAt
+	w.UpdatedAt = time.Now()
+
+	if err := wu.repo.Update(c, w); err != nil {
+		return err
+	}
+	if !rotated {
+		w.Secret = ""
+	}
+	return nil
+}
+
+func (wu *webhookUseCase) Delete(ctx context.Context, id int64) error {
+	c, cancel := context.WithTimeout(ctx, wu.contextTimeout)
+	defer cancel()
+
+	return wu.repo.Delete(c, id)
+}
+
+// --- EventSink ---
+
+func (wu *webhookUseCase) Name() string {
+	return "webhooks"
+}
+
+// Publish ghi mỗi event thành delivery cho các webhook đăng ký nó, việc gửi HTTP do DeliverDue đảm nhận
+// để receiver chậm không làm nghẽn outbox relay
+func (wu *webhookUseCase) Publish(ctx context.Context, events []domain.DomainEvent) (int, error) {
+	c, cancel := context.WithTimeout(ctx, wu.contextTimeout)
+	defer cancel()
+
+	hooks, err := wu.repo.FetchActive(c)
+	if err != nil {
+		return 0, err
+	}
+	if len(hooks) == 0 {
+		return len(events), nil
+	}
+
+	now := time.Now()
+	deliveries := make([]domain.WebhookDelivery, 0, len(events))
+	for _, e := range events {
+		body, err := json.Marshal(webhookEnvelope{
+			EventID:       e.ID,
+			Type:          e.Type,
+			AggregateType: e.AggregateType,
+			AggregateID:   e.AggregateID,
+			OccurredAt:    e.OccurredAt,
+			Data:          e.Payload,
+		})
+		if err != nil {
+			return 0, err
+		}
+		for i := range hooks {
+			if !hooks[i].Accepts(e.Type) {
+				continue
+			}
+			deliveries = append(deliveries, domain.WebhookDelivery{
+				WebhookID:     hooks[i].ID,
+				EventID:       e.ID,
+				EventType:     e.Type,
+				Payload:       body,
+				Status:        domain.WebhookDeliveryPending,
+				NextAttemptAt: &now,
+				CreatedAt:     now,
+			})
+		}
+	}
+
+	// Chia nhỏ để câu INSERT nhiều dòng không vượt giới hạn placeholder khi có nhiều webhook
+	for start := 0; start < len(deliveries); start += webhookDeliveryChunk {
+		end := min(start+webhookDeliveryChunk, len(deliveries))
+		if err := wu.repo.StoreDeliveries(c, deliveries[start:end]); err != nil {
+			return 0, err
+		}
+	}
+	return len(events), nil
+}
+
+// --- Delivery ---
+
+// attempt gửi delivery một lần và ghi kết quả vào d, trả về true khi receiver trả về 2xx
+func (wu *webhookUseCase) attempt(ctx context.Context, d *domain.WebhookDelivery, w *domain.Webhook) bool {
+	now := time.Now()
+	headers := map[string]string{
+		domain.WebhookHeaderID:        strconv.FormatInt(d.ID, 10),
+		domain.WebhookHeaderEvent:     d.EventType,
+		domain.WebhookHeaderTimestamp: strconv.FormatInt(now.Unix(), 10),
+		domain.WebhookHeaderSignature: signWebhook(w.Secret, now.Unix(), d.Payload),
+	}
+
+	resp := wu.sender.Send(ctx, w.URL, headers, d.Payload)
+
+	d.Attempts++
+	d.LastAttemptAt = &now
+	d.DurationMs = resp.Duration.Milliseconds()
+	d.ResponseCode = nil
+	d.ResponseBody = resp.Body
+	d.Error = ""
+	if resp.StatusCode > 0 {
+		code := resp.StatusCode
+		d.ResponseCode = &code
+	}
+
+	switch {
+	case resp.Err != nil:
+		d.Error = resp.Err.Error()
+		return false
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		d.Error = fmt.Sprintf("unexpected status %d", resp.StatusCode)
+		return false
+	default:
+		return true
+	}
+}
+
+// deliver gửi một delivery đã claim, lên lịch thử lại theo exponential backoff khi thất bại
+func (wu *webhookUseCase) deliver(ctx context.Context, d *domain.WebhookDelivery, w *domain.Webhook) {
+	if w == nil || !w.Active {
+		d.Status = domain.WebhookDeliveryFailed
+		d.Error = "webhook is disabled"
+		d.NextAttemptAt = nil
+	} else {
+		ok := wu.attempt(ctx, d, w)
+		switch {
+		case ok:
+			d.Status = domain.WebhookDeliverySucceeded
+			d.NextAttemptAt = nil
+		case d.Attempts >= wu.cfg.MaxAttempts:
+			d.Status = domain.WebhookDeliveryFailed
+			d.NextAttemptAt = nil
+		default:
+			next := time.Now().Add(wu.retryDelay(d.Attempts))
+			d.Status = domain.WebhookDeliveryPending
+			d.NextAttemptAt = &next
+		}
+
+		disabled, err := wu.repo.RecordAttempt(ctx, w.ID, ok, wu.cfg.DisableAfter)
+		if err != nil {
+			slog.ErrorContext(ctx, "webhook: cannot record attempt", "webhook_id", w.ID, "error", err)
+		} else if disabled {
+			slog.WarnContext(ctx, "webhook: disabled after consecutive failures", "webhook_id", w.ID, "url", w.URL, "failures", wu.cfg.DisableAfter)
+			w.Active = false
+		}
+	}
+
+	if err := wu.repo.UpdateDelivery(ctx, d); err != nil {
+		slog.ErrorContext(ctx, "webhook: cannot update delivery", "delivery_id", d.ID, "error", err)
+	}
+}
+
+func (wu *webhookUseCase) DeliverDue(ctx context.Context, limit int) (int, error) {
+	c, cancel := context.WithTimeout(ctx, wu.contextTimeout)
+	due, err := wu.repo.ClaimDueDeliveries(c, time.Now(), wu.cfg.Lease, limit)
+	if err != nil {
+		cancel()
+		return 0, err
+	}
+
+	hooks := make(map[int64]*domain.Webhook)
+	for _, d := range due {
+		if _, ok := hooks[d.WebhookID]; ok {
+			continue
+		}
+		w, err := wu.repo.GetByID(c, d.WebhookID)
+		if err != nil && err != domain.ErrWebhookNotFound {
+			cancel()
+			return 0, err
+		}
+		hooks[d.WebhookID] = w
+	}
+	cancel()
+
+	// Mỗi webhook được gửi tuần tự theo thứ tự claim, các webhook khác nhau chạy song song
+	byHook := make(map[int64][]*domain.WebhookDelivery)
+	order := make([]int64, 0)
+	for i := range due {
+		id := due[i].WebhookID
+		if _, ok := byHook[id]; !ok {
+			order = append(order, id)
+		}
+		byHook[id] = append(byHook[id], &due[i])
+	}
+
+	sem := make(chan struct{}, wu.cfg.Concurrency)
+	var wg sync.WaitGroup
+	for _, id := range order {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(w *domain.Webhook, deliveries []*domain.WebhookDelivery) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			for _, d := range deliveries {
+				wu.deliver(ctx, d, w)
+			}
+		}(hooks[id], byHook[id])
+	}
+	wg.Wait()
+
+	return len(due), nil
+}
+
+func (wu *webhookUseCase) Test(ctx context.Context, id int64) (*domain.WebhookDelivery, error) {
+	c, cancel := context.WithTimeout(ctx, wu.contextTimeout)
+	defer cancel()
+
+	w, err := wu.repo.GetByID(c, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	body, err := json.Marshal(webhookEnvelope{
+		Type:       domain.WebhookEventPing,
+		OccurredAt: now,
+		Data:       json.RawMessage(`{"webhook_id":` + strconv.FormatInt(w.ID, 10) + `}`),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Ping không có next_attempt_at nên không bị worker gửi lại, và không tính vào số lần thất bại của webhook
+	deliveries := []domain.WebhookDelivery{{
+		WebhookID: w.ID,
+		EventType: domain.WebhookEventPing,
+		Payload:   body,
+		Status:    domain.WebhookDeliveryPending,
+		CreatedAt: now,
+	}}
+	if err := wu.repo.StoreDeliveries(c, deliveries); err != nil {
+		return nil, err
+	}
+	d := &deliveries[0]
+
+	// Request HTTP dùng context của client thay vì contextTimeout, sender đã có timeout riêng
+	if wu.attempt(ctx, d, w) {
+		d.Status = domain.WebhookDeliverySucceeded
+	} else {
+		d.Status = domain.WebhookDeliveryFailed
+	}
+
+	uc, ucancel := context.WithTimeout(context.WithoutCancel(ctx), wu.contextTimeout)
+	defer ucancel()
+	if err := wu.repo.UpdateDelivery(uc, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (wu *webhookUseCase) FetchDeliveries(ctx context.Context, filter domain.WebhookDeliveryFilter, page int64, pageSize int64) ([]domain.WebhookDelivery, error) {
+	c, cancel := context.WithTimeout(ctx, wu.contextTimeout)
+	defer cancel()
+
+	switch filter.Status {
+	case "", domain.WebhookDeliveryPending, domain.WebhookDeliverySucceeded, domain.WebhookDeliveryFailed:
+	default:
+		return nil, fmt.Errorf("%w: %q", domain.ErrInvalidWebhookStatus, filter.Status)
+	}
+
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+	return wu.repo.FetchDeliveries(c, filter, pageSize, (page-1)*pageSize)
+}
+
+func (wu *webhookUseCase) GetDelivery(ctx context.Context, id int64) (*domain.WebhookDelivery, error) {
+	c, cancel := context.WithTimeout(ctx, wu.contextTimeout)
+	defer cancel()
+
+	return wu.repo.GetDelivery(c, id)
+}
+
+func (wu *webhookUseCase) Replay(ctx context.Context, id int64) (*domain.WebhookDelivery, error) {
+	c, cancel := context.WithTimeout(ctx, wu.contextTimeout)
+	defer cancel()
+
+	original, err := wu.repo.GetDelivery(c, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	deliveries := []domain.WebhookDelivery{{
+		WebhookID:     original.WebhookID,
+		EventID:       original.EventID,
+		EventType:     original.EventType,
+		Payload:       original.Payload,
+		Status:        domain.WebhookDeliveryPending,
+		ReplayOf:      &original.ID,
+		NextAttemptAt: &now,
+		CreatedAt:     now,
+	}}
+	if err := wu.repo.StoreDeliveries(c, deliveries); err != nil {
+		return nil, err
+	}
+	return &deliveries[0], nil
+}
@@ -0,0 +1,167 @@
+package usecase
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"Test2/internal/domain"
+)
+
+// Đọc file WXR (WordPress eXtended RSS) theo kiểu streaming: mỗi <item> và <wp:category>
+// được giải mã riêng nên bộ nhớ chỉ phụ thuộc kích thước một bài viết.
+// Tag không kèm namespace của encoding/xml khớp mọi namespace (wp: 1.0, 1.1, 1.2).
+
+// wxrDateLayout là định dạng ngày của WordPress (post_date, post_modified, ...)
+const wxrDateLayout = "2006-01-02 15:04:05"
+
+// wxrStatuses ánh xạ trạng thái WordPress sang domain, trạng thái khác (private, future, ...) bị bỏ qua
+var wxrStatuses = map[string]string{
+	"publish": domain.StatusPublished,
+	"draft":   domain.StatusDraft,
+	"pending": domain.StatusPending,
+	"trash":   domain.StatusDeleted,
+}
+
+type wxrCategory struct {
+	TermID      int64  `xml:"term_id"`
+	Nicename    string `xml:"category_nicename"`
+	Parent      string `xml:"category_parent"` // nicename của danh mục cha
+	Name        string `xml:"cat_name"`
+	Description string `xml:"category_description"`
+}
+
+// wxrEncoded là <content:encoded> hoặc <excerpt:encoded>, phân biệt theo namespace
+type wxrEncoded struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+type wxrItemCategory struct {
+	Domain   string `xml:"domain,attr"` // category | post_tag | ...
+	Nicename string `xml:"nicename,attr"`
+	Name     string `xml:",chardata"`
+}
+
+type wxrPostMeta struct {
+	Key   string `xml:"meta_key"`
+	Value string `xml:"meta_value"`
+}
+
+type wxrItem struct {
+	Title       string            `xml:"title"`
+	Encoded     []wxrEncoded      `xml:"encoded"`
+	PostID      int64             `xml:"post_id"`
+	PostDate    string            `xml:"post_date"`
+	PostDateGMT string            `xml:"post_date_gmt"`
+	Modified    string            `xml:"post_modified"`
+	ModifiedGMT string            `xml:"post_modified_gmt"`
+	Name        string            `xml:"post_name"`
+	Status      string            `xml:"status"`
+	Type        string            `xml:"post_type"`
+	Categories  []wxrItemCategory `xml:"category"`
+	Meta        []wxrPostMeta     `xml:"postmeta"`
+}
+
+func (it *wxrItem) encoded(kind string) string {
+	for _, e := range it.Encoded {
+		if strings.Contains(e.XMLName.Space, kind) {
+			return e.Value
+		}
+	}
+	return ""
+}
+
+func (it *wxrItem) meta(key string) string {
+	for _, m := range it.Meta {
+		if m.Key == key {
+			return m.Value
+		}
+	}
+	return ""
+}
+
+// wxrReader trả về lần lượt *wxrCategory và *wxrItem theo thứ tự trong file
+type wxrReader struct {
+	dec     *xml.Decoder
+	started bool
+	siteURL string // <wp:base_blog_url> (hoặc <wp:base_site_url>), nằm trong <channel> trước các <item>
+}
+
+func newWXRReader(r io.Reader) *wxrReader {
+	return &wxrReader{dec: xml.NewDecoder(r)}
+}
+
+func (r *wxrReader) Next() (any, error) {
+	for {
+		tok, err := r.dec.Token()
+		if err != nil {
+			if err == io.EOF && !r.started {
+				return nil, fmt.Errorf("%w: empty document", domain.ErrInvalidWXR)
+			}
+			if err != io.EOF {
+				err = fmt.Errorf("%w: %v", domain.ErrInvalidWXR, err)
+			}
+			return nil, err
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if !r.started {
+			if start.Name.Local != "rss" {
+				return nil, fmt.Errorf("%w: root element is <%s>, expected <rss>", domain.ErrInvalidWXR, start.Name.Local)
+			}
+			r.started = true
+			continue
+		}
+
+		switch {
+		case start.Name.Local == "item":
+			item := &wxrItem{}
+			if err := r.dec.DecodeElement(item, &start); err != nil {
+				return nil, fmt.Errorf("%w: %v", domain.ErrInvalidWXR, err)
+			}
+			return item, nil
+		case (start.Name.Local == "base_blog_url" || start.Name.Local == "base_site_url") && strings.Contains(start.Name.Space, "wordpress.org/export"):
+			var url string
+			if err := r.dec.DecodeElement(&url, &start); err != nil {
+				return nil, fmt.Errorf("%w: %v", domain.ErrInvalidWXR, err)
+			}
+			if start.Name.Local == "base_blog_url" || r.siteURL == "" {
+				r.siteURL = strings.TrimSpace(url)
+			}
+		case start.Name.Local == "category" && strings.Contains(start.Name.Space, "wordpress.org/export"):
+			cat := &wxrCategory{}
+			if err := r.dec.DecodeElement(cat, &start); err != nil {
+				return nil, fmt.Errorf("%w: %v", domain.ErrInvalidWXR, err)
+			}
+			return cat, nil
+		}
+	}
+}
+
+// parseWXRDate ưu tiên giá trị GMT, bài nháp có *_gmt là "0000-00-00 00:00:00" thì dùng giờ local
+func parseWXRDate(gmt, local string) *time.Time {
+	if t, err := time.ParseInLocation(wxrDateLayout, gmt, time.UTC); err == nil {
+		return &t
+	}
+	if t, err := time.ParseInLocation(wxrDateLayout, local, time.Local); err == nil {
+		return &t
+	}
+	return nil
+}
+
+// parseWXRUnix đọc timestamp dạng giây (ví dụ _wp_trash_meta_time)
+func parseWXRUnix(raw string) *time.Time {
+	sec, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+	if err != nil || sec <= 0 {
+		return nil
+	}
+	t := time.Unix(sec, 0)
+	return &t
+}
@@ -0,0 +1,536 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"time"
+
+	"Test2/internal/domain"
+)
+
+type wxrImportUseCase struct {
+	posts          domain.PostUseCase
+	postRepo       domain.PostRepository
+	cates          domain.CategoryUseCase
+	cateRepo       domain.CategoryRepository
+	contextTimeout time.Duration
+}
+
+// NewWXRImportUseCase khởi tạo WXRImportUseCase. Bài viết được ghi qua PostUseCase.ImportPosts
+// (khớp theo post_id của WordPress, giữ nguyên ngày tháng của WordPress), danh mục qua CategoryUseCase.
+func NewWXRImportUseCase(
+	posts domain.PostUseCase,
+	postRepo domain.PostRepository,
+	cates domain.CategoryUseCase,
+	cateRepo domain.CategoryRepository,
+	timeout time.Duration,
+) domain.WXRImportUseCase {
+	return &wxrImportUseCase{
+		posts:          posts,
+		postRepo:       postRepo,
+		cates:          cates,
+		cateRepo:       cateRepo,
+		contextTimeout: timeout,
+	}
+}
+
+// wxrPending là bài viết/trang đang chờ ghi theo lô, giữ thông tin nguồn để báo cáo lỗi
+type wxrPending struct {
+	kind     string
+	counts   *domain.WXRImportCounts
+	sourceID int64
+	title    string
+	post     domain.Post
+}
+
+// wxrRun là trạng thái của một lần import
+type wxrRun struct {
+	uc      *wxrImportUseCase
+	summary *domain.WXRImportSummary
+
+	categories     []*wxrCategory
+	categoriesDone bool
+	cateIDs        map[string]*int64           // nicename -> id danh mục (nil: không import được)
+	byTitle        map[string]*domain.Category // tên (chữ thường) -> danh mục đang có
+	slugs          map[string]int64            // slug -> post_id đã dùng slug đó trong lần chạy này
+	pagesCategory  *int64                      // danh mục WXRPagesCategory, nạp khi gặp trang đầu tiên
+	pagesLoaded    bool
+
+	site  string // base_blog_url của file, phân biệt post_id của các site WordPress khác nhau
+	posts []wxrPending
+}
+
+func (uc *wxrImportUseCase) Import(ctx context.Context, r io.Reader) (*domain.WXRImportSummary, error) {
+	run := &wxrRun{
+		uc:      uc,
+		summary: &domain.WXRImportSummary{Skipped: []domain.WXRSkippedItem{}},
+		cateIDs: map[string]*int64{},
+		byTitle: map[string]*domain.Category{},
+		slugs:   map[string]int64{},
+	}
+
+	reader := newWXRReader(r)
+	for {
+		v, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return run.summary, err
+		}
+
+		switch v := v.(type) {
+		case *wxrCategory:
+			run.categories = append(run.categories, v)
+		case *wxrItem:
+			// <wp:category> luôn nằm trước <item>, import danh mục trước để bài viết gắn được category_id
+			if !run.categoriesDone {
+				if err := run.importCategories(ctx); err != nil {
+					return run.summary, err
+				}
+			}
+			run.site = reader.siteURL
+			if err := run.addItem(ctx, v); err != nil {
+				return run.summary, err
+			}
+		}
+	}
+
+	if !run.categoriesDone {
+		if err := run.importCategories(ctx); err != nil {
+			return run.summary, err
+		}
+	}
+	if err := run.flush(ctx); err != nil {
+		return run.summary, err
+	}
+	return run.summary, nil
+}
+
+func (run *wxrRun) skip(counts *domain.WXRImportCounts, kind string, sourceID int64, title string, reason string) {
+	counts.Skipped++
+	run.summary.Skipped = append(run.summary.Skipped, domain.WXRSkippedItem{
+		Kind:     kind,
+		SourceID: sourceID,
+		Title:    title,
+		Reason:   reason,
+	})
+}
+
+// --- CATEGORY ---
+
+// loadCategories nạp các danh mục đang có theo tên vào run.byTitle
+func (run *wxrRun) loadCategories(ctx context.Context, titles []string) error {
+	c, cancel := context.WithTimeout(ctx, run.uc.contextTimeout)
+	defer cancel()
+
+	existing, err := run.uc.cateRepo.FetchByTitles(c, titles)
+	if err != nil {
+		return err
+	}
+	for i := range existing {
+		run.byTitle[strings.ToLower(existing[i].Title)] = &existing[i]
+	}
+	return nil
+}
+
+func (run *wxrRun) importCategories(ctx context.Context) error {
+	run.categoriesDone = true
+
+	byNicename := make(map[string]*wxrCategory, len(run.categories))
+	titles := make([]string, 0, len(run.categories))
+	for _, wc := range run.categories {
+		byNicename[wc.Nicename] = wc
+		titles = append(titles, categoryTitle(wc.Name))
+	}
+	if err := run.loadCategories(ctx, titles); err != nil {
+		return err
+	}
+
+	// Danh mục cha luôn được import trước danh mục con, bất kể thứ tự trong file
+	visiting := map[string]bool{}
+	var ensure func(wc *wxrCategory) (*int64, error)
+	ensure = func(wc *wxrCategory) (*int64, error) {
+		if id, ok := run.cateIDs[wc.Nicename]; ok {
+			return id, nil
+		}
+		if visiting[wc.Nicename] {
+			run.skip(&run.summary.Categories, domain.WXRKindCategory, wc.TermID, categoryTitle(wc.Name), "category parent cycle")
+			run.cateIDs[wc.Nicename] = nil
+			return nil, nil
+		}
+		visiting[wc.Nicename] = true
+
+		var parentID *int64
+		if parent, ok := byNicename[wc.Parent]; ok && wc.Parent != "" {
+			id, err := ensure(parent)
+			if err != nil {
+				return nil, err
+			}
+			parentID = id
+		}
+
+		if id, ok := run.cateIDs[wc.Nicename]; ok {
+			return id, nil
+		}
+		id, err := run.importCategory(ctx, wc.TermID, categoryTitle(wc.Name), html.UnescapeString(wc.Description), parentID)
+		if err != nil {
+			return nil, err
+		}
+		run.cateIDs[wc.Nicename] = id
+		return id, nil
+	}
+
+	for _, wc := range run.categories {
+		if _, err := ensure(wc); err != nil {
+			return err
+		}
+	}
+	run.categories = nil
+	return nil
+}
+
+// importCategory tạo mới hoặc cập nhật một danh mục theo tên, trả về id để gắn cho bài viết.
+// Lỗi nghiệp vụ được ghi vào báo cáo, chỉ trả về error khi context đã bị hủy.
+func (run *wxrRun) importCategory(ctx context.Context, termID int64, title, description string, parentID *int64) (*int64, error) {
+	counts := &run.summary.Categories
+	if title == "" {
+		run.skip(counts, domain.WXRKindCategory, termID, title, "missing category name")
+		return nil, nil
+	}
+
+	if existing, ok := run.byTitle[strings.ToLower(title)]; ok {
+		if existing.Status == domain.CategoryStatusInactive {
+			run.skip(counts, domain.WXRKindCategory, termID, title, "category is in the trash")
+			return nil, nil
+		}
+		if existing.Description == description && sameParent(existing.ParentID, parentID) {
+			counts.Unchanged++
+			return &existing.ID, nil
+		}
+
+		updated := *existing
+		updated.Description = description
+		updated.ParentID = parentID
+		if err := run.uc.cates.Update(ctx, &updated); err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			// Vẫn gắn bài viết vào danh mục đang có dù không cập nhật được
+			run.skip(counts, domain.WXRKindCategory, termID, title, err.Error())
+			return &existing.ID, nil
+		}
+		counts.Updated++
+		*existing = updated
+		return &existing.ID, nil
+	}
+
+	c := &domain.Category{Title: title, Description: description, ParentID: parentID}
+	if err := run.uc.cates.Store(ctx, c); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		run.skip(counts, domain.WXRKindCategory, termID, title, err.Error())
+		return nil, nil
+	}
+	counts.Created++
+	run.byTitle[strings.ToLower(title)] = c
+	return &c.ID, nil
+}
+
+// itemCategory trả về danh mục đầu tiên của bài viết (domain.Post chỉ có một category_id).
+// Danh mục chỉ khai báo trong <item> (file export một phần) được tạo như danh mục gốc.
+func (run *wxrRun) itemCategory(ctx context.Context, item *wxrItem) (*int64, error) {
+	for _, ic := range item.Categories {
+		if ic.Domain != "category" {
+			continue
+		}
+		if id, ok := run.cateIDs[ic.Nicename]; ok {
+			return id, nil
+		}
+
+		title := categoryTitle(ic.Name)
+		if _, ok := run.byTitle[strings.ToLower(title)]; !ok && title != "" {
+			if err := run.loadCategories(ctx, []string{title}); err != nil {
+				return nil, err
+			}
+		}
+		// <item> không mang mô tả/danh mục cha nên danh mục đang có được dùng nguyên trạng
+		if existing, ok := run.byTitle[strings.ToLower(title)]; ok && existing.Status != domain.CategoryStatusInactive {
+			run.summary.Categories.Unchanged++
+			run.cateIDs[ic.Nicename] = &existing.ID
+			return &existing.ID, nil
+		}
+		id, err := run.importCategory(ctx, 0, title, "", nil)
+		if err != nil {
+			return nil, err
+		}
+		run.cateIDs[ic.Nicename] = id
+		return id, nil
+	}
+	return nil, nil
+}
+
+func categoryTitle(name string) string {
+	return strings.TrimSpace(html.UnescapeString(name))
+}
+
+// pageCategory trả về id danh mục WXRPagesCategory, dùng danh mục đang có cùng tên hoặc tạo mới
+func (run *wxrRun) pageCategory(ctx context.Context) (*int64, error) {
+	if run.pagesLoaded {
+		return run.pagesCategory, nil
+	}
+	title := domain.WXRPagesCategory
+	if _, ok := run.byTitle[strings.ToLower(title)]; !ok {
+		if err := run.loadCategories(ctx, []string{title}); err != nil {
+			return nil, err
+		}
+	}
+	if existing, ok := run.byTitle[strings.ToLower(title)]; ok && existing.Status != domain.CategoryStatusInactive {
+		run.summary.Categories.Unchanged++
+		run.pagesCategory = &existing.ID
+	} else {
+		id, err := run.importCategory(ctx, 0, title, "Pages imported from WordPress", nil)
+		if err != nil {
+			return nil, err
+		}
+		run.pagesCategory = id
+	}
+	run.pagesLoaded = true
+	return run.pagesCategory, nil
+}
+
+// --- POST & PAGE ---
+
+func (run *wxrRun) addItem(ctx context.Context, item *wxrItem) error {
+	title := strings.TrimSpace(html.UnescapeString(item.Title))
+	var counts *domain.WXRImportCounts
+	switch item.Type {
+	case domain.WXRKindPost:
+		counts = &run.summary.Posts
+	case domain.WXRKindPage:
+		counts = &run.summary.Pages
+	default:
+		// attachment, nav_menu_item, revision, ... chỉ được đếm, không liệt kê chi tiết
+		run.summary.Other.Skipped++
+		return nil
+	}
+
+	status, ok := wxrStatuses[item.Status]
+	if !ok {
+		run.skip(counts, item.Type, item.PostID, title, fmt.Sprintf("unsupported status %q", item.Status))
+		return nil
+	}
+
+	slug := wxrSlug(item)
+	if other, used := run.slugs[slug]; used {
+		run.skip(counts, item.Type, item.PostID, title, fmt.Sprintf("slug %q is already used by WordPress post %d", slug, other))
+		return nil
+	}
+	run.slugs[slug] = item.PostID
+
+	p := domain.Post{
+		Title:         title,
+		Slug:          slug,
+		Description:   item.encoded("excerpt"),
+		Content:       item.encoded("content"),
+		ContentFormat: domain.ContentFormatHTML,
+		Status:        status,
+		SourceID:      wxrSourceID(run.site, item.PostID),
+	}
+
+	// Trang của WordPress không có danh mục, được gom vào danh mục riêng để tách khỏi bài viết
+	var categoryID *int64
+	var err error
+	if item.Type == domain.WXRKindPage {
+		categoryID, err = run.pageCategory(ctx)
+	} else {
+		categoryID, err = run.itemCategory(ctx, item)
+	}
+	if err != nil {
+		return err
+	}
+	p.CategoryID = categoryID
+
+	posted := parseWXRDate(item.PostDateGMT, item.PostDate)
+	if posted != nil {
+		p.CreatedAt = *posted
+	}
+	if modified := parseWXRDate(item.ModifiedGMT, item.Modified); modified != nil {
+		p.UpdateDate = *modified
+	}
+
+	publishedStatus := status
+	if status == domain.StatusDeleted {
+		// WordPress lưu trạng thái trước khi vào thùng rác trong postmeta
+		p.PreviousStatus = wxrStatuses[item.meta("_wp_trash_meta_status")]
+		p.DeletedAt = parseWXRUnix(item.meta("_wp_trash_meta_time"))
+		publishedStatus = p.PreviousStatus
+	}
+	if publishedStatus == domain.StatusPublished {
+		p.PublishDate = posted
+	}
+
+	run.posts = append(run.posts, wxrPending{kind: item.Type, counts: counts, sourceID: item.PostID, title: title, post: p})
+	if len(run.posts) >= domain.BatchMaxItems {
+		return run.flush(ctx)
+	}
+	return nil
+}
+
+// flush ghi các bài viết/trang đang chờ qua PostUseCase.ImportPosts. Bài viết khớp với bài đã import trước đó
+// theo SourceID, bài không đổi gì được đếm là Unchanged, bài có slug trùng bài không do import này tạo bị bỏ qua.
+func (run *wxrRun) flush(ctx context.Context) error {
+	if len(run.posts) == 0 {
+		return nil
+	}
+	defer func() { run.posts = run.posts[:0] }()
+
+	byID, bySlug, err := run.loadExisting(ctx)
+	if err != nil {
+		return err
+	}
+
+	pending := make([]wxrPending, 0, len(run.posts))
+	posts := make([]domain.Post, 0, len(run.posts))
+	for _, item := range run.posts {
+		p := item.post
+		if owner, ok := bySlug[p.Slug]; ok && (p.SourceID == "" || owner.SourceID != p.SourceID) {
+			run.skip(item.counts, item.kind, item.sourceID, item.title, fmt.Sprintf("slug %q is already used by post %d", p.Slug, owner.ID))
+			continue
+		}
+		if before, ok := byID[p.SourceID]; ok {
+			if sameWXRPost(before, &p) {
+				item.counts.Unchanged++
+				continue
+			}
+			p.ID = before.ID
+		}
+		pending = append(pending, item)
+		posts = append(posts, p)
+	}
+	if len(posts) == 0 {
+		return nil
+	}
+
+	report, err := run.uc.posts.ImportPosts(ctx, posts, false)
+	if err != nil {
+		return err
+	}
+
+	// Báo cáo chỉ liệt kê dòng lỗi, dòng còn lại được tạo mới (không có ID) hoặc cập nhật (có ID)
+	failed := make(map[int]string, len(report.Errors))
+	for _, e := range report.Errors {
+		failed[e.Row-1] = e.Error
+	}
+	for i, item := range pending {
+		switch reason, ok := failed[i]; {
+		case ok:
+			run.skip(item.counts, item.kind, item.sourceID, item.title, reason)
+		case posts[i].ID == 0:
+			item.counts.Created++
+		default:
+			item.counts.Updated++
+		}
+	}
+	return nil
+}
+
+// loadExisting nạp các bài viết đã import trước đó (theo SourceID) và các bài đang giữ slug của lô,
+// kết quả được đánh chỉ mục theo SourceID và theo slug
+func (run *wxrRun) loadExisting(ctx context.Context) (bySource, bySlug map[string]*domain.Post, err error) {
+	c, cancel := context.WithTimeout(ctx, run.uc.contextTimeout)
+	defer cancel()
+
+	sourceIDs := make([]string, 0, len(run.posts))
+	slugs := make([]string, 0, len(run.posts))
+	for _, item := range run.posts {
+		if item.post.SourceID != "" {
+			sourceIDs = append(sourceIDs, item.post.SourceID)
+		}
+		slugs = append(slugs, item.post.Slug)
+	}
+
+	ids, err := run.uc.postRepo.FetchIDsBySource(c, sourceIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	sourceByID := make(map[int64]string, len(ids))
+	idList := make([]int64, 0, len(ids))
+	for sourceID, id := range ids {
+		sourceByID[id] = sourceID
+		idList = append(idList, id)
+	}
+
+	existing, err := run.uc.postRepo.FetchByKeys(c, idList, slugs)
+	if err != nil {
+		return nil, nil, err
+	}
+	bySource = make(map[string]*domain.Post, len(existing))
+	bySlug = make(map[string]*domain.Post, len(existing))
+	for i := range existing {
+		p := &existing[i]
+		// FetchByKeys không đọc source_id, gắn lại từ kết quả tra cứu ở trên
+		p.SourceID = sourceByID[p.ID]
+		if p.SourceID != "" {
+			bySource[p.SourceID] = p
+		}
+		if p.Slug != "" {
+			bySlug[p.Slug] = p
+		}
+	}
+	return bySource, bySlug, nil
+}
+
+// sameWXRPost so sánh bài viết đang có với bài đọc từ file, các mốc thời gian file không có thì không so sánh
+func sameWXRPost(before, p *domain.Post) bool {
+	if before.Title != p.Title || before.Slug != p.Slug || before.Description != p.Description ||
+		before.Content != p.Content || before.ContentFormat != p.ContentFormat ||
+		before.Status != p.Status || !sameParent(before.CategoryID, p.CategoryID) {
+		return false
+	}
+	if !p.CreatedAt.IsZero() && !before.CreatedAt.Equal(p.CreatedAt) {
+		return false
+	}
+	if !p.UpdateDate.IsZero() && !before.UpdateDate.Equal(p.UpdateDate) {
+		return false
+	}
+	if p.PublishDate != nil && (before.PublishDate == nil || !before.PublishDate.Equal(*p.PublishDate)) {
+		return false
+	}
+	if p.Status == domain.StatusDeleted {
+		if p.PreviousStatus != "" && before.PreviousStatus != p.PreviousStatus {
+			return false
+		}
+		if p.DeletedAt != nil && (before.DeletedAt == nil || !before.DeletedAt.Equal(*p.DeletedAt)) {
+			return false
+		}
+	}
+	return true
+}
+
+// wxrSourceID định danh bài viết WordPress theo site và post_id, file không có base_blog_url chỉ dùng post_id
+func wxrSourceID(site string, postID int64) string {
+	if postID <= 0 {
+		return ""
+	}
+	site = strings.TrimSuffix(strings.TrimSpace(site), "/")
+	if site == "" || len(site) > 200 {
+		return fmt.Sprintf("wordpress:%d", postID)
+	}
+	return fmt.Sprintf("wordpress:%s#%d", site, postID)
+}
+
+// wxrSlug dùng post_name của WordPress nếu hợp lệ. post_name rỗng (bản nháp), chứa ký tự
+// ngoài ASCII (được WordPress percent-encode) hoặc hậu tố "__trashed" thì dùng slug cố định
+// theo post_id để các lần chạy lại vẫn khớp cùng một bài viết.
+func wxrSlug(item *wxrItem) string {
+	slug := strings.ToLower(strings.TrimSpace(item.Name))
+	if slug != "" && len(slug) <= 255 && slugPattern.MatchString(slug) {
+		return slug
+	}
+	return fmt.Sprintf("wp-%s-%d", item.Type, item.PostID)
+}
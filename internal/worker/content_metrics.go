@@ -0,0 +1,92 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"Test2/internal/domain"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	postsGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "cms_posts",
+		Help: "Số bài viết theo trạng thái (Deleted: trong thùng rác), làm mới theo metrics.content_interval.",
+	}, []string{"status"})
+	categoriesGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "cms_categories",
+		Help: "Số danh mục theo trạng thái (Inactive: trong thùng rác), làm mới theo metrics.content_interval.",
+	}, []string{"status"})
+)
+
+// ContentMetricsJob định kỳ đếm bài viết và danh mục theo trạng thái cho metric cms_posts và cms_categories
+type ContentMetricsJob struct {
+	posts      domain.PostRepository
+	categories domain.CategoryRepository
+	interval   time.Duration
+	timeout    time.Duration
+}
+
+func NewContentMetricsJob(
+	posts domain.PostRepository,
+	categories domain.CategoryRepository,
+	interval time.Duration,
+	timeout time.Duration,
+) *ContentMetricsJob {
+	return &ContentMetricsJob{
+		posts:      posts,
+		categories: categories,
+		interval:   interval,
+		timeout:    timeout,
+	}
+}
+
+// Run chạy tới khi ctx bị hủy, mỗi interval làm mới một lượt
+func (j *ContentMetricsJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		j.runOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (j *ContentMetricsJob) runOnce(ctx context.Context) {
+	c, cancel := context.WithTimeout(ctx, j.timeout)
+	defer cancel()
+
+	// Lượt lỗi giữ nguyên giá trị cũ thay vì báo 0
+	posts, err := j.posts.CountByStatus(c)
+	if err != nil {
+		slog.ErrorContext(ctx, "content metrics: count posts failed", "error", err)
+	} else {
+		setStatusGauge(postsGauge, posts, domain.StatusDraft, domain.StatusPending, domain.StatusPublished, domain.StatusDeleted)
+	}
+
+	categories, err := j.categories.CountByStatus(c)
+	if err != nil {
+		slog.ErrorContext(ctx, "content metrics: count categories failed", "error", err)
+	} else {
+		setStatusGauge(categoriesGauge, categories, domain.CategoryStatusActive, domain.CategoryStatusInactive)
+	}
+}
+
+// setStatusGauge thay toàn bộ giá trị của gauge, trạng thái đã biết không còn bản ghi nào được đặt về 0
+func setStatusGauge(gauge *prometheus.GaugeVec, counts map[string]int64, known ...string) {
+	gauge.Reset()
+	for _, status := range known {
+		gauge.WithLabelValues(status).Set(0)
+	}
+	for status, n := range counts {
+		gauge.WithLabelValues(status).Set(float64(n))
+	}
+}
@@ -0,0 +1,147 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"Test2/internal/domain"
+)
+
+// OutboxRelay định kỳ đọc domain event trong outbox và giao tới các sink theo thứ tự id.
+// Event chỉ bị xóa khỏi outbox sau khi mọi sink đã nhận nên được giao ít nhất một lần.
+// Relay nhớ từng event mỗi sink đã nhận (theo ID, không theo mốc ID lớn nhất vì event có ID nhỏ hơn
+// có thể commit muộn hơn): khi một sink lỗi, các sink khác vẫn nhận tiếp và lượt sau chỉ sink lỗi
+// được giao lại (sau khi khởi động lại, sink có thể nhận trùng).
+// Sink dựa trên Redis trả về ErrCacheUnavailable khi circuit breaker đang mở, các event đó
+// được bỏ qua cho sink ấy thay vì giữ outbox lại và làm nghẽn webhook tới khi Redis phục hồi.
+type OutboxRelay struct {
+	outbox    domain.OutboxRepository
+	sinks     []domain.EventSink
+	acked     []map[int64]struct{} // ID các event còn trong outbox mà sinks[i] đã nhận
+	interval  time.Duration
+	batchSize int
+}
+
+func NewOutboxRelay(
+	outbox domain.OutboxRepository,
+	interval time.Duration,
+	batchSize int,
+	sinks ...domain.EventSink,
+) *OutboxRelay {
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	acked := make([]map[int64]struct{}, len(sinks))
+	for i := range acked {
+		acked[i] = make(map[int64]struct{})
+	}
+	return &OutboxRelay{
+		outbox:    outbox,
+		sinks:     sinks,
+		acked:     acked,
+		interval:  interval,
+		batchSize: batchSize,
+	}
+}
+
+// Run chạy tới khi ctx bị hủy. Lô đầy được giao tiếp ngay, lô thiếu hoặc lỗi thì chờ interval.
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := r.runOnce(ctx)
+			if err != nil {
+				if !errors.Is(err, domain.ErrOutboxBusy) && ctx.Err() == nil {
+					slog.ErrorContext(ctx, "outbox relay: dispatch failed", "delivered", n, "error", err)
+				}
+				break
+			}
+			if n < r.batchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *OutboxRelay) runOnce(ctx context.Context) (int, error) {
+	return r.outbox.Dispatch(ctx, r.batchSize, r.publish)
+}
+
+// publish giao tới từng sink các event trong lô mà sink đó chưa nhận,
+// trả về số event đầu tiên mà mọi sink đều đã nhận
+func (r *OutboxRelay) publish(ctx context.Context, events []domain.DomainEvent) (int, error) {
+	var errs []error
+	for i, sink := range r.sinks {
+		pending := make([]domain.DomainEvent, 0, len(events))
+		for _, e := range events {
+			if _, ok := r.acked[i][e.ID]; !ok {
+				pending = append(pending, e)
+			}
+		}
+		if len(pending) == 0 {
+			continue
+		}
+
+		n, err := sink.Publish(ctx, pending)
+		n = max(0, min(n, len(pending)))
+		if errors.Is(err, domain.ErrCacheUnavailable) {
+			// Circuit breaker đã ghi log lỗi Redis, ở đây chỉ ghi nhận số event sink này bị mất
+			slog.WarnContext(ctx, "outbox relay: sink unavailable, skipping events",
+				"sink", sink.Name(), "skipped", len(pending)-n, "last_event_id", pending[len(pending)-1].ID)
+			n = len(pending)
+		} else if err != nil {
+			errs = append(errs, fmt.Errorf("sink %s: %w", sink.Name(), err))
+		}
+		for _, e := range pending[:n] {
+			r.acked[i][e.ID] = struct{}{}
+		}
+	}
+
+	delivered := 0
+	for delivered < len(events) && r.ackedByAll(events[delivered].ID) {
+		delivered++
+	}
+	r.prune(events, delivered)
+	return delivered, errors.Join(errs...)
+}
+
+func (r *OutboxRelay) ackedByAll(id int64) bool {
+	for _, acked := range r.acked {
+		if _, ok := acked[id]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// prune quên các ID không còn trong outbox: delivered event đầu lô sắp bị Dispatch xóa, và mọi ID
+// không lớn hơn ID cuối lô mà không có trong lô (đã bị xóa, kể cả bởi relay của instance khác).
+// ID lớn hơn ID cuối lô được giữ vì chỉ bị đẩy ra khỏi lô bởi event commit muộn.
+func (r *OutboxRelay) prune(events []domain.DomainEvent, delivered int) {
+	if len(events) == 0 {
+		return
+	}
+	remaining := make(map[int64]struct{}, len(events)-delivered)
+	for _, e := range events[delivered:] {
+		remaining[e.ID] = struct{}{}
+	}
+	last := events[len(events)-1].ID
+	for _, acked := range r.acked {
+		for id := range acked {
+			if _, ok := remaining[id]; !ok && id <= last {
+				delete(acked, id)
+			}
+		}
+	}
+}
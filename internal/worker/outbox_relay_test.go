@@ -0,0 +1,186 @@
+package worker
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"Test2/internal/domain"
+)
+
+// fakeOutbox giữ event trong bộ nhớ và xóa phần publish báo đã giao, giống Dispatch của MySQL
+type fakeOutbox struct {
+	events []domain.DomainEvent
+}
+
+// Append chèn event theo ID, event có ID nhỏ hơn commit muộn nằm trước event đã có
+func (o *fakeOutbox) Append(_ context.Context, events []domain.DomainEvent) error {
+	o.events = append(o.events, events...)
+	slices.SortFunc(o.events, func(a, b domain.DomainEvent) int { return cmp.Compare(a.ID, b.ID) })
+	return nil
+}
+
+func (o *fakeOutbox) Dispatch(ctx context.Context, limit int, publish func(ctx context.Context, events []domain.DomainEvent) (int, error)) (int, error) {
+	batch := o.events[:min(limit, len(o.events))]
+	if len(batch) == 0 {
+		return 0, nil
+	}
+	n, err := publish(ctx, batch)
+	o.events = o.events[n:]
+	return n, err
+}
+
+// fakeSink ghi lại ID của mọi event được giao, respond quyết định kết quả của lần gọi thứ call
+type fakeSink struct {
+	name     string
+	respond  func(call int, events []domain.DomainEvent) (int, error)
+	calls    int
+	received []int64
+}
+
+func (s *fakeSink) Name() string { return s.name }
+
+func (s *fakeSink) Publish(_ context.Context, events []domain.DomainEvent) (int, error) {
+	for _, e := range events {
+		s.received = append(s.received, e.ID)
+	}
+	s.calls++
+	if s.respond == nil {
+		return len(events), nil
+	}
+	return s.respond(s.calls, events)
+}
+
+var errSinkDown = errors.New("sink down")
+
+func failFirst(n int) func(int, []domain.DomainEvent) (int, error) {
+	return func(call int, events []domain.DomainEvent) (int, error) {
+		if call == 1 {
+			return n, errSinkDown
+		}
+		return len(events), nil
+	}
+}
+
+func redisDown(int, []domain.DomainEvent) (int, error) {
+	return 0, domain.ErrCacheUnavailable
+}
+
+func TestOutboxRelayPublish(t *testing.T) {
+	tests := []struct {
+		name      string
+		events    []int64
+		late      map[int][]int64 // event commit muộn, được thêm vào outbox trước lượt thứ i (tính từ 0)
+		batchSize int
+		rounds    int
+		sinks     []*fakeSink
+		want      map[string]string // ID event mỗi sink đã nhận, theo thứ tự
+		wantLeft  int
+	}{
+		{
+			name:   "all sinks succeed",
+			events: []int64{1, 2, 3}, batchSize: 10, rounds: 1,
+			sinks: []*fakeSink{{name: "webhooks"}, {name: "sse"}},
+			want:  map[string]string{"webhooks": "[1 2 3]", "sse": "[1 2 3]"},
+		},
+		{
+			name:   "failed sink is retried alone",
+			events: []int64{1, 2, 3}, batchSize: 10, rounds: 2,
+			sinks: []*fakeSink{{name: "webhooks"}, {name: "redis-stream", respond: failFirst(1)}},
+			want:  map[string]string{"webhooks": "[1 2 3]", "redis-stream": "[1 2 3 2 3]"},
+		},
+		{
+			name:   "failed sink holds the outbox back",
+			events: []int64{1, 2, 3}, batchSize: 10, rounds: 1,
+			sinks:    []*fakeSink{{name: "webhooks", respond: failFirst(0)}, {name: "sse"}},
+			want:     map[string]string{"webhooks": "[1 2 3]", "sse": "[1 2 3]"},
+			wantLeft: 3,
+		},
+		{
+			name:   "later sinks still receive when an earlier one fails",
+			events: []int64{1, 2, 3}, batchSize: 10, rounds: 2,
+			sinks: []*fakeSink{{name: "webhooks", respond: failFirst(2)}, {name: "sse"}},
+			want:  map[string]string{"webhooks": "[1 2 3 3]", "sse": "[1 2 3]"},
+		},
+		{
+			name:   "unavailable redis sink is skipped",
+			events: []int64{1, 2, 3}, batchSize: 10, rounds: 1,
+			sinks: []*fakeSink{{name: "webhooks"}, {name: "redis-stream", respond: redisDown}},
+			want:  map[string]string{"webhooks": "[1 2 3]", "redis-stream": "[1 2 3]"},
+		},
+		{
+			name:   "webhooks keep flowing past a batch while redis is down",
+			events: []int64{1, 2, 3, 4, 5}, batchSize: 2, rounds: 3,
+			sinks: []*fakeSink{{name: "webhooks"}, {name: "sse", respond: redisDown}},
+			want:  map[string]string{"webhooks": "[1 2 3 4 5]", "sse": "[1 2 3 4 5]"},
+		},
+		{
+			name:   "event committed after a higher ID was delivered",
+			events: []int64{4, 6}, late: map[int][]int64{1: {5}}, batchSize: 10, rounds: 2,
+			sinks: []*fakeSink{{name: "webhooks"}, {name: "sse"}},
+			want:  map[string]string{"webhooks": "[4 6 5]", "sse": "[4 6 5]"},
+		},
+		{
+			name:   "late event below a higher ID still held by a failed sink",
+			events: []int64{4, 6}, late: map[int][]int64{1: {5}}, batchSize: 10, rounds: 2,
+			sinks: []*fakeSink{{name: "webhooks"}, {name: "redis-stream", respond: failFirst(1)}},
+			want:  map[string]string{"webhooks": "[4 6 5]", "redis-stream": "[4 6 5 6]"},
+		},
+		{
+			name:   "late event pushes an acked event out of a full batch",
+			events: []int64{3, 4}, late: map[int][]int64{1: {2}}, batchSize: 2, rounds: 3,
+			sinks: []*fakeSink{{name: "webhooks"}, {name: "sse", respond: failFirst(0)}},
+			want:  map[string]string{"webhooks": "[3 4 2]", "sse": "[3 4 2 3 4]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outbox := &fakeOutbox{}
+			appendIDs := func(ids []int64) {
+				for _, id := range ids {
+					_ = outbox.Append(context.Background(), []domain.DomainEvent{{ID: id}})
+				}
+			}
+			appendIDs(tt.events)
+			sinks := make([]domain.EventSink, len(tt.sinks))
+			for i, s := range tt.sinks {
+				sinks[i] = s
+			}
+			relay := NewOutboxRelay(outbox, 0, tt.batchSize, sinks...)
+
+			for round := range tt.rounds {
+				appendIDs(tt.late[round])
+				_, _ = relay.runOnce(context.Background())
+			}
+
+			for _, s := range tt.sinks {
+				if got := fmt.Sprint(s.received); got != tt.want[s.name] {
+					t.Errorf("sink %s received %s, want %s", s.name, got, tt.want[s.name])
+				}
+			}
+			if len(outbox.events) != tt.wantLeft {
+				t.Errorf("outbox has %d events left, want %d", len(outbox.events), tt.wantLeft)
+			}
+		})
+	}
+}
+
+func TestOutboxRelayReportsSinkErrors(t *testing.T) {
+	outbox := &fakeOutbox{events: []domain.DomainEvent{{ID: 1}, {ID: 2}}}
+	relay := NewOutboxRelay(outbox, 0, 10,
+		&fakeSink{name: "webhooks", respond: failFirst(1)},
+		&fakeSink{name: "redis-stream", respond: redisDown},
+	)
+
+	n, err := relay.runOnce(context.Background())
+	if n != 1 || !errors.Is(err, errSinkDown) {
+		t.Errorf("runOnce() = %d, %v, want 1, %v", n, err, errSinkDown)
+	}
+	if errors.Is(err, domain.ErrCacheUnavailable) {
+		t.Errorf("runOnce() error %v includes the skipped sink", err)
+	}
+}
@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"Test2/internal/domain"
+)
+
+// TrashRetentionJob định kỳ xóa vĩnh viễn Post/Category nằm trong thùng rác quá thời gian lưu giữ
+type TrashRetentionJob struct {
+	postUseCase domain.PostUseCase
+	cateUseCase domain.CategoryUseCase
+	retention   time.Duration
+	interval    time.Duration
+}
+
+func NewTrashRetentionJob(
+	posts domain.PostUseCase,
+	categories domain.CategoryUseCase,
+	retention time.Duration,
+	interval time.Duration,
+) *TrashRetentionJob {
+	return &TrashRetentionJob{
+		postUseCase: posts,
+		cateUseCase: categories,
+		retention:   retention,
+		interval:    interval,
+	}
+}
+
+// Run chạy tới khi ctx bị hủy, mỗi interval thực hiện một lượt dọn dẹp
+func (j *TrashRetentionJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		j.runOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (j *TrashRetentionJob) runOnce(ctx context.Context) {
+	posts, err := j.postUseCase.PurgeExpired(ctx, j.retention)
+	if err != nil {
+		slog.ErrorContext(ctx, "trash retention: purge posts failed", "purged", posts, "error", err)
+	}
+
+	categories, err := j.cateUseCase.PurgeExpired(ctx, j.retention)
+	if err != nil {
+		slog.ErrorContext(ctx, "trash retention: purge categories failed", "purged", categories, "error", err)
+	}
+
+	if posts > 0 || categories > 0 {
+		slog.InfoContext(ctx, "trash retention: purged expired items", "posts", posts, "categories", categories, "retention", j.retention.String())
+	}
+}
@@ -0,0 +1,52 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"Test2/internal/domain"
+)
+
+// WebhookDispatcher định kỳ gửi các webhook delivery đã tới hạn (lần đầu hoặc thử lại theo backoff)
+type WebhookDispatcher struct {
+	webhooks  domain.WebhookUseCase
+	interval  time.Duration
+	batchSize int
+}
+
+func NewWebhookDispatcher(webhooks domain.WebhookUseCase, interval time.Duration, batchSize int) *WebhookDispatcher {
+	if batchSize <= 0 {
+		batchSize = 50
+	}
+	return &WebhookDispatcher{
+		webhooks:  webhooks,
+		interval:  interval,
+		batchSize: batchSize,
+	}
+}
+
+// Run chạy tới khi ctx bị hủy. Lô đầy được gửi tiếp ngay, lô thiếu hoặc lỗi thì chờ interval.
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			n, err := d.webhooks.DeliverDue(ctx, d.batchSize)
+			if err != nil {
+				slog.ErrorContext(ctx, "webhook dispatcher: delivery round failed", "error", err)
+				break
+			}
+			if n < d.batchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -1,10 +1,3 @@
--- 0. CƯỠNG CHẾ BẢNG MÃ UNICODE CHO TOÀN BỘ LUỒNG KẾT NỐI
-SET NAMES utf8mb4 COLLATE utf8mb4_unicode_ci;
-SET CHARACTER SET utf8mb4;
-
-USE ahihi_db;
-
--- 1. ĐỊNH NGHĨA LƯỢC ĐỒ (SCHEMA)
 CREATE TABLE IF NOT EXISTS posts (
     id INT AUTO_INCREMENT PRIMARY KEY,
     title VARCHAR(255) NOT NULL,
@@ -19,4 +12,4 @@ CREATE TABLE IF NOT EXISTS posts (
     INDEX idx_status_created_at (status, created_at DESC),
     INDEX idx_created_at (created_at DESC),
     FULLTEXT INDEX idx_fts_search (title, description, content)
-) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
\ No newline at end of file
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -1,5 +1,3 @@
-USE ahihi_db
-
 CREATE TABLE IF NOT EXISTS categories (
     id INT AUTO_INCREMENT PRIMARY KEY,
     title VARCHAR(255) NOT NULL,
@@ -8,9 +6,6 @@ CREATE TABLE IF NOT EXISTS categories (
     status VARCHAR(50) DEFAULT 'Active',
     created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
     updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_status_created_at (status, created_at DESC), -- Chỉ mục phân trang
     UNIQUE INDEX idx_title (title) -- Index để đảm bảo tên danh mục không trùng lặp
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
-
--- 1. Bổ sung Chỉ mục Phân trang cho bảng categories
-ALTER TABLE categories 
-ADD INDEX idx_status_created_at (status, created_at DESC);
\ No newline at end of file
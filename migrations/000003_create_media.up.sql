@@ -0,0 +1,14 @@
+-- Metadata của thư viện media, tệp vật lý nằm trên MediaStorage
+CREATE TABLE IF NOT EXISTS media (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    file_name VARCHAR(255) NOT NULL,
+    mime_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL,
+    width INT NOT NULL DEFAULT 0,
+    height INT NOT NULL DEFAULT 0,
+    storage_key VARCHAR(512) NOT NULL,
+    variants JSON, -- [{name, storage_key, width, height, size}]
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+
+    INDEX idx_created_at (created_at DESC)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,6 @@
+ALTER TABLE categories
+    DROP COLUMN media_id;
+
+ALTER TABLE posts
+    DROP INDEX idx_media_id,
+    DROP COLUMN media_id;
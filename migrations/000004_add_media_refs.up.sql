@@ -0,0 +1,7 @@
+-- Tham chiếu bảng media (ảnh đại diện) của bài viết và danh mục
+ALTER TABLE posts
+    ADD COLUMN media_id INT NULL AFTER thumbnail,
+    ADD INDEX idx_media_id (media_id);
+
+ALTER TABLE categories
+    ADD COLUMN media_id INT NULL AFTER thumbnail;
@@ -0,0 +1,3 @@
+-- Định dạng nội dung bài viết: markdown | html | plaintext
+ALTER TABLE posts
+    ADD COLUMN content_format VARCHAR(20) NOT NULL DEFAULT 'markdown' AFTER content;
@@ -0,0 +1,3 @@
+ALTER TABLE posts
+    DROP INDEX idx_status_category_publish,
+    DROP COLUMN category_id;
@@ -0,0 +1,4 @@
+-- Danh mục của bài viết, kèm chỉ mục cho feed theo danh mục
+ALTER TABLE posts
+    ADD COLUMN category_id INT NULL AFTER media_id,
+    ADD INDEX idx_status_category_publish (status, category_id, publish_date DESC);
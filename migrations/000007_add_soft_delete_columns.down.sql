@@ -0,0 +1,8 @@
+ALTER TABLE categories
+    DROP COLUMN previous_status,
+    DROP COLUMN deleted_at;
+
+ALTER TABLE posts
+    DROP INDEX idx_status_deleted_at,
+    DROP COLUMN previous_status,
+    DROP COLUMN deleted_at;
@@ -0,0 +1,10 @@
+-- deleted_at: thời điểm xóa mềm, dùng cho retention job
+-- previous_status: trạng thái trước khi xóa, dùng khi Restore
+ALTER TABLE posts
+    ADD COLUMN deleted_at DATETIME NULL AFTER created_at,
+    ADD COLUMN previous_status VARCHAR(50) NULL AFTER deleted_at,
+    ADD INDEX idx_status_deleted_at (status, deleted_at);
+
+ALTER TABLE categories
+    ADD COLUMN deleted_at DATETIME NULL AFTER updated_at,
+    ADD COLUMN previous_status VARCHAR(50) NULL AFTER deleted_at;
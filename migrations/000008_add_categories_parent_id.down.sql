@@ -0,0 +1,3 @@
+ALTER TABLE categories
+    DROP INDEX idx_parent_id,
+    DROP COLUMN parent_id;
@@ -0,0 +1,4 @@
+-- Danh mục cha, NULL là danh mục gốc
+ALTER TABLE categories
+    ADD COLUMN parent_id INT NULL AFTER media_id,
+    ADD INDEX idx_parent_id (parent_id);
@@ -0,0 +1,4 @@
+ALTER TABLE categories
+    ADD INDEX idx_parent_id (parent_id),
+    DROP INDEX idx_parent_position,
+    DROP COLUMN position;
@@ -0,0 +1,5 @@
+-- Thứ tự thủ công trong cùng danh mục cha; idx_parent_position thay thế idx_parent_id
+ALTER TABLE categories
+    ADD COLUMN position INT NOT NULL DEFAULT 0 AFTER parent_id,
+    ADD INDEX idx_parent_position (parent_id, position),
+    DROP INDEX idx_parent_id;
@@ -0,0 +1,3 @@
+ALTER TABLE posts
+    DROP INDEX uq_slug,
+    DROP COLUMN slug;
@@ -0,0 +1,4 @@
+-- Định danh thân thiện URL, dùng làm khóa khi import
+ALTER TABLE posts
+    ADD COLUMN slug VARCHAR(255) NULL AFTER title,
+    ADD UNIQUE INDEX uq_slug (slug);
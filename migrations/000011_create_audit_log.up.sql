@@ -0,0 +1,24 @@
+-- Nhật ký thay đổi nội dung (Post/Category), chỉ thêm mới, không sửa hay xóa
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    actor VARCHAR(255) NOT NULL,
+    request_id VARCHAR(64) NOT NULL DEFAULT '',
+    ip VARCHAR(45) NOT NULL DEFAULT '', -- Đủ cho IPv6
+    entity_type VARCHAR(32) NOT NULL, -- post | category
+    entity_id INT NOT NULL,
+    action VARCHAR(32) NOT NULL, -- create | update | delete | restore | purge
+    before_data JSON NULL, -- NULL khi tạo mới
+    after_data JSON NULL, -- NULL khi xóa
+    created_at DATETIME(6) NOT NULL,
+
+    INDEX idx_entity_created_at (entity_type, entity_id, created_at DESC),
+    INDEX idx_actor_created_at (actor, created_at DESC),
+    INDEX idx_created_at (created_at DESC)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Chặn sửa/xóa ở tầng database, kể cả khi ứng dụng hoặc người vận hành chạy câu lệnh trực tiếp
+CREATE TRIGGER IF NOT EXISTS audit_log_no_update BEFORE UPDATE ON audit_log FOR EACH ROW
+    SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_log is append-only';
+
+CREATE TRIGGER IF NOT EXISTS audit_log_no_delete BEFORE DELETE ON audit_log FOR EACH ROW
+    SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_log is append-only';
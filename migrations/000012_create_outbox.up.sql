@@ -0,0 +1,12 @@
+-- Transactional outbox: domain event được ghi cùng transaction với thay đổi dữ liệu,
+-- relay đọc theo thứ tự id, giao tới các sink rồi xóa khỏi bảng
+CREATE TABLE IF NOT EXISTS outbox (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    event_type VARCHAR(64) NOT NULL,
+    aggregate_type VARCHAR(32) NOT NULL, -- post | category
+    aggregate_id INT NOT NULL,
+    payload JSON NOT NULL,
+    occurred_at DATETIME(6) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0, -- Số lần giao thất bại
+    last_error TEXT NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,37 @@
+-- Đăng ký webhook của đối tác
+CREATE TABLE IF NOT EXISTS webhooks (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    url VARCHAR(2048) NOT NULL,
+    events JSON NOT NULL, -- Danh sách loại event, [] = mọi event
+    secret VARCHAR(255) NOT NULL, -- Khóa HMAC-SHA256 ký payload
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    failure_count INT NOT NULL DEFAULT 0, -- Số lần gửi thất bại liên tiếp
+    disabled_at DATETIME NULL, -- Thời điểm bị tắt tự động
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Delivery log: mỗi dòng là một event cần giao tới một webhook, kèm kết quả lần gửi gần nhất
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    webhook_id INT NOT NULL,
+    event_id BIGINT NOT NULL DEFAULT 0, -- id trong outbox, 0 với Ping
+    event_type VARCHAR(64) NOT NULL,
+    payload JSON NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending | succeeded | failed
+    attempts INT NOT NULL DEFAULT 0,
+    response_code INT NULL,
+    response_body TEXT NULL,
+    error TEXT NULL,
+    duration_ms INT NOT NULL DEFAULT 0,
+    replay_of BIGINT NULL, -- delivery gốc khi được replay
+    dedupe_key VARCHAR(64) NULL, -- "<webhook_id>:<event_id>", NULL với Ping/replay
+    next_attempt_at DATETIME(3) NULL,
+    last_attempt_at DATETIME(3) NULL,
+    created_at DATETIME(3) NOT NULL,
+
+    UNIQUE INDEX uq_dedupe_key (dedupe_key),
+    INDEX idx_status_next_attempt (status, next_attempt_at),
+    INDEX idx_webhook_created_at (webhook_id, created_at DESC),
+    CONSTRAINT fk_delivery_webhook FOREIGN KEY (webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
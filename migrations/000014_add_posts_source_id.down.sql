@@ -0,0 +1,3 @@
+ALTER TABLE posts
+    DROP INDEX uq_source_id,
+    DROP COLUMN source_id;
@@ -0,0 +1,4 @@
+-- Định danh bản ghi ở hệ thống nguồn (ví dụ bài viết WordPress) để import lại khớp đúng bài đã tạo
+ALTER TABLE posts
+    ADD COLUMN source_id VARCHAR(255) NULL AFTER previous_status,
+    ADD UNIQUE INDEX uq_source_id (source_id);
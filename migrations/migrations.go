@@ -0,0 +1,12 @@
+// Package migrations chứa schema của database dưới dạng migration có phiên bản, được nhúng vào binary.
+//
+// Mỗi migration gồm hai tệp <version>_<name>.up.sql và <version>_<name>.down.sql, version tăng dần.
+// 000001 và 000002 giữ nguyên schema của các tệp .sql cũ (docker-entrypoint-initdb) và dùng CREATE ... IF NOT EXISTS,
+// nên database đã khởi tạo theo cách cũ có thể chạy `migrate up` mà không lỗi. Vì vậy mọi cột và chỉ mục thêm sau
+// phải đi qua một migration ALTER TABLE riêng, không được sửa vào hai migration này.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS